@@ -0,0 +1,25 @@
+package main
+
+import (
+	"flag"
+	"log"
+
+	"github.com/verazalayli/go_studying/grpc/pkg/tlsutil"
+)
+
+// gencerts выпускает одноразовый CA и сертификаты сервера/клиента для локального TLS/mTLS:
+//
+//	go run ./grpc/cmd/gencerts -dir certs
+func main() {
+	dir := flag.String("dir", "certs", "куда записать ca.pem, server*.pem, client*.pem")
+	flag.Parse()
+
+	ca, err := tlsutil.NewTestCA()
+	if err != nil {
+		log.Fatalf("create CA: %v", err)
+	}
+	if err := ca.WriteFiles(*dir); err != nil {
+		log.Fatalf("write certificates: %v", err)
+	}
+	log.Printf("certificates written to %s", *dir)
+}
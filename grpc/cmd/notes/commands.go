@@ -0,0 +1,810 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+
+	"github.com/verazalayli/go_studying/grpc/pkg/archive"
+	"github.com/verazalayli/go_studying/grpc/proto/pb"
+)
+
+func createCmd(fs *flag.FlagSet) runFunc {
+	title := fs.String("title", "", "note title")
+	content := fs.String("content", "", "note text")
+	contentFile := fs.String("content-file", "", "read note text from file ('-' — stdin)")
+	key := fs.String("idempotency-key", "", "repeat with the same key returns the same note instead of a duplicate")
+	var tags stringsFlag
+	fs.Var(&tags, "tag", "tag the note (repeatable)")
+	return func(ctx context.Context, c *cli, args []string) error {
+		if len(args) != 0 {
+			return usagef("create takes no arguments, got %q", args)
+		}
+		if *content != "" && *contentFile != "" {
+			return usagef("--content and --content-file are mutually exclusive")
+		}
+		text := *content
+		if *contentFile != "" {
+			b, err := readFile(*contentFile)
+			if err != nil {
+				return err
+			}
+			text = string(b)
+		}
+		resp, err := c.client.CreateNote(ctx, &pb.CreateNoteRequest{
+			Title:          *title,
+			Content:        text,
+			IdempotencyKey: *key,
+			Tags:           tags,
+		})
+		if err != nil {
+			return err
+		}
+		return c.out.note(resp.GetNote())
+	}
+}
+
+func getCmd(fs *flag.FlagSet) runFunc {
+	return func(ctx context.Context, c *cli, args []string) error {
+		if len(args) != 1 {
+			return usagef("get takes exactly one note ID")
+		}
+		resp, err := c.client.GetNote(ctx, &pb.GetNoteRequest{Id: args[0]})
+		if err != nil {
+			return err
+		}
+		return c.out.note(resp.GetNote())
+	}
+}
+
+func listCmd(fs *flag.FlagSet) runFunc {
+	pageSize := fs.Int("page-size", 0, "notes per page (0 — server default)")
+	pageToken := fs.String("page-token", "", "continue from next_page_token of a previous call")
+	orderBy := fs.String("order-by", "", `"created_at" (default), "created_at desc", "title" or "title desc"`)
+	titlePrefix := fs.String("title-prefix", "", "only notes whose title starts with this")
+	var tags stringsFlag
+	fs.Var(&tags, "tag", "only notes with this tag (repeatable)")
+	tagMatch := fs.String("tag-match", "any", `with several --tag: "any" of them or "all"`)
+	all := fs.Bool("all", false, "follow next_page_token until the last page")
+	return func(ctx context.Context, c *cli, args []string) error {
+		if len(args) != 0 {
+			return usagef("list takes no arguments, got %q", args)
+		}
+		match, ok := pb.TagMatch_value["TAG_MATCH_"+strings.ToUpper(*tagMatch)]
+		if !ok {
+			return usagef("--tag-match must be any or all, got %q", *tagMatch)
+		}
+		req := &pb.ListNotesRequest{
+			PageSize:    int32(*pageSize),
+			PageToken:   *pageToken,
+			OrderBy:     *orderBy,
+			TitlePrefix: *titlePrefix,
+			Tags:        tags,
+			TagMatch:    pb.TagMatch(match),
+		}
+		result := &pb.ListNotesResponse{}
+		for {
+			resp, err := c.client.ListNotes(ctx, req)
+			if err != nil {
+				return err
+			}
+			result.Notes = append(result.Notes, resp.GetNotes()...)
+			result.NextPageToken = resp.GetNextPageToken()
+			if !*all || result.NextPageToken == "" {
+				break
+			}
+			req.PageToken = result.NextPageToken
+		}
+		return c.out.notes(result)
+	}
+}
+
+func searchCmd(fs *flag.FlagSet) runFunc {
+	limit := fs.Int("limit", 0, "max hits (0 — server default)")
+	return func(ctx context.Context, c *cli, args []string) error {
+		query := strings.Join(args, " ")
+		if strings.TrimSpace(query) == "" {
+			return usagef("search needs a query")
+		}
+		resp, err := c.client.SearchNotes(ctx, &pb.SearchNotesRequest{Query: query, Limit: int32(*limit)})
+		if err != nil {
+			return err
+		}
+		return c.out.hits(resp)
+	}
+}
+
+// updateCmd меняет только поля, флаги которых переданы явно (update_mask),
+// так что пустой --content очищает текст, а не оставляет его как есть.
+func updateCmd(fs *flag.FlagSet) runFunc {
+	title := fs.String("title", "", "new title")
+	content := fs.String("content", "", "new text")
+	contentFile := fs.String("content-file", "", "read new text from file ('-' — stdin)")
+	version := fs.Int64("expected-version", 0, "fail with Aborted if the note is no longer at this version")
+	return func(ctx context.Context, c *cli, args []string) error {
+		if len(args) != 1 {
+			return usagef("update takes exactly one note ID")
+		}
+		set := map[string]bool{}
+		fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+		if set["content"] && set["content-file"] {
+			return usagef("--content and --content-file are mutually exclusive")
+		}
+		req := &pb.UpdateNoteRequest{Id: args[0], ExpectedVersion: *version, UpdateMask: &fieldmaskpb.FieldMask{}}
+		if set["title"] {
+			req.Title = *title
+			req.UpdateMask.Paths = append(req.UpdateMask.Paths, "title")
+		}
+		switch {
+		case set["content"]:
+			req.Content = *content
+		case set["content-file"]:
+			b, err := readFile(*contentFile)
+			if err != nil {
+				return err
+			}
+			req.Content = string(b)
+		}
+		if set["content"] || set["content-file"] {
+			req.UpdateMask.Paths = append(req.UpdateMask.Paths, "content")
+		}
+		if len(req.UpdateMask.Paths) == 0 {
+			return usagef("update needs --title, --content or --content-file")
+		}
+		resp, err := c.client.UpdateNote(ctx, req)
+		if err != nil {
+			return err
+		}
+		return c.out.note(resp.GetNote())
+	}
+}
+
+// deleteCmd удаляет по очереди и останавливается на первой ошибке.
+// --expected-version имеет смысл только для одного ID.
+func deleteCmd(fs *flag.FlagSet) runFunc {
+	version := fs.Int64("expected-version", 0, "fail with Aborted if the note is no longer at this version")
+	return func(ctx context.Context, c *cli, args []string) error {
+		if len(args) == 0 {
+			return usagef("delete needs at least one note ID")
+		}
+		if *version != 0 && len(args) > 1 {
+			return usagef("--expected-version needs exactly one note ID")
+		}
+		for _, id := range args {
+			if _, err := c.client.DeleteNote(ctx, &pb.DeleteNoteRequest{Id: id, ExpectedVersion: *version}); err != nil {
+				return err
+			}
+			if err := c.out.done("moved to trash", id); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func trashCmd(fs *flag.FlagSet) runFunc {
+	pageSize := fs.Int("page-size", 0, "notes per page (0 — server default)")
+	pageToken := fs.String("page-token", "", "continue from next_page_token of a previous call")
+	orderBy := fs.String("order-by", "", `"created_at" (default), "created_at desc", "title" or "title desc"`)
+	titlePrefix := fs.String("title-prefix", "", "only notes whose title starts with this")
+	all := fs.Bool("all", false, "follow next_page_token until the last page")
+	return func(ctx context.Context, c *cli, args []string) error {
+		if len(args) != 0 {
+			return usagef("trash takes no arguments, got %q", args)
+		}
+		req := &pb.ListTrashRequest{PageSize: int32(*pageSize), PageToken: *pageToken, OrderBy: *orderBy, TitlePrefix: *titlePrefix}
+		result := &pb.ListTrashResponse{}
+		for {
+			resp, err := c.client.ListTrash(ctx, req)
+			if err != nil {
+				return err
+			}
+			result.Notes = append(result.Notes, resp.GetNotes()...)
+			result.NextPageToken = resp.GetNextPageToken()
+			if !*all || result.NextPageToken == "" {
+				break
+			}
+			req.PageToken = result.NextPageToken
+		}
+		return c.out.trash(result)
+	}
+}
+
+func restoreCmd(fs *flag.FlagSet) runFunc {
+	version := fs.Int64("expected-version", 0, "fail with Aborted if the note is no longer at this version")
+	return func(ctx context.Context, c *cli, args []string) error {
+		if len(args) != 1 {
+			return usagef("restore takes exactly one note ID")
+		}
+		resp, err := c.client.RestoreNote(ctx, &pb.RestoreNoteRequest{Id: args[0], ExpectedVersion: *version})
+		if err != nil {
+			return err
+		}
+		return c.out.note(resp.GetNote())
+	}
+}
+
+// purgeCmd, как и deleteCmd, идёт по ID по очереди до первой ошибки.
+func purgeCmd(fs *flag.FlagSet) runFunc {
+	version := fs.Int64("expected-version", 0, "fail with Aborted if the note is no longer at this version")
+	return func(ctx context.Context, c *cli, args []string) error {
+		if len(args) == 0 {
+			return usagef("purge needs at least one note ID")
+		}
+		if *version != 0 && len(args) > 1 {
+			return usagef("--expected-version needs exactly one note ID")
+		}
+		for _, id := range args {
+			if _, err := c.client.PurgeNote(ctx, &pb.PurgeNoteRequest{Id: id, ExpectedVersion: *version}); err != nil {
+				return err
+			}
+			if err := c.out.done("purged", id); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func tagCmd(fs *flag.FlagSet) runFunc {
+	version := fs.Int64("expected-version", 0, "fail with Aborted if the note is no longer at this version")
+	return func(ctx context.Context, c *cli, args []string) error {
+		if len(args) < 2 {
+			return usagef("tag needs a note ID and at least one tag")
+		}
+		resp, err := c.client.AddTags(ctx, &pb.AddTagsRequest{Id: args[0], Tags: args[1:], ExpectedVersion: *version})
+		if err != nil {
+			return err
+		}
+		return c.out.note(resp.GetNote())
+	}
+}
+
+func untagCmd(fs *flag.FlagSet) runFunc {
+	version := fs.Int64("expected-version", 0, "fail with Aborted if the note is no longer at this version")
+	return func(ctx context.Context, c *cli, args []string) error {
+		if len(args) < 2 {
+			return usagef("untag needs a note ID and at least one tag")
+		}
+		resp, err := c.client.RemoveTags(ctx, &pb.RemoveTagsRequest{Id: args[0], Tags: args[1:], ExpectedVersion: *version})
+		if err != nil {
+			return err
+		}
+		return c.out.note(resp.GetNote())
+	}
+}
+
+func tagsCmd(fs *flag.FlagSet) runFunc {
+	return func(ctx context.Context, c *cli, args []string) error {
+		if len(args) != 0 {
+			return usagef("tags takes no arguments, got %q", args)
+		}
+		resp, err := c.client.ListTags(ctx, &pb.ListTagsRequest{})
+		if err != nil {
+			return err
+		}
+		return c.out.tags(resp)
+	}
+}
+
+func revisionsCmd(fs *flag.FlagSet) runFunc {
+	pageSize := fs.Int("page-size", 0, "revisions per page (0 — server default)")
+	pageToken := fs.String("page-token", "", "continue from next_page_token of a previous call")
+	all := fs.Bool("all", false, "follow next_page_token until the oldest revision")
+	return func(ctx context.Context, c *cli, args []string) error {
+		if len(args) != 1 {
+			return usagef("revisions takes exactly one note ID")
+		}
+		req := &pb.ListNoteRevisionsRequest{Id: args[0], PageSize: int32(*pageSize), PageToken: *pageToken}
+		result := &pb.ListNoteRevisionsResponse{}
+		for {
+			resp, err := c.client.ListNoteRevisions(ctx, req)
+			if err != nil {
+				return err
+			}
+			result.Revisions = append(result.Revisions, resp.GetRevisions()...)
+			result.NextPageToken = resp.GetNextPageToken()
+			if !*all || result.NextPageToken == "" {
+				break
+			}
+			req.PageToken = result.NextPageToken
+		}
+		return c.out.revisions(result)
+	}
+}
+
+func revisionCmd(fs *flag.FlagSet) runFunc {
+	return func(ctx context.Context, c *cli, args []string) error {
+		if len(args) != 2 {
+			return usagef("revision takes a note ID and a version")
+		}
+		v, err := parseVersion(args[1])
+		if err != nil {
+			return err
+		}
+		resp, err := c.client.GetNoteRevision(ctx, &pb.GetNoteRevisionRequest{Id: args[0], Version: v})
+		if err != nil {
+			return err
+		}
+		return c.out.note(resp.GetRevision())
+	}
+}
+
+func diffCmd(fs *flag.FlagSet) runFunc {
+	from := fs.Int64("from", 0, "old version (0 — the one before --to)")
+	to := fs.Int64("to", 0, "new version (0 — current)")
+	return func(ctx context.Context, c *cli, args []string) error {
+		if len(args) != 1 {
+			return usagef("diff takes exactly one note ID")
+		}
+		resp, err := c.client.DiffNoteRevisions(ctx, &pb.DiffNoteRevisionsRequest{Id: args[0], FromVersion: *from, ToVersion: *to})
+		if err != nil {
+			return err
+		}
+		return c.out.diff(resp)
+	}
+}
+
+func renderCmd(fs *flag.FlagSet) runFunc {
+	version := fs.Int64("version", 0, "version to render (0 — current)")
+	toc := fs.Bool("toc", false, "put the table of contents before the text")
+	standalone := fs.Bool("standalone", false, "print a complete HTML document, not a fragment")
+	return func(ctx context.Context, c *cli, args []string) error {
+		if len(args) != 1 {
+			return usagef("render takes exactly one note ID")
+		}
+		resp, err := c.client.RenderNote(ctx, &pb.RenderNoteRequest{Id: args[0], Version: *version})
+		if err != nil {
+			return err
+		}
+		return c.out.rendered(resp, *toc, *standalone)
+	}
+}
+
+func revertCmd(fs *flag.FlagSet) runFunc {
+	version := fs.Int64("expected-version", 0, "fail with Aborted if the note is no longer at this version")
+	return func(ctx context.Context, c *cli, args []string) error {
+		if len(args) != 2 {
+			return usagef("revert takes a note ID and the version to revert to")
+		}
+		v, err := parseVersion(args[1])
+		if err != nil {
+			return err
+		}
+		resp, err := c.client.RevertNote(ctx, &pb.RevertNoteRequest{Id: args[0], Version: v, ExpectedVersion: *version})
+		if err != nil {
+			return err
+		}
+		return c.out.note(resp.GetNote())
+	}
+}
+
+// parseVersion разбирает номер версии из позиционного аргумента.
+func parseVersion(s string) (int64, error) {
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || v < 1 {
+		return 0, usagef("version must be a positive integer, got %q", s)
+	}
+	return v, nil
+}
+
+// uploadChunkSize — размер куска данных в потоке UploadAttachment.
+const uploadChunkSize = 64 << 10
+
+// attachCmd сначала считает SHA-256 файла (сервер сверит его после загрузки),
+// затем отправляет файл кусками по uploadChunkSize. У потока нет дедлайна.
+func attachCmd(fs *flag.FlagSet) runFunc {
+	name := fs.String("name", "", "attachment name (default — base name of FILE; required for '-')")
+	contentType := fs.String("content-type", "", "MIME type (default — by file extension)")
+	version := fs.Int64("expected-version", 0, "fail with Aborted if the note is no longer at this version")
+	return func(ctx context.Context, c *cli, args []string) error {
+		if len(args) != 2 {
+			return usagef("attach takes a note ID and a file ('-' — stdin)")
+		}
+		src, err := openAttachment(args[1])
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+		h := sha256.New()
+		if _, err := io.Copy(h, src); err != nil {
+			return err
+		}
+		if _, err := src.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+
+		n := *name
+		if n == "" {
+			if args[1] == "-" {
+				return usagef("--name is required when reading from stdin")
+			}
+			n = filepath.Base(args[1])
+		}
+		ct := *contentType
+		if ct == "" {
+			ct = mime.TypeByExtension(filepath.Ext(n))
+		}
+
+		stream, err := c.client.UploadAttachment(ctx)
+		if err != nil {
+			return err
+		}
+		err = stream.Send(&pb.UploadAttachmentRequest{Data: &pb.UploadAttachmentRequest_Metadata{Metadata: &pb.AttachmentUpload{
+			NoteId:          args[0],
+			Name:            n,
+			ContentType:     ct,
+			Sha256:          hex.EncodeToString(h.Sum(nil)),
+			ExpectedVersion: *version,
+		}}})
+		buf := make([]byte, uploadChunkSize)
+		for err == nil {
+			var k int
+			k, err = src.Read(buf)
+			if k > 0 {
+				if sendErr := stream.Send(&pb.UploadAttachmentRequest{Data: &pb.UploadAttachmentRequest_Chunk{Chunk: buf[:k]}}); sendErr != nil {
+					err = sendErr
+				}
+			}
+		}
+		// io.EOF от Send значит, что сервер уже ответил (скорее всего ошибкой) — её вернёт CloseAndRecv.
+		if err != io.EOF {
+			return err
+		}
+		resp, err := stream.CloseAndRecv()
+		if err != nil {
+			return err
+		}
+		return c.out.upload(resp)
+	}
+}
+
+// openAttachment открывает файл для attach; "-" — stdin, прочитанный в память,
+// потому что его нужно пройти дважды (хеш, потом отправка).
+func openAttachment(name string) (io.ReadSeekCloser, error) {
+	if name == "-" {
+		b, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, err
+		}
+		return nopCloser{bytes.NewReader(b)}, nil
+	}
+	f, err := os.Open(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, usagef("file %s does not exist", name)
+	}
+	return f, err
+}
+
+type nopCloser struct{ io.ReadSeeker }
+
+func (nopCloser) Close() error { return nil }
+
+// downloadCmd сохраняет вложение во временный файл рядом с целевым и переименовывает
+// его, только когда размер и SHA-256 совпали с метаданными: недокачанный файл не появится.
+func downloadCmd(fs *flag.FlagSet) runFunc {
+	file := fs.String("file", "", "where to save (default — attachment name in the current directory, '-' — stdout)")
+	return func(ctx context.Context, c *cli, args []string) error {
+		if len(args) != 2 {
+			return usagef("download takes a note ID and an attachment ID")
+		}
+		stream, err := c.client.DownloadAttachment(ctx, &pb.DownloadAttachmentRequest{NoteId: args[0], AttachmentId: args[1]})
+		if err != nil {
+			return err
+		}
+		first, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		a := first.GetAttachment()
+		if a == nil {
+			return errors.New("server sent data before attachment metadata")
+		}
+
+		path := *file
+		if path == "" {
+			path = filepath.Base(a.GetName())
+		}
+		var (
+			w   io.Writer = os.Stdout
+			tmp *os.File
+		)
+		if path != "-" {
+			if tmp, err = os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".part-*"); err != nil {
+				return err
+			}
+			defer os.Remove(tmp.Name()) // после rename удалять уже нечего
+			defer tmp.Close()
+			w = tmp
+		}
+
+		h := sha256.New()
+		var size int64
+		for {
+			msg, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			k, err := w.Write(msg.GetChunk())
+			if err != nil {
+				return err
+			}
+			h.Write(msg.GetChunk())
+			size += int64(k)
+		}
+		if got := hex.EncodeToString(h.Sum(nil)); size != a.GetSize() || got != a.GetSha256() {
+			return fmt.Errorf("downloaded data does not match attachment: %d bytes, sha256 %s (want %d bytes, %s)",
+				size, got, a.GetSize(), a.GetSha256())
+		}
+		if tmp == nil {
+			return nil
+		}
+		if err := tmp.Close(); err != nil {
+			return err
+		}
+		if err := os.Rename(tmp.Name(), path); err != nil {
+			return err
+		}
+		return c.out.saved(a, path)
+	}
+}
+
+// exportCmd пишет архив (pkg/archive) во временный файл рядом с целевым и переименовывает
+// его, только когда manifest сервера совпал с записанным: оборванный поток не оставит полуфайла.
+func exportCmd(fs *flag.FlagSet) runFunc {
+	format := fs.String("format", "", "jsonl or protobuf (default — by FILE extension: .pb, .binpb — protobuf)")
+	compress := fs.Bool("gzip", false, "compress with gzip (default — if FILE ends with .gz)")
+	skipTrash := fs.Bool("skip-trash", false, "do not export notes from the trash")
+	skipHistory := fs.Bool("skip-history", false, "export only the latest version of each note")
+	return func(ctx context.Context, c *cli, args []string) error {
+		if len(args) != 1 {
+			return usagef("export takes exactly one file ('-' — stdout)")
+		}
+		path := args[0]
+		f, gz, err := archiveFormat(path, *format, *compress)
+		if err != nil {
+			return usagef("%v", err)
+		}
+		stream, err := c.client.ExportNotes(ctx, &pb.ExportNotesRequest{SkipTrash: *skipTrash, SkipHistory: *skipHistory})
+		if err != nil {
+			return err
+		}
+
+		var (
+			w   io.Writer = os.Stdout
+			tmp *os.File
+		)
+		if path != "-" {
+			if tmp, err = os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".part-*"); err != nil {
+				return err
+			}
+			defer os.Remove(tmp.Name()) // после rename удалять уже нечего
+			defer tmp.Close()
+			w = tmp
+		}
+		aw, err := archive.NewWriter(w, f, gz)
+		if err != nil {
+			return err
+		}
+		for {
+			msg, err := stream.Recv()
+			if err == io.EOF {
+				return errors.New("export stream ended without a manifest: the archive is incomplete")
+			}
+			if err != nil {
+				return err
+			}
+			if n := msg.GetNote(); n != nil {
+				if err := aw.Write(n); err != nil {
+					return err
+				}
+				continue
+			}
+			if m := msg.GetManifest(); !archive.SameManifest(m, aw.Manifest()) {
+				return fmt.Errorf("received %d notes do not match the server manifest (%d notes, sha256 %s)",
+					aw.Manifest().GetNoteCount(), m.GetNoteCount(), m.GetSha256())
+			}
+			break
+		}
+		if err := aw.Close(); err != nil {
+			return err
+		}
+		if tmp != nil {
+			if err := tmp.Close(); err != nil {
+				return err
+			}
+			if err := os.Rename(tmp.Name(), path); err != nil {
+				return err
+			}
+		}
+		return c.out.exported(aw.Manifest(), path)
+	}
+}
+
+// archiveFormat выбирает кодировку и сжатие архива: явные флаги или расширение файла
+// (notes.jsonl.gz — JSON Lines в gzip, notes.pb — protobuf без сжатия).
+func archiveFormat(path, format string, compress bool) (archive.Format, bool, error) {
+	name := strings.ToLower(path)
+	if strings.HasSuffix(name, ".gz") {
+		compress = true
+		name = strings.TrimSuffix(name, ".gz")
+	}
+	if format != "" {
+		f, err := archive.ParseFormat(format)
+		return f, compress, err
+	}
+	switch filepath.Ext(name) {
+	case ".pb", ".binpb":
+		return archive.Protobuf, compress, nil
+	default:
+		return archive.JSONL, compress, nil
+	}
+}
+
+// importCmd проходит архив дважды: сначала целиком сверяет его с manifest и только
+// потом отправляет на сервер — испорченный или оборванный файл не будет загружен наполовину.
+func importCmd(fs *flag.FlagSet) runFunc {
+	modeName := fs.String("mode", "merge", "merge — keep existing notes, report conflicts; replace — make the store match the archive")
+	return func(ctx context.Context, c *cli, args []string) error {
+		if len(args) != 1 {
+			return usagef("import takes exactly one file ('-' — stdin)")
+		}
+		var mode pb.ImportMode
+		switch *modeName {
+		case "merge":
+			mode = pb.ImportMode_IMPORT_MODE_MERGE
+		case "replace":
+			mode = pb.ImportMode_IMPORT_MODE_REPLACE
+		default:
+			return usagef("unknown --mode %q (want merge or replace)", *modeName)
+		}
+		src, err := openArchive(args[0])
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+		if err := verifyArchive(src); err != nil {
+			return err
+		}
+		if _, err := src.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		ar, err := archive.NewReader(src)
+		if err != nil {
+			return err
+		}
+		defer ar.Close()
+
+		stream, err := c.client.ImportNotes(ctx)
+		if err != nil {
+			return err
+		}
+		err = stream.Send(&pb.ImportNotesRequest{Data: &pb.ImportNotesRequest_Options{Options: &pb.ImportOptions{Mode: mode}}})
+		for err == nil {
+			var n *pb.ExportedNote
+			if n, err = ar.Next(); err == nil {
+				err = stream.Send(&pb.ImportNotesRequest{Data: &pb.ImportNotesRequest_Note{Note: n}})
+			}
+		}
+		// io.EOF — архив кончился или (от Send) сервер уже ответил; ответ вернёт CloseAndRecv.
+		if err != io.EOF {
+			return err
+		}
+		resp, err := stream.CloseAndRecv()
+		if err != nil {
+			return err
+		}
+		return c.out.imported(resp)
+	}
+}
+
+// verifyArchive читает архив до конца: Reader сам сверяет manifest на последней записи.
+func verifyArchive(r io.Reader) error {
+	ar, err := archive.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer ar.Close()
+	for {
+		if _, err := ar.Next(); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+	}
+}
+
+// openArchive открывает архив для import; "-" — stdin, сохранённый во временный файл,
+// потому что его нужно пройти дважды (проверка, потом отправка), а в память архив
+// может и не поместиться.
+func openArchive(name string) (io.ReadSeekCloser, error) {
+	if name != "-" {
+		f, err := os.Open(name)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, usagef("file %s does not exist", name)
+		}
+		return f, err
+	}
+	f, err := os.CreateTemp("", "notes-import-*")
+	if err != nil {
+		return nil, err
+	}
+	os.Remove(f.Name()) // файл живёт, пока открыт
+	if _, err := io.Copy(f, os.Stdin); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// watchCmd печатает события, пока поток не закроется. Ctrl+C — штатный выход (код 0).
+// У потока нет дедлайна: --timeout к нему не применяется.
+func watchCmd(fs *flag.FlagSet) runFunc {
+	fromSeq := fs.Uint64("from-seq", 0, "replay stored events starting at this seq (0 — only new)")
+	return func(ctx context.Context, c *cli, args []string) error {
+		if len(args) != 0 {
+			return usagef("watch takes no arguments, got %q", args)
+		}
+		stream, err := c.client.WatchNotes(ctx, &pb.WatchNotesRequest{FromSeq: *fromSeq})
+		if err != nil {
+			return err
+		}
+		for {
+			ev, err := stream.Recv()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				if ctx.Err() != nil && status.Code(err) == codes.Canceled {
+					return nil
+				}
+				return err
+			}
+			if err := c.out.event(ev); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// stringsFlag — повторяемый флаг: каждое вхождение добавляет значение.
+type stringsFlag []string
+
+func (s *stringsFlag) String() string { return strings.Join(*s, ",") }
+
+func (s *stringsFlag) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
+
+func readFile(name string) ([]byte, error) {
+	if name == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	b, err := os.ReadFile(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, usagef("content file %s does not exist", name)
+	}
+	return b, err
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"time"
+
+	// Клиент NoteService с повторами, дедлайнами и keepalive; загрузка TLS-сертификатов.
+	"github.com/verazalayli/go_studying/grpc/pkg/client"
+	"github.com/verazalayli/go_studying/grpc/pkg/tlsutil"
+)
+
+// globalFlags — настройки соединения и вывода, общие для всех команд.
+// Значения по умолчанию — из переменных окружения, флаг их перекрывает.
+type globalFlags struct {
+	addr          string        // --addr, NOTES_ADDR
+	timeout       time.Duration // --timeout: дедлайн каждого unary-вызова (client.WithTimeout)
+	tlsCA         string        // --tls-ca, NOTES_TLS_CA: CA сервера; пусто — без TLS
+	tlsCert       string        // --tls-cert, NOTES_TLS_CLIENT_CERT: сертификат клиента (mTLS)
+	tlsKey        string        // --tls-key, NOTES_TLS_CLIENT_KEY
+	tlsServerName string        // --tls-server-name, NOTES_TLS_SERVER_NAME
+	token         string        // --token, NOTES_TOKEN: bearer-токен (go run ./grpc/cmd/token -sub alice)
+	output        string        // -o, --output: table | json | yaml
+}
+
+func defaultGlobalFlags() globalFlags {
+	return globalFlags{
+		addr:          env("NOTES_ADDR", "localhost:50051"),
+		timeout:       5 * time.Second,
+		tlsCA:         os.Getenv("NOTES_TLS_CA"),
+		tlsCert:       os.Getenv("NOTES_TLS_CLIENT_CERT"),
+		tlsKey:        os.Getenv("NOTES_TLS_CLIENT_KEY"),
+		tlsServerName: os.Getenv("NOTES_TLS_SERVER_NAME"),
+		token:         os.Getenv("NOTES_TOKEN"),
+		output:        formatTable,
+	}
+}
+
+// register объявляет флаги в fs. Значения по умолчанию — текущие поля g:
+// флаги команды продолжают то, что уже разобрано до её имени.
+func (g *globalFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&g.addr, "addr", g.addr, "server address")
+	fs.DurationVar(&g.timeout, "timeout", g.timeout, "deadline of each call (watch: none)")
+	fs.StringVar(&g.tlsCA, "tls-ca", g.tlsCA, "CA to verify the server; empty — plaintext")
+	fs.StringVar(&g.tlsCert, "tls-cert", g.tlsCert, "client certificate for mTLS")
+	fs.StringVar(&g.tlsKey, "tls-key", g.tlsKey, "client key for mTLS")
+	fs.StringVar(&g.tlsServerName, "tls-server-name", g.tlsServerName, "server name in its certificate, if it differs from --addr")
+	fs.StringVar(&g.token, "token", g.token, "bearer token")
+	fs.StringVar(&g.output, "output", g.output, "output format: table, json or yaml")
+	fs.StringVar(&g.output, "o", g.output, "shorthand for --output")
+}
+
+// cli — то, с чем работают команды: клиент NoteService и печать результата.
+type cli struct {
+	client *client.Client
+	out    printer
+}
+
+// dial создаёт клиент (pkg/client): повторы чтений при UNAVAILABLE, дедлайн --timeout
+// на каждый unary-вызов, keepalive. Соединение поднимается при первом вызове.
+//
+//	без --tls-ca            — plaintext (как сервер по умолчанию);
+//	--tls-ca                — TLS с проверкой сервера этим CA;
+//	+ --tls-cert/--tls-key  — клиент предъявляет свой сертификат (mTLS);
+//	--token                 — каждый вызов несёт "authorization: Bearer <token>";
+//	                          без TLS это разрешено только для локальной разработки.
+func dial(g globalFlags, out printer) (*cli, error) {
+	opts := []client.Option{client.WithTimeout(g.timeout)}
+	if g.tlsCA != "" {
+		cfg, err := tlsutil.ClientConfig(g.tlsCA, g.tlsCert, g.tlsKey, g.tlsServerName)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, client.WithTLS(cfg))
+	}
+	if g.token != "" {
+		opts = append(opts, client.WithToken(g.token))
+	}
+	c, err := client.New(g.addr, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &cli{client: c, out: out}, nil
+}
+
+func (c *cli) close() error {
+	return c.client.Close()
+}
+
+func env(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
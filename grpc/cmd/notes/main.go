@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+/*
+	notes — консольный клиент NoteService.
+
+	  notes [глобальные флаги] <команда> [флаги команды] [аргументы]
+
+	Команды:
+	  create  --title T [--content C | --content-file F] [--idempotency-key K] [--tag T]...
+	                                                      — создать заметку
+	  get     ID                                          — показать заметку
+	  update  [--title T] [--content C | --content-file F] [--expected-version V] ID
+	                                                      — изменить только переданные поля
+	  list    [--page-size N] [--order-by F] [--title-prefix P] [--tag T]... [--tag-match any|all]
+	          [--page-token T] [--all]
+	  search  [--limit N] QUERY...                        — полнотекстовый поиск
+	  delete  [--expected-version V] ID...                — убрать заметки в корзину
+	  trash   [--page-size N] [--order-by F] [--page-token T] [--all]  — корзина
+	  restore [--expected-version V] ID                   — вернуть заметку из корзины
+	  purge   [--expected-version V] ID...                — удалить из корзины окончательно
+	  tag     [--expected-version V] ID TAG...            — добавить теги
+	  untag   [--expected-version V] ID TAG...            — снять теги
+	  tags                                                — все теги с числом заметок
+	  revisions [--page-size N] [--page-token T] [--all] ID — история версий
+	  revision ID VERSION                                 — показать версию
+	  diff    [--from V] [--to V] ID                      — diff содержимого версий
+	  revert  [--expected-version V] ID VERSION           — откатить к версии
+	  render  [--version V] [--toc] [--standalone] ID     — текст заметки в HTML
+	  attach  [--name N] [--content-type T] [--expected-version V] ID FILE
+	                                                      — прикрепить файл ('-' — stdin)
+	  download [--file F] ID ATTACHMENT_ID                — скачать вложение ('-' — в stdout)
+	  export  [--format jsonl|protobuf] [--gzip] [--skip-trash] [--skip-history] FILE
+	                                                      — выгрузить все заметки в архив ('-' — в stdout)
+	  import  [--mode merge|replace] FILE                 — загрузить заметки из архива ('-' — stdin)
+	  watch   [--from-seq N]                              — поток изменений до Ctrl+C
+
+	Глобальные флаги (можно указывать и до, и после команды):
+	  --addr, --timeout, --tls-ca, --tls-cert, --tls-key, --tls-server-name, --token,
+	  -o/--output table|json|yaml. Значения по умолчанию берутся из NOTES_* (см. globalFlags).
+
+	Код выхода — код gRPC-статуса (NotFound — 5, PermissionDenied — 7, Unauthenticated — 16, ...),
+	поэтому скрипт может отличить «нет такой заметки» от «сервер недоступен» (Unavailable — 14).
+	Ошибки самого CLI: exitUsage (неверные флаги/аргументы) и exitLocal (файл, вывод).
+*/
+
+const (
+	exitUsage = 64 // EX_USAGE из sysexits.h
+	exitLocal = 70 // EX_SOFTWARE: ошибка не от сервера (чтение файла, вывод)
+)
+
+// command — подкоманда CLI. setup объявляет её флаги и возвращает функцию запуска,
+// которая получит уже разобранные флаги и позиционные аргументы.
+type command struct {
+	name  string
+	usage string
+	setup func(fs *flag.FlagSet) runFunc
+}
+
+type runFunc func(ctx context.Context, c *cli, args []string) error
+
+var commands = []command{
+	{name: "create", usage: "create --title T [--content C | --content-file F] [--idempotency-key K] [--tag T]...", setup: createCmd},
+	{name: "get", usage: "get ID", setup: getCmd},
+	{name: "list", usage: "list [--page-size N] [--order-by F] [--title-prefix P] [--tag T]... [--tag-match any|all] [--page-token T] [--all]", setup: listCmd},
+	{name: "search", usage: "search [--limit N] QUERY...", setup: searchCmd},
+	{name: "update", usage: "update [--title T] [--content C | --content-file F] [--expected-version V] ID", setup: updateCmd},
+	{name: "delete", usage: "delete [--expected-version V] ID...", setup: deleteCmd},
+	{name: "trash", usage: "trash [--page-size N] [--order-by F] [--title-prefix P] [--page-token T] [--all]", setup: trashCmd},
+	{name: "restore", usage: "restore [--expected-version V] ID", setup: restoreCmd},
+	{name: "purge", usage: "purge [--expected-version V] ID...", setup: purgeCmd},
+	{name: "tag", usage: "tag [--expected-version V] ID TAG...", setup: tagCmd},
+	{name: "untag", usage: "untag [--expected-version V] ID TAG...", setup: untagCmd},
+	{name: "tags", usage: "tags", setup: tagsCmd},
+	{name: "revisions", usage: "revisions [--page-size N] [--page-token T] [--all] ID", setup: revisionsCmd},
+	{name: "revision", usage: "revision ID VERSION", setup: revisionCmd},
+	{name: "diff", usage: "diff [--from V] [--to V] ID", setup: diffCmd},
+	{name: "revert", usage: "revert [--expected-version V] ID VERSION", setup: revertCmd},
+	{name: "render", usage: "render [--version V] [--toc] [--standalone] ID", setup: renderCmd},
+	{name: "attach", usage: "attach [--name N] [--content-type T] [--expected-version V] ID FILE", setup: attachCmd},
+	{name: "download", usage: "download [--file F] ID ATTACHMENT_ID", setup: downloadCmd},
+	{name: "export", usage: "export [--format jsonl|protobuf] [--gzip] [--skip-trash] [--skip-history] FILE", setup: exportCmd},
+	{name: "import", usage: "import [--mode merge|replace] FILE", setup: importCmd},
+	{name: "watch", usage: "watch [--from-seq N]", setup: watchCmd},
+}
+
+// usageError — неверный вызов CLI; печатается вместе со справкой по команде.
+type usageError struct{ msg string }
+
+func (e usageError) Error() string { return e.msg }
+
+func usagef(format string, args ...any) error {
+	return usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	g := defaultGlobalFlags()
+	root := flag.NewFlagSet("notes", flag.ContinueOnError)
+	root.SetOutput(stderr)
+	g.register(root)
+	root.Usage = func() { printUsage(stderr) }
+	if err := root.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return exitUsage
+	}
+	if root.NArg() == 0 {
+		printUsage(stderr)
+		return exitUsage
+	}
+
+	name := root.Arg(0)
+	var cmd *command
+	for i := range commands {
+		if commands[i].name == name {
+			cmd = &commands[i]
+		}
+	}
+	if cmd == nil {
+		fmt.Fprintf(stderr, "notes: unknown command %q\n", name)
+		printUsage(stderr)
+		return exitUsage
+	}
+
+	// Флаги команды + те же глобальные, чтобы работало и "notes list -o json".
+	fs := flag.NewFlagSet("notes "+cmd.name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	g.register(fs)
+	runCmd := cmd.setup(fs)
+	fs.Usage = func() {
+		// Только флаги самой команды; глобальные — в "notes -h".
+		own := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+		own.SetOutput(stderr)
+		cmd.setup(own)
+		fmt.Fprintf(stderr, "usage: notes %s\n", cmd.usage)
+		own.PrintDefaults()
+		fmt.Fprintln(stderr, "global flags: notes -h")
+	}
+	args, err := parseInterspersed(fs, root.Args()[1:])
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return exitUsage
+	}
+	out, err := newPrinter(g.output, stdout)
+	if err != nil {
+		fmt.Fprintf(stderr, "notes: %v\n", err)
+		return exitUsage
+	}
+
+	// Ctrl+C отменяет контекст: watch завершается штатно, прочие вызовы — с Canceled.
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	c, err := dial(g, out)
+	if err != nil {
+		fmt.Fprintf(stderr, "notes: %v\n", err)
+		return exitLocal
+	}
+	defer c.close()
+
+	err = runCmd(ctx, c, args)
+	return exitCode(stderr, fs, err)
+}
+
+// parseInterspersed разбирает флаги вперемешку с аргументами ("get ID -o json"):
+// пакет flag останавливается на первом аргументе, поэтому разбор повторяется с остатка.
+// После "--" всё считается аргументами.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+// exitCode печатает ошибку в stderr и выбирает код выхода.
+func exitCode(stderr io.Writer, fs *flag.FlagSet, err error) int {
+	if err == nil {
+		return 0
+	}
+	var ue usageError
+	if errors.As(err, &ue) {
+		fmt.Fprintf(stderr, "notes: %v\n", err)
+		fs.Usage()
+		return exitUsage
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		fmt.Fprintf(stderr, "notes: %v\n", err)
+		return exitLocal
+	}
+	printStatus(stderr, st)
+	if st.Code() == codes.OK {
+		return exitLocal
+	}
+	return int(st.Code())
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "usage: notes [flags] <command> [command flags] [args]")
+	fmt.Fprintln(w, "\ncommands:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %s\n", c.usage)
+	}
+	fmt.Fprintln(w, "\nflags:")
+	fs := flag.NewFlagSet("notes", flag.ContinueOnError)
+	fs.SetOutput(w)
+	g := defaultGlobalFlags()
+	g.register(fs)
+	fs.PrintDefaults()
+}
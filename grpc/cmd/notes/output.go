@@ -0,0 +1,333 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"gopkg.in/yaml.v3"
+
+	"github.com/verazalayli/go_studying/grpc/proto/pb"
+)
+
+// Форматы вывода (-o).
+const (
+	formatTable = "table" // для человека: колонки, время в локальной зоне
+	formatJSON  = "json"  // protojson, имена полей как в .proto
+	formatYAML  = "yaml"  // то же дерево, что в JSON, но в YAML
+)
+
+// printer печатает результаты команд в выбранном формате.
+// В json/yaml печатается само protobuf-сообщение ответа, чтобы скрипты видели все поля;
+// события watch — по строке JSON (JSON Lines) или по YAML-документу на событие.
+type printer struct {
+	w      io.Writer
+	format string
+}
+
+func newPrinter(format string, w io.Writer) (printer, error) {
+	switch format {
+	case formatTable, formatJSON, formatYAML:
+		return printer{w: w, format: format}, nil
+	default:
+		return printer{}, fmt.Errorf("unknown output format %q (want table, json or yaml)", format)
+	}
+}
+
+func (p printer) note(n *pb.Note) error {
+	if p.format != formatTable {
+		return p.message(n, false)
+	}
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "ID\t%s\n", n.GetId())
+	fmt.Fprintf(tw, "TITLE\t%s\n", n.GetTitle())
+	if n.GetOwnerId() != "" {
+		fmt.Fprintf(tw, "OWNER\t%s\n", n.GetOwnerId())
+	}
+	fmt.Fprintf(tw, "CREATED\t%s\n", unixTime(n.GetCreatedAt()))
+	fmt.Fprintf(tw, "UPDATED\t%s\n", unixTime(n.GetUpdatedAt()))
+	fmt.Fprintf(tw, "VERSION\t%d\n", n.GetVersion())
+	if len(n.GetTags()) > 0 {
+		fmt.Fprintf(tw, "TAGS\t%s\n", strings.Join(n.GetTags(), ", "))
+	}
+	if n.GetDeletedAt() != 0 {
+		fmt.Fprintf(tw, "DELETED\t%s\n", unixTime(n.GetDeletedAt()))
+	}
+	for _, a := range n.GetAttachments() {
+		fmt.Fprintf(tw, "ATTACHMENT\t%s  %s  %d bytes  %s\n", a.GetId(), a.GetName(), a.GetSize(), a.GetContentType())
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if n.GetContent() != "" {
+		_, err := fmt.Fprintf(p.w, "\n%s\n", strings.TrimRight(n.GetContent(), "\n"))
+		return err
+	}
+	return nil
+}
+
+func (p printer) notes(resp *pb.ListNotesResponse) error {
+	if p.format != formatTable {
+		return p.message(resp, false)
+	}
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tTITLE\tOWNER\tUPDATED\tTAGS")
+	for _, n := range resp.GetNotes() {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", n.GetId(), n.GetTitle(), n.GetOwnerId(), unixTime(n.GetUpdatedAt()), strings.Join(n.GetTags(), ","))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if t := resp.GetNextPageToken(); t != "" {
+		_, err := fmt.Fprintf(p.w, "\nmore: --page-token %s\n", t)
+		return err
+	}
+	return nil
+}
+
+func (p printer) hits(resp *pb.SearchNotesResponse) error {
+	if p.format != formatTable {
+		return p.message(resp, false)
+	}
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SCORE\tID\tTITLE\tSNIPPET")
+	for _, h := range resp.GetHits() {
+		var snippet string
+		if s := h.GetSnippets(); len(s) > 0 {
+			snippet = s[0]
+		}
+		fmt.Fprintf(tw, "%.2f\t%s\t%s\t%s\n", h.GetScore(), h.GetNote().GetId(), h.GetNote().GetTitle(), snippet)
+	}
+	return tw.Flush()
+}
+
+func (p printer) trash(resp *pb.ListTrashResponse) error {
+	if p.format != formatTable {
+		return p.message(resp, false)
+	}
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tTITLE\tDELETED")
+	for _, n := range resp.GetNotes() {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", n.GetId(), n.GetTitle(), unixTime(n.GetDeletedAt()))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if t := resp.GetNextPageToken(); t != "" {
+		_, err := fmt.Fprintf(p.w, "\nmore: --page-token %s\n", t)
+		return err
+	}
+	return nil
+}
+
+func (p printer) tags(resp *pb.ListTagsResponse) error {
+	if p.format != formatTable {
+		return p.message(resp, false)
+	}
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TAG\tNOTES")
+	for _, t := range resp.GetTags() {
+		fmt.Fprintf(tw, "%s\t%d\n", t.GetTag(), t.GetCount())
+	}
+	return tw.Flush()
+}
+
+func (p printer) revisions(resp *pb.ListNoteRevisionsResponse) error {
+	if p.format != formatTable {
+		return p.message(resp, false)
+	}
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "VERSION\tUPDATED\tTITLE\tTAGS")
+	for _, n := range resp.GetRevisions() {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", n.GetVersion(), unixTime(n.GetUpdatedAt()), n.GetTitle(), strings.Join(n.GetTags(), ","))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if t := resp.GetNextPageToken(); t != "" {
+		_, err := fmt.Fprintf(p.w, "\nmore: --page-token %s\n", t)
+		return err
+	}
+	return nil
+}
+
+// diff в табличном режиме печатает сам unified diff, чтобы его можно было отдать patch.
+func (p printer) diff(resp *pb.DiffNoteRevisionsResponse) error {
+	if p.format != formatTable {
+		return p.message(resp, false)
+	}
+	_, err := io.WriteString(p.w, resp.GetDiff())
+	return err
+}
+
+// rendered в табличном режиме печатает сам HTML: с toc — сначала оглавление,
+// со standalone — целым документом, который можно открыть в браузере.
+func (p printer) rendered(resp *pb.RenderNoteResponse, toc, standalone bool) error {
+	if p.format != formatTable {
+		return p.message(resp, false)
+	}
+	var sb strings.Builder
+	if standalone {
+		fmt.Fprintf(&sb, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n</head>\n<body>\n",
+			html.EscapeString(resp.GetTitle()))
+	}
+	if toc && resp.GetTocHtml() != "" {
+		fmt.Fprintf(&sb, "<nav class=\"toc\">\n%s</nav>\n", resp.GetTocHtml())
+	}
+	sb.WriteString(resp.GetHtml())
+	if standalone {
+		sb.WriteString("</body>\n</html>\n")
+	}
+	_, err := io.WriteString(p.w, sb.String())
+	return err
+}
+
+// upload — результат attach: в таблице заметка с новым вложением.
+func (p printer) upload(resp *pb.UploadAttachmentResponse) error {
+	if p.format != formatTable {
+		return p.message(resp, false)
+	}
+	return p.note(resp.GetNote())
+}
+
+// saved сообщает, куда download сохранил вложение; в json/yaml — метаданные вложения.
+func (p printer) saved(a *pb.Attachment, path string) error {
+	if p.format != formatTable {
+		return p.message(a, false)
+	}
+	_, err := fmt.Fprintf(p.w, "saved %s (%d bytes) to %s\n", a.GetName(), a.GetSize(), path)
+	return err
+}
+
+// exported сообщает, сколько заметок выгружено и куда; в json/yaml — manifest архива.
+// При выводе архива в stdout (path "-") в таблице ничего не печатает, чтобы не испортить архив.
+func (p printer) exported(m *pb.ArchiveManifest, path string) error {
+	if path == "-" {
+		return nil
+	}
+	if p.format != formatTable {
+		return p.message(m, false)
+	}
+	_, err := fmt.Fprintf(p.w, "exported %d notes to %s (sha256 %s)\n", m.GetNoteCount(), path, m.GetSha256())
+	return err
+}
+
+// imported — итог import: счётчики и заметки, которые не импортированы.
+func (p printer) imported(resp *pb.ImportNotesResponse) error {
+	if p.format != formatTable {
+		return p.message(resp, false)
+	}
+	fmt.Fprintf(p.w, "received %d, imported %d, unchanged %d, deleted %d\n",
+		resp.GetReceived(), resp.GetImported(), resp.GetUnchanged(), resp.GetDeleted())
+	if len(resp.GetIssues()) == 0 {
+		return nil
+	}
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "\nINDEX\tID\tREASON\tMESSAGE")
+	for _, is := range resp.GetIssues() {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", is.GetIndex(), is.GetId(), is.GetReason(), is.GetMessage())
+	}
+	return tw.Flush()
+}
+
+// done сообщает об успехе команды без ответа (delete, purge).
+// В json/yaml ничего не печатает: успех виден по коду выхода.
+func (p printer) done(action, id string) error {
+	if p.format != formatTable {
+		return nil
+	}
+	_, err := fmt.Fprintf(p.w, "%s %s\n", action, id)
+	return err
+}
+
+func (p printer) event(ev *pb.NoteEvent) error {
+	if p.format != formatTable {
+		return p.message(ev, true)
+	}
+	typ := strings.TrimPrefix(ev.GetType().String(), "NOTE_EVENT_TYPE_")
+	_, err := fmt.Fprintf(p.w, "%-6d %-8s %s  %s  %s\n",
+		ev.GetSeq(), typ, unixTime(ev.GetOccurredAt()), ev.GetNote().GetId(), ev.GetNote().GetTitle())
+	return err
+}
+
+// message печатает protobuf-сообщение в json или yaml.
+// stream — элемент потока: JSON в одну строку, YAML отдельным документом.
+func (p printer) message(m proto.Message, stream bool) error {
+	opts := protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true}
+	if !stream {
+		opts.Multiline = true
+		opts.Indent = "  "
+	}
+	b, err := opts.Marshal(m)
+	if err != nil {
+		return err
+	}
+	if p.format == formatYAML {
+		if b, err = jsonToYAML(b); err != nil {
+			return err
+		}
+		if stream {
+			b = append([]byte("---\n"), b...)
+		}
+		_, err = p.w.Write(b)
+		return err
+	}
+	_, err = fmt.Fprintf(p.w, "%s\n", b)
+	return err
+}
+
+// jsonToYAML переводит JSON в блочный YAML с сохранением порядка полей.
+// JSON — подмножество YAML, поэтому yaml.v3 читает его в дерево узлов напрямую;
+// остаётся сбросить стили (flow-скобки, кавычки) — кодировщик сам расставит кавычки,
+// где без них значение прочиталось бы иначе (например, int64 строкой: "1700000000").
+func jsonToYAML(b []byte) ([]byte, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(b, &root); err != nil {
+		return nil, err
+	}
+	var reset func(n *yaml.Node)
+	reset = func(n *yaml.Node) {
+		n.Style = 0
+		for _, c := range n.Content {
+			reset(c)
+		}
+	}
+	reset(&root)
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&root); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), enc.Close()
+}
+
+// printStatus разбирает gRPC-ошибку: код, текст и детали (поле и причину).
+func printStatus(w io.Writer, st *status.Status) {
+	fmt.Fprintf(w, "notes: %s: %s\n", st.Code(), st.Message())
+	for _, d := range st.Details() {
+		switch d := d.(type) {
+		case *errdetails.BadRequest:
+			for _, v := range d.GetFieldViolations() {
+				fmt.Fprintf(w, "  field %q: %s\n", v.GetField(), v.GetDescription())
+			}
+		case *errdetails.ErrorInfo:
+			fmt.Fprintf(w, "  reason %s (%s) %v\n", d.GetReason(), d.GetDomain(), d.GetMetadata())
+		}
+	}
+}
+
+func unixTime(sec int64) string {
+	if sec == 0 {
+		return "-"
+	}
+	return time.Unix(sec, 0).Local().Format(time.DateTime)
+}
@@ -0,0 +1,308 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+
+	// Драйвер SQLite для database/sql (регистрируется как "sqlite3"). Требует cgo.
+	_ "github.com/mattn/go-sqlite3"
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/verazalayli/go_studying/grpc/pkg/auth"
+	"github.com/verazalayli/go_studying/grpc/pkg/janitor"
+	"github.com/verazalayli/go_studying/grpc/pkg/repository/file"
+	"github.com/verazalayli/go_studying/grpc/pkg/repository/memory"
+	redisrepo "github.com/verazalayli/go_studying/grpc/pkg/repository/redis"
+	sqlrepo "github.com/verazalayli/go_studying/grpc/pkg/repository/sql"
+	"github.com/verazalayli/go_studying/grpc/pkg/service"
+	"github.com/verazalayli/go_studying/grpc/pkg/tlsutil"
+)
+
+// config — настройки сервера. Читаются из переменных окружения,
+// у каждой есть значение по умолчанию.
+type config struct {
+	Port int // PORT
+
+	// HTTPPort — порт REST-шлюза (JSON поверх HTTP); 0 — не поднимать.
+	HTTPPort int // HTTP_PORT
+	// AdminPort — HTTP-порт с метриками (/metrics); 0 — не поднимать.
+	AdminPort int // ADMIN_PORT
+	// ShutdownTimeout — сколько ждать завершения RPC при остановке, потом Stop.
+	ShutdownTimeout time.Duration // NOTES_SHUTDOWN_TIMEOUT
+	// IdempotencyWindow — сколько помнить ключи идемпотентности CreateNote; 0 — не помнить.
+	IdempotencyWindow time.Duration // NOTES_IDEMPOTENCY_WINDOW
+	// TrashRetention — сколько удалённая заметка лежит в корзине до окончательного
+	// удаления; 0 — не удалять автоматически.
+	TrashRetention time.Duration // NOTES_TRASH_RETENTION
+	// JanitorInterval — как часто проверять корзину.
+	JanitorInterval time.Duration // NOTES_JANITOR_INTERVAL
+	// BlobDir — каталог с содержимым вложений (по SHA-256), общий для любого Storage.
+	BlobDir string // NOTES_BLOB_DIR
+	// MaxAttachmentSize — лимит размера одного вложения в байтах.
+	MaxAttachmentSize int64 // NOTES_MAX_ATTACHMENT_SIZE
+	// LogFormat — формат логов: "text" (по умолчанию) или "json".
+	LogFormat string // LOG_FORMAT
+
+	// TLS: если задан сертификат, сервер слушает только TLS.
+	TLSCert     string        // NOTES_TLS_CERT — PEM сертификата сервера
+	TLSKey      string        // NOTES_TLS_KEY — PEM ключа сервера
+	TLSClientCA string        // NOTES_TLS_CLIENT_CA — CA клиентов; задан — включается mTLS
+	TLSReload   time.Duration // NOTES_TLS_RELOAD — как часто перечитывать файлы (0 — никогда)
+
+	// Аутентификация: если задан ключ, каждый вызов требует JWT (HS256) этим ключом.
+	AuthKey      string // NOTES_AUTH_KEY — общий секрет HMAC
+	AuthIssuer   string // NOTES_AUTH_ISSUER — ожидаемый iss (пусто — не проверять)
+	AuthAudience string // NOTES_AUTH_AUDIENCE — ожидаемый aud (пусто — не проверять)
+
+	// Storage — какое хранилище поднять: "memory" (по умолчанию), "file", "sqlite" или "redis".
+	Storage string // NOTES_STORAGE
+
+	// Для Storage == "file":
+	DataDir      string          // NOTES_DATA_DIR — каталог с WAL и снимком
+	FileSync     file.SyncPolicy // NOTES_FSYNC — "always" | "interval" | "never"
+	CompactEvery int             // NOTES_COMPACT_EVERY — записей WAL между снимками
+
+	// Для Storage == "sqlite":
+	SQLiteDSN string // NOTES_SQLITE_DSN — DSN драйвера mattn/go-sqlite3
+
+	// Для Storage == "redis":
+	RedisAddr     string        // NOTES_REDIS_ADDR
+	RedisPassword string        // NOTES_REDIS_PASSWORD
+	RedisPrefix   string        // NOTES_REDIS_PREFIX — префикс всех ключей
+	RedisTTL      time.Duration // NOTES_REDIS_TTL — срок жизни заметки после записи (0 — бессрочно)
+}
+
+func loadConfig() (config, error) {
+	cfg := config{
+		Port:              50051,
+		HTTPPort:          8080,
+		AdminPort:         9090,
+		ShutdownTimeout:   10 * time.Second,
+		IdempotencyWindow: service.DefaultIdempotencyWindow,
+		TrashRetention:    30 * 24 * time.Hour,
+		JanitorInterval:   janitor.DefaultInterval,
+		BlobDir:           env("NOTES_BLOB_DIR", "data/blobs"),
+		MaxAttachmentSize: service.DefaultMaxAttachmentSize,
+		LogFormat:         env("LOG_FORMAT", "text"),
+		TLSCert:           env("NOTES_TLS_CERT", ""),
+		TLSKey:            env("NOTES_TLS_KEY", ""),
+		TLSClientCA:       env("NOTES_TLS_CLIENT_CA", ""),
+		TLSReload:         tlsutil.DefaultReloadInterval,
+		AuthKey:           env("NOTES_AUTH_KEY", ""),
+		AuthIssuer:        env("NOTES_AUTH_ISSUER", ""),
+		AuthAudience:      env("NOTES_AUTH_AUDIENCE", ""),
+		Storage:           env("NOTES_STORAGE", "memory"),
+		DataDir:           env("NOTES_DATA_DIR", "data/notes"),
+		SQLiteDSN:         env("NOTES_SQLITE_DSN", "file:data/notes.db?_journal_mode=WAL&_busy_timeout=5000"),
+		RedisAddr:         env("NOTES_REDIS_ADDR", "127.0.0.1:6379"),
+		RedisPassword:     env("NOTES_REDIS_PASSWORD", ""),
+		RedisPrefix:       env("NOTES_REDIS_PREFIX", "notes:"),
+		CompactEvery:      file.DefaultCompactEvery,
+	}
+	if p := os.Getenv("PORT"); p != "" {
+		if v, err := strconv.Atoi(p); err == nil {
+			cfg.Port = v
+		}
+	}
+	if p := os.Getenv("HTTP_PORT"); p != "" {
+		v, err := strconv.Atoi(p)
+		if err != nil {
+			return config{}, fmt.Errorf("invalid HTTP_PORT: %w", err)
+		}
+		cfg.HTTPPort = v
+	}
+	if p := os.Getenv("ADMIN_PORT"); p != "" {
+		v, err := strconv.Atoi(p)
+		if err != nil {
+			return config{}, fmt.Errorf("invalid ADMIN_PORT: %w", err)
+		}
+		cfg.AdminPort = v
+	}
+	if cfg.LogFormat != "text" && cfg.LogFormat != "json" {
+		return config{}, fmt.Errorf("unknown LOG_FORMAT %q", cfg.LogFormat)
+	}
+	if (cfg.TLSCert == "") != (cfg.TLSKey == "") {
+		return config{}, fmt.Errorf("NOTES_TLS_CERT and NOTES_TLS_KEY must be set together")
+	}
+	if cfg.TLSClientCA != "" && cfg.TLSCert == "" {
+		return config{}, fmt.Errorf("NOTES_TLS_CLIENT_CA requires NOTES_TLS_CERT and NOTES_TLS_KEY")
+	}
+	if v := os.Getenv("NOTES_SHUTDOWN_TIMEOUT"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return config{}, fmt.Errorf("invalid NOTES_SHUTDOWN_TIMEOUT: %w", err)
+		}
+		cfg.ShutdownTimeout = d
+	}
+	if v := os.Getenv("NOTES_IDEMPOTENCY_WINDOW"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return config{}, fmt.Errorf("invalid NOTES_IDEMPOTENCY_WINDOW: %w", err)
+		}
+		cfg.IdempotencyWindow = d
+	}
+	if v := os.Getenv("NOTES_TRASH_RETENTION"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return config{}, fmt.Errorf("invalid NOTES_TRASH_RETENTION: %w", err)
+		}
+		cfg.TrashRetention = d
+	}
+	if v := os.Getenv("NOTES_JANITOR_INTERVAL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return config{}, fmt.Errorf("invalid NOTES_JANITOR_INTERVAL: %w", err)
+		}
+		if d <= 0 {
+			return config{}, fmt.Errorf("NOTES_JANITOR_INTERVAL must be positive")
+		}
+		cfg.JanitorInterval = d
+	}
+	if v := os.Getenv("NOTES_MAX_ATTACHMENT_SIZE"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return config{}, fmt.Errorf("invalid NOTES_MAX_ATTACHMENT_SIZE: %w", err)
+		}
+		if n <= 0 {
+			return config{}, fmt.Errorf("NOTES_MAX_ATTACHMENT_SIZE must be positive")
+		}
+		cfg.MaxAttachmentSize = n
+	}
+	if v := os.Getenv("NOTES_TLS_RELOAD"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return config{}, fmt.Errorf("invalid NOTES_TLS_RELOAD: %w", err)
+		}
+		cfg.TLSReload = d
+	}
+	switch s := env("NOTES_FSYNC", "always"); s {
+	case "always":
+		cfg.FileSync = file.SyncAlways
+	case "interval":
+		cfg.FileSync = file.SyncInterval
+	case "never":
+		cfg.FileSync = file.SyncNever
+	default:
+		return config{}, fmt.Errorf("unknown NOTES_FSYNC %q", s)
+	}
+	if v := os.Getenv("NOTES_COMPACT_EVERY"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return config{}, fmt.Errorf("invalid NOTES_COMPACT_EVERY: %w", err)
+		}
+		cfg.CompactEvery = n
+	}
+	if v := os.Getenv("NOTES_REDIS_TTL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return config{}, fmt.Errorf("invalid NOTES_REDIS_TTL: %w", err)
+		}
+		if d < 0 {
+			return config{}, fmt.Errorf("NOTES_REDIS_TTL must not be negative")
+		}
+		cfg.RedisTTL = d
+	}
+	return cfg, nil
+}
+
+// newRepository собирает хранилище по конфигу.
+// Возвращает функцию закрытия: файловому хранилищу нужно дописать снимок и закрыть журнал.
+func newRepository(cfg config) (service.NoteRepository, func() error, error) {
+	switch cfg.Storage {
+	case "memory":
+		return memory.NewNoteRepo(), func() error { return nil }, nil
+	case "file":
+		repo, err := file.NewNoteRepo(cfg.DataDir,
+			file.WithSyncPolicy(cfg.FileSync),
+			file.WithCompactEvery(cfg.CompactEvery),
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+		return repo, repo.Close, nil
+	case "sqlite":
+		db, err := sql.Open("sqlite3", cfg.SQLiteDSN)
+		if err != nil {
+			return nil, nil, err
+		}
+		// SQLite допускает одного писателя; одно соединение убирает ошибки "database is locked".
+		db.SetMaxOpenConns(1)
+		repo, err := sqlrepo.NewNoteRepo(context.Background(), db)
+		if err != nil {
+			db.Close()
+			return nil, nil, err
+		}
+		return repo, func() error {
+			repo.Close()
+			return db.Close()
+		}, nil
+	case "redis":
+		rdb := goredis.NewClient(&goredis.Options{Addr: cfg.RedisAddr, Password: cfg.RedisPassword})
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+		if err := rdb.Ping(ctx).Err(); err != nil {
+			rdb.Close()
+			return nil, nil, fmt.Errorf("redis ping: %w", err)
+		}
+		repo := redisrepo.NewNoteRepo(rdb,
+			redisrepo.WithKeyPrefix(cfg.RedisPrefix),
+			redisrepo.WithTTL(cfg.RedisTTL),
+		)
+		return repo, rdb.Close, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown NOTES_STORAGE %q", cfg.Storage)
+	}
+}
+
+// env — чтение переменной окружения со значением по умолчанию.
+func env(key, def string) string {
+	if val := os.Getenv(key); val != "" {
+		return val
+	}
+	return def
+}
+
+// newLogger — slog-логгер для access-логов и паник в перехватчиках.
+func newLogger(cfg config) *slog.Logger {
+	if cfg.LogFormat == "json" {
+		return slog.New(slog.NewJSONHandler(os.Stderr, nil))
+	}
+	return slog.New(slog.NewTextHandler(os.Stderr, nil))
+}
+
+// newTLSReloader — TLS-сертификат сервера с перечитыванием с диска; из него строятся
+// конфиги и для gRPC, и для REST-шлюза. nil — TLS не настроен, сервер работает без шифрования.
+func newTLSReloader(cfg config) (*tlsutil.Reloader, error) {
+	if cfg.TLSCert == "" {
+		return nil, nil
+	}
+	opts := []tlsutil.Option{
+		tlsutil.WithReloadInterval(cfg.TLSReload),
+		tlsutil.WithOnError(func(err error) { log.Printf("%v (keeping previous certificate)", err) }),
+	}
+	if cfg.TLSClientCA != "" {
+		opts = append(opts, tlsutil.WithClientCA(cfg.TLSClientCA))
+	}
+	return tlsutil.NewReloader(cfg.TLSCert, cfg.TLSKey, opts...)
+}
+
+// newVerifier — проверка токенов; nil — аутентификация выключена.
+func newVerifier(cfg config) auth.Verifier {
+	if cfg.AuthKey == "" {
+		return nil
+	}
+	var opts []auth.VerifierOption
+	if cfg.AuthIssuer != "" {
+		opts = append(opts, auth.WithIssuer(cfg.AuthIssuer))
+	}
+	if cfg.AuthAudience != "" {
+		opts = append(opts, auth.WithAudience(cfg.AuthAudience))
+	}
+	return auth.NewHMACVerifier([]byte(cfg.AuthKey), opts...)
+}
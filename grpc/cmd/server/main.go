@@ -2,45 +2,81 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log"
 	"net"
-	"os"
+	"net/http"
 	"os/signal"
 	"strconv"
 	"syscall"
+	"time"
 
 	// gRPC серверная библиотека (HTTP/2 транспорт, маршрутизация RPC, кодеки и т.д.)
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/keepalive"
+	// Стандартные health-check и reflection из grpc-go.
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
 
-	// Наш входной адаптер транспорта: gRPC-обработчик сервиса заметок.
+	// Наши входные адаптеры транспорта: gRPC-обработчик сервиса заметок и REST-шлюз к тому же сервису.
 	grpch "github.com/verazalayli/go_studying/grpc/pkg/handler/grpc"
-	// Репозиторий в памяти — реализация интерфейса хранилища (порт прикладного слоя).
-	"github.com/verazalayli/go_studying/grpc/pkg/repository/memory"
+	"github.com/verazalayli/go_studying/grpc/pkg/handler/rest"
+	// Перехватчики: recovery, access-логи, request id, метрики.
+	"github.com/verazalayli/go_studying/grpc/pkg/middleware"
+	// Связка health-check с доступностью хранилища.
+	"github.com/verazalayli/go_studying/grpc/pkg/healthcheck"
+	// Фоновая очистка корзины.
+	"github.com/verazalayli/go_studying/grpc/pkg/janitor"
+	// Хранилище содержимого вложений на диске.
+	"github.com/verazalayli/go_studying/grpc/pkg/repository/blob"
 	// Прикладной слой (use cases): бизнес-логика и интерфейс порта NoteRepository.
 	"github.com/verazalayli/go_studying/grpc/pkg/service"
+	"github.com/verazalayli/go_studying/grpc/proto/pb"
 )
 
 func main() {
-	// 1) Выбираем порт, на котором будет слушать gRPC-сервер.
-	//    Можно задать через переменную окружения PORT.
-	port := 50051
-	if p := os.Getenv("PORT"); p != "" {
-		if v, err := strconv.Atoi(p); err == nil {
-			port = v
-		}
+	// 1) Читаем конфигурацию из переменных окружения (см. config.go):
+	//    порты (PORT, HTTP_PORT, ADMIN_PORT), тип хранилища
+	//    (NOTES_STORAGE=memory|file|sqlite|redis и его параметры), TLS, токены, корзину.
+	cfg, err := loadConfig()
+	if err != nil {
+		log.Fatalf("config: %v", err)
 	}
+	port := cfg.Port
 
 	// 2) КОМПОЗИЦИЯ ЗАВИСИМОСТЕЙ (Composition Root).
 	//    Склеиваем слои строго «снаружи вовнутрь»:
 	//    transport(gRPC handler) -> service(use cases) -> repository(хранилище).
 	//
-	//    ↓ Хранилище: in-memory реализация. В проде легко заменить на Postgres/Mongo
-	//      просто подставив другой пакет, реализующий тот же интерфейс service.NoteRepository.
-	repo := memory.NewNoteRepo()
+	//    ↓ Хранилище: in-memory, файловое (WAL + снимки), SQLite или Redis — выбирается конфигом.
+	//      Все реализуют один интерфейс service.NoteRepository, поэтому слои выше не меняются.
+	repo, closeRepo, err := newRepository(cfg)
+	if err != nil {
+		log.Fatalf("storage init failed: %v", err)
+	}
+	defer func() {
+		if err := closeRepo(); err != nil {
+			log.Printf("storage close error: %v", err)
+		}
+	}()
+	log.Printf("storage: %s", cfg.Storage)
+
+	//    ↓ Содержимое вложений — отдельное хранилище на диске (второй исходящий порт, service.BlobStore).
+	blobs, err := blob.NewStore(cfg.BlobDir)
+	if err != nil {
+		log.Fatalf("blob storage init failed: %v", err)
+	}
 
 	//    ↓ Прикладной слой (use cases): инкапсулирует бизнес-правила.
 	//      Он знает ТОЛЬКО про абстрактный NoteRepository (порт), а не про конкретную БД.
-	svc := service.NewNoteService(repo)
+	//      Ключи идемпотентности CreateNote он помнит NOTES_IDEMPOTENCY_WINDOW.
+	svc := service.NewNoteService(repo,
+		service.WithIdempotencyWindow(cfg.IdempotencyWindow),
+		service.WithBlobStore(blobs),
+		service.WithMaxAttachmentSize(cfg.MaxAttachmentSize),
+	)
 
 	//    ↓ Транспортный адаптер: gRPC-хендлер, который:
 	//      - получает protobuf-запросы,
@@ -52,8 +88,37 @@ func main() {
 	//    Вызов grpc.NewServer() настраивает серверный рантайм:
 	//     - HTTP/2 обработку фреймов,
 	//     - регистрацию сервисов (ниже),
-	//     - опционально интерсепторы, кредитный контроль, лимиты и т.д. (можно передавать опции).
-	grpcServer := grpc.NewServer()
+	//     - цепочку перехватчиков (interceptors), которые оборачивают каждый RPC:
+	//       request id -> access-лог -> метрики -> auth -> recovery -> наш handler.
+	//       Порядок собран в middleware.ServerOptions.
+	//     - TLS, если он настроен (NOTES_TLS_CERT/NOTES_TLS_KEY, для mTLS ещё NOTES_TLS_CLIENT_CA).
+	logger := newLogger(cfg)
+	metrics := middleware.NewMetrics()
+	//     - проверку bearer-токенов (если задан NOTES_AUTH_KEY); без неё все вызовы
+	//       анонимные и видят только заметки без владельца.
+	verifier := newVerifier(cfg)
+	if verifier == nil {
+		log.Printf("auth: disabled (set NOTES_AUTH_KEY to require tokens)")
+	}
+	//       Health-check доступен без токена: оркестратор токенов не знает.
+	serverOpts := middleware.ServerOptions(logger, metrics, verifier,
+		"/"+healthpb.Health_ServiceDesc.ServiceName+"/")
+	//     - keepalive: клиенты (pkg/client) пингуют соединение раз в 30 секунд простоя;
+	//       по умолчанию сервер считает пинги чаще 5 минут злоупотреблением и рвёт соединение.
+	serverOpts = append(serverOpts, grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+		MinTime:             10 * time.Second,
+		PermitWithoutStream: true,
+	}))
+	tlsReloader, err := newTLSReloader(cfg)
+	if err != nil {
+		log.Fatalf("tls init failed: %v", err)
+	}
+	if tlsReloader != nil {
+		defer tlsReloader.Close()
+		serverOpts = append(serverOpts, grpc.Creds(credentials.NewTLS(tlsReloader.ServerConfig())))
+		log.Printf("tls: enabled (mutual: %t)", cfg.TLSClientCA != "")
+	}
+	grpcServer := grpc.NewServer(serverOpts...)
 
 	// 4) Регистрируем наш gRPC-сервис в сервере.
 	//    Внутри grpch.Register(...) вызывается сгенерённая функця pb.RegisterNoteServiceServer,
@@ -61,11 +126,34 @@ func main() {
 	//    дернуть соответствующий метод у нашего handler (NoteHandler).
 	grpch.Register(grpcServer, handler)
 
+	// 4.1) Стандартный grpc.health.v1.Health: статус зависит от доступности хранилища
+	//      (service.Pinger), Checker перепроверяет его в фоне. Отвечает и за весь сервер (""),
+	//      и за note.v1.NoteService отдельно.
+	healthSrv := health.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, healthSrv)
+	var probe func(context.Context) error
+	if p, ok := repo.(service.Pinger); ok {
+		probe = p.Ping
+	}
+	checker := healthcheck.New(healthSrv, probe, []string{pb.NoteService_ServiceDesc.ServiceName},
+		healthcheck.WithOnChange(func(serving bool, err error) {
+			if serving {
+				log.Printf("health: SERVING")
+			} else {
+				log.Printf("health: NOT_SERVING: %v", err)
+			}
+		}),
+	)
+
+	// 4.2) Server reflection: grpcurl и похожие инструменты узнают схему у самого сервера,
+	//      без .proto-файла (grpcurl -plaintext localhost:50051 list).
+	reflection.Register(grpcServer)
+
 	// 5) Открываем TCP-слушатель.
 	//    net.Listen создаёт сокет и начинает слушать порт :<port>.
 	//    Дальше grpcServer.Serve(lis) примет этот listener и будет:
 	//      - принимать TCP соединения,
-	//      - апгрейдить до HTTP/2 (h2c, если без TLS),
+	//      - делать TLS-рукопожатие (если включён TLS) и поднимать HTTP/2 (h2c, если без TLS),
 	//      - читать gRPC-фреймы,
 	//      - диспатчить их в нужные зарегистрированные методы обработчика.
 	lis, err := net.Listen("tcp", ":"+strconv.Itoa(port))
@@ -78,16 +166,114 @@ func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
+	go checker.Run(ctx)
+
+	// 6.1) Janitor окончательно удаляет заметки, пролежавшие в корзине дольше
+	//      NOTES_TRASH_RETENTION. Он останавливается по тому же контексту сигналов,
+	//      а main дожидается его (janitorDone) до закрытия хранилища.
+	janitorDone := make(chan struct{})
+	if cfg.TrashRetention > 0 {
+		j := janitor.New(svc.PurgeTrash, cfg.TrashRetention,
+			janitor.WithInterval(cfg.JanitorInterval),
+			janitor.WithOnPurge(func(purged int, err error) {
+				if err != nil {
+					log.Printf("janitor: purged %d notes, then failed: %v", purged, err)
+					return
+				}
+				log.Printf("janitor: purged %d notes older than %s in trash", purged, cfg.TrashRetention)
+			}),
+		)
+		go func() {
+			defer close(janitorDone)
+			j.Run(ctx)
+		}()
+	} else {
+		close(janitorDone)
+	}
+
 	// 7) Фоновая горутина, которая ждёт отмены контекста (сигнала) и мягко останавливает сервер.
+	//    Сначала health переходит в NOT_SERVING — балансировщик перестаёт слать новые запросы.
 	//    GracefulStop:
 	//      - перестаёт принимать новые соединения,
 	//      - ждёт завершения активных RPC,
 	//      - закрывает слушатели и соединения корректно.
+	//    Если RPC не уложились в NOTES_SHUTDOWN_TIMEOUT (например, висит поток WatchNotes),
+	//    Stop рвёт оставшиеся соединения.
 	go func() {
 		<-ctx.Done()
-		grpcServer.GracefulStop()
+		checker.Shutdown()
+		stopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-time.After(cfg.ShutdownTimeout):
+			log.Printf("graceful stop timed out after %s, forcing stop", cfg.ShutdownTimeout)
+			grpcServer.Stop()
+		}
 	}()
 
+	// 7.1) Админский HTTP-порт: метрики в формате Prometheus на /metrics.
+	//      Отдельный порт, чтобы метрики не торчали наружу вместе с API.
+	if cfg.AdminPort != 0 {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", metrics)
+		admin := &http.Server{Addr: ":" + strconv.Itoa(cfg.AdminPort), Handler: mux}
+		go func() {
+			log.Printf("admin HTTP server starting on :%d\n", cfg.AdminPort)
+			if err := admin.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Printf("admin server error: %v", err)
+			}
+		}()
+		defer admin.Close()
+	}
+
+	// 7.2) REST-шлюз: тот же service.NoteService по HTTP/JSON для клиентов без gRPC
+	//      (маршруты — в pkg/handler/rest). Токен и TLS — те же, что у gRPC;
+	//      при остановке ждём активные запросы не дольше NOTES_SHUTDOWN_TIMEOUT,
+	//      а main дожидается остановки (restDone) до закрытия хранилища.
+	restDone := make(chan struct{})
+	if cfg.HTTPPort != 0 {
+		restOpts := []rest.Option{rest.WithLogger(logger)}
+		if verifier != nil {
+			restOpts = append(restOpts, rest.WithVerifier(verifier))
+		}
+		restSrv := &http.Server{
+			Addr:              ":" + strconv.Itoa(cfg.HTTPPort),
+			Handler:           rest.New(svc, restOpts...),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		if tlsReloader != nil {
+			restSrv.TLSConfig = tlsReloader.ServerConfig()
+		}
+		go func() {
+			log.Printf("REST gateway starting on :%d\n", cfg.HTTPPort)
+			var err error
+			if restSrv.TLSConfig != nil {
+				err = restSrv.ListenAndServeTLS("", "")
+			} else {
+				err = restSrv.ListenAndServe()
+			}
+			if err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Printf("REST gateway error: %v", err)
+			}
+		}()
+		go func() {
+			defer close(restDone)
+			<-ctx.Done()
+			sctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+			defer cancel()
+			if err := restSrv.Shutdown(sctx); err != nil {
+				log.Printf("REST gateway shutdown: %v", err)
+				restSrv.Close()
+			}
+		}()
+	} else {
+		close(restDone)
+	}
+
 	// 8) Запускаем главный цикл gRPC-сервера.
 	//    Serve блокируется и:
 	//      - принимает входящие соединения/стримы,
@@ -102,7 +288,12 @@ func main() {
 	//    Если вернулась ошибка — логируем фатально (обычно это проблемы на уровне listener'а).
 	log.Printf("gRPC server starting on :%d\n", port)
 	if err := grpcServer.Serve(lis); err != nil {
-		log.Fatalf("serve error: %v", err)
+		log.Printf("serve error: %v", err)
 	}
 	log.Println("gRPC server stopped")
+	// Serve мог завершиться и без сигнала (ошибка listener'а) — тогда останавливаем
+	// фоновые задачи сами. Хранилище закроется (defer) только после них.
+	stop()
+	<-janitorDone
+	<-restDone
 }
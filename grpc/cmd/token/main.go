@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/verazalayli/go_studying/grpc/pkg/auth"
+)
+
+// token выпускает JWT (HS256) для локальной проверки аутентификации.
+// Ключ берётся из NOTES_AUTH_KEY — тот же, что у сервера:
+//
+//	NOTES_AUTH_KEY=secret go run ./grpc/cmd/token -sub alice
+func main() {
+	sub := flag.String("sub", "", "владелец заметок (claim sub)")
+	ttl := flag.Duration("ttl", time.Hour, "срок жизни токена")
+	iss := flag.String("iss", os.Getenv("NOTES_AUTH_ISSUER"), "claim iss")
+	aud := flag.String("aud", os.Getenv("NOTES_AUTH_AUDIENCE"), "claim aud")
+	flag.Parse()
+
+	key := os.Getenv("NOTES_AUTH_KEY")
+	if key == "" || *sub == "" {
+		log.Fatal("NOTES_AUTH_KEY and -sub are required")
+	}
+	now := time.Now()
+	c := auth.Claims{
+		Subject:   *sub,
+		Issuer:    *iss,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(*ttl).Unix(),
+	}
+	if *aud != "" {
+		c.Audience = auth.Audience{*aud}
+	}
+	tok, err := auth.SignHS256([]byte(key), c)
+	if err != nil {
+		log.Fatalf("sign: %v", err)
+	}
+	fmt.Println(tok)
+}
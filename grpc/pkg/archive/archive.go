@@ -0,0 +1,304 @@
+// Package archive — файл резервной копии заметок (ExportNotes/ImportNotes, CLI notes export/import).
+//
+// Файл — последовательность записей pb.ArchiveRecord: header, затем note на каждую
+// заметку (с историей), последней — manifest с числом заметок и SHA-256. Кодировки две:
+//   - JSON Lines: запись protojson в строке — удобно смотреть глазами и разбирать jq;
+//   - protobuf: записи с префиксом длины (varint), как в protodelim, — компактнее и быстрее.
+//
+// Любая из них может быть сжата gzip. Reader определяет сжатие и кодировку по первым байтам.
+//
+// Контрольная сумма (Checksum) считается не по байтам файла, а по каноническому
+// protobuf-представлению заметок: она одинакова в обеих кодировках, со сжатием и без,
+// и ту же сумму присылает сервер в конце ExportNotes. Файл без manifest — оборванный.
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protodelim"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/verazalayli/go_studying/grpc/proto/pb"
+)
+
+// FormatVersion — версия формата, которую пишет Writer; Reader читает её и более ранние.
+const FormatVersion = 1
+
+// MaxRecordSize — предел размера одной записи при чтении.
+const MaxRecordSize = 64 << 20 // 64 MiB
+
+// Format — кодировка записей в файле.
+type Format int
+
+const (
+	JSONL Format = iota
+	Protobuf
+)
+
+// ParseFormat разбирает имя кодировки: "jsonl" или "protobuf".
+func ParseFormat(s string) (Format, error) {
+	switch s {
+	case "jsonl":
+		return JSONL, nil
+	case "protobuf":
+		return Protobuf, nil
+	default:
+		return 0, fmt.Errorf("unknown archive format %q (want jsonl or protobuf)", s)
+	}
+}
+
+func (f Format) String() string {
+	if f == Protobuf {
+		return "protobuf"
+	}
+	return "jsonl"
+}
+
+// Ошибки чтения архива.
+var (
+	ErrTruncated        = errors.New("archive is truncated: no manifest at the end")
+	ErrChecksumMismatch = errors.New("archive checksum mismatch")
+	ErrMalformed        = errors.New("malformed archive")
+)
+
+var (
+	jsonMarshal   = protojson.MarshalOptions{UseProtoNames: true}
+	jsonUnmarshal = protojson.UnmarshalOptions{}
+)
+
+// Checksum считает manifest по заметкам архива.
+type Checksum struct {
+	h hash.Hash
+	n int64
+}
+
+func NewChecksum() *Checksum {
+	return &Checksum{h: sha256.New()}
+}
+
+// Add учитывает заметку: в хеш идут её детерминированная protobuf-кодировка
+// и длина перед ней, чтобы границы между заметками тоже были частью суммы.
+func (c *Checksum) Add(n *pb.ExportedNote) error {
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(n)
+	if err != nil {
+		return err
+	}
+	var l [binary.MaxVarintLen64]byte
+	c.h.Write(l[:binary.PutUvarint(l[:], uint64(len(b)))])
+	c.h.Write(b)
+	c.n++
+	return nil
+}
+
+// Manifest — число учтённых заметок и их сумма на текущий момент.
+func (c *Checksum) Manifest() *pb.ArchiveManifest {
+	return &pb.ArchiveManifest{NoteCount: c.n, Sha256: hex.EncodeToString(c.h.Sum(nil))}
+}
+
+// SameManifest — совпадают ли два manifest.
+func SameManifest(a, b *pb.ArchiveManifest) bool {
+	return a.GetNoteCount() == b.GetNoteCount() && a.GetSha256() == b.GetSha256()
+}
+
+// Writer пишет архив: header сразу, заметки по одной, manifest — в Close.
+type Writer struct {
+	format Format
+	bw     *bufio.Writer
+	gz     *gzip.Writer
+	sum    *Checksum
+}
+
+// NewWriter начинает архив в w; compress — сжать gzip. w закрывает вызывающий.
+func NewWriter(w io.Writer, format Format, compress bool) (*Writer, error) {
+	aw := &Writer{format: format, sum: NewChecksum()}
+	if compress {
+		aw.gz = gzip.NewWriter(w)
+		w = aw.gz
+	}
+	aw.bw = bufio.NewWriter(w)
+	header := &pb.ArchiveHeader{FormatVersion: FormatVersion, CreatedAt: time.Now().Unix()}
+	if err := aw.write(&pb.ArchiveRecord{Record: &pb.ArchiveRecord_Header{Header: header}}); err != nil {
+		return nil, err
+	}
+	return aw, nil
+}
+
+func (w *Writer) Write(n *pb.ExportedNote) error {
+	if err := w.sum.Add(n); err != nil {
+		return err
+	}
+	return w.write(&pb.ArchiveRecord{Record: &pb.ArchiveRecord_Note{Note: n}})
+}
+
+// Manifest — manifest того, что уже записано (его же допишет Close).
+func (w *Writer) Manifest() *pb.ArchiveManifest {
+	return w.sum.Manifest()
+}
+
+// Close дописывает manifest и сбрасывает буферы (и gzip).
+func (w *Writer) Close() error {
+	if err := w.write(&pb.ArchiveRecord{Record: &pb.ArchiveRecord_Manifest{Manifest: w.sum.Manifest()}}); err != nil {
+		return err
+	}
+	if err := w.bw.Flush(); err != nil {
+		return err
+	}
+	if w.gz != nil {
+		return w.gz.Close()
+	}
+	return nil
+}
+
+func (w *Writer) write(rec *pb.ArchiveRecord) error {
+	if w.format == Protobuf {
+		_, err := protodelim.MarshalTo(w.bw, rec)
+		return err
+	}
+	b, err := jsonMarshal.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	if _, err := w.bw.Write(b); err != nil {
+		return err
+	}
+	return w.bw.WriteByte('\n')
+}
+
+// Reader читает архив и на последней записи сверяет manifest с прочитанным.
+type Reader struct {
+	format     Format
+	compressed bool
+	br         *bufio.Reader
+	lines      *bufio.Scanner // строки JSON Lines поверх br
+	gz         *gzip.Reader
+	header     *pb.ArchiveHeader
+	sum        *Checksum
+	manifest   *pb.ArchiveManifest
+}
+
+// NewReader определяет сжатие и кодировку и читает header.
+func NewReader(r io.Reader) (*Reader, error) {
+	ar := &Reader{br: bufio.NewReader(r), sum: NewChecksum()}
+	if magic, _ := ar.br.Peek(2); bytes.Equal(magic, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(ar.br)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+		}
+		ar.gz, ar.compressed = gz, true
+		ar.br = bufio.NewReader(gz)
+	}
+	// Запись JSON начинается с '{'; в protobuf первый байт — длина header,
+	// а header заведомо короче 123 байт ('{' == 123).
+	first, err := ar.br.Peek(1)
+	if err != nil {
+		return nil, fmt.Errorf("%w: empty archive", ErrMalformed)
+	}
+	if first[0] != '{' {
+		ar.format = Protobuf
+	}
+
+	rec, err := ar.read()
+	if err != nil {
+		return nil, err
+	}
+	ar.header = rec.GetHeader()
+	switch {
+	case ar.header == nil:
+		return nil, fmt.Errorf("%w: the first record is not a header", ErrMalformed)
+	case ar.header.GetFormatVersion() < 1 || ar.header.GetFormatVersion() > FormatVersion:
+		return nil, fmt.Errorf("%w: unsupported format version %d", ErrMalformed, ar.header.GetFormatVersion())
+	}
+	return ar, nil
+}
+
+func (r *Reader) Format() Format                { return r.format }
+func (r *Reader) Compressed() bool              { return r.compressed }
+func (r *Reader) Header() *pb.ArchiveHeader     { return r.header }
+func (r *Reader) Manifest() *pb.ArchiveManifest { return r.manifest }
+
+// Next возвращает следующую заметку. После последней — io.EOF, если manifest
+// совпал с прочитанным, иначе ErrChecksumMismatch или ErrTruncated.
+func (r *Reader) Next() (*pb.ExportedNote, error) {
+	if r.manifest != nil {
+		return nil, io.EOF
+	}
+	rec, err := r.read()
+	if err == io.EOF {
+		return nil, ErrTruncated
+	}
+	if err != nil {
+		return nil, err
+	}
+	switch rec := rec.GetRecord().(type) {
+	case *pb.ArchiveRecord_Note:
+		if err := r.sum.Add(rec.Note); err != nil {
+			return nil, err
+		}
+		return rec.Note, nil
+	case *pb.ArchiveRecord_Manifest:
+		if got := r.sum.Manifest(); !SameManifest(got, rec.Manifest) {
+			return nil, fmt.Errorf("%w: read %d notes with sha256 %s, manifest says %d notes with sha256 %s",
+				ErrChecksumMismatch, got.GetNoteCount(), got.GetSha256(), rec.Manifest.GetNoteCount(), rec.Manifest.GetSha256())
+		}
+		if _, err := r.read(); err != io.EOF {
+			return nil, fmt.Errorf("%w: data after manifest", ErrMalformed)
+		}
+		r.manifest = rec.Manifest
+		return nil, io.EOF
+	default:
+		return nil, fmt.Errorf("%w: unexpected record %T", ErrMalformed, rec)
+	}
+}
+
+// Close освобождает gzip; исходный reader закрывает вызывающий.
+func (r *Reader) Close() error {
+	if r.gz != nil {
+		return r.gz.Close()
+	}
+	return nil
+}
+
+// read читает одну запись; чистый конец файла — io.EOF.
+func (r *Reader) read() (*pb.ArchiveRecord, error) {
+	rec := &pb.ArchiveRecord{}
+	if r.format == Protobuf {
+		err := protodelim.UnmarshalOptions{MaxSize: MaxRecordSize}.UnmarshalFrom(r.br, rec)
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+		}
+		return rec, nil
+	}
+	// Строка длиннее MaxRecordSize обрывает чтение сразу, не дочитывая её в память.
+	if r.lines == nil {
+		r.lines = bufio.NewScanner(r.br)
+		r.lines.Buffer(nil, MaxRecordSize)
+	}
+	for r.lines.Scan() {
+		if line := bytes.TrimSpace(r.lines.Bytes()); len(line) > 0 {
+			if err := jsonUnmarshal.Unmarshal(line, rec); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+			}
+			return rec, nil
+		}
+	}
+	if err := r.lines.Err(); errors.Is(err, bufio.ErrTooLong) {
+		return nil, fmt.Errorf("%w: record longer than %d bytes", ErrMalformed, MaxRecordSize)
+	} else if err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
@@ -0,0 +1,36 @@
+package auth
+
+import (
+	"context"
+	"strings"
+)
+
+// BearerCredentials — токен для клиента gRPC (реализует credentials.PerRPCCredentials):
+// добавляет "authorization: Bearer <token>" в метаданные каждого вызова.
+//
+//	conn, err := grpc.Dial(addr, grpc.WithPerRPCCredentials(auth.BearerCredentials{Token: tok}), ...)
+type BearerCredentials struct {
+	Token string
+	// AllowInsecure разрешает слать токен без TLS. Только для локальной разработки:
+	// токен в открытом виде можно перехватить.
+	AllowInsecure bool
+}
+
+func (c BearerCredentials) GetRequestMetadata(context.Context, ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + c.Token}, nil
+}
+
+func (c BearerCredentials) RequireTransportSecurity() bool {
+	return !c.AllowInsecure
+}
+
+// ParseBearer достаёт токен из значения заголовка "Bearer <token>" (схема без учёта регистра).
+// Общий разбор для gRPC-метаданных и HTTP-заголовка Authorization.
+func ParseBearer(header string) (string, bool) {
+	scheme, token, found := strings.Cut(header, " ")
+	token = strings.TrimSpace(token)
+	if !found || !strings.EqualFold(scheme, "bearer") || token == "" {
+		return "", false
+	}
+	return token, true
+}
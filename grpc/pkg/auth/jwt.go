@@ -0,0 +1,173 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+/*
+	Пакет auth — проверка bearer-токенов и «кто вызывает» в контексте запроса.
+
+	Токен — JWT (RFC 7519), подписанный HMAC-SHA256 (alg HS256) общим секретом.
+	Этого достаточно, когда токены выпускает тот же, кто их проверяет; для внешнего
+	провайдера (OIDC) понадобится асимметричная подпись и другой Verifier.
+
+	Проверяется: подпись, alg (только HS256 — "none" и прочие отвергаются),
+	exp (обязателен), nbf, а также iss/aud, если они заданы опциями.
+*/
+
+// Ошибки проверки токена. Всё это для клиента — Unauthenticated.
+var (
+	ErrInvalidToken = errors.New("invalid token")
+	ErrTokenExpired = errors.New("token expired")
+)
+
+// Claims — поля JWT, которые мы выпускаем и проверяем.
+type Claims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss,omitempty"`
+	Audience  Audience `json:"aud,omitempty"`
+	ExpiresAt int64    `json:"exp"`
+	NotBefore int64    `json:"nbf,omitempty"`
+	IssuedAt  int64    `json:"iat,omitempty"`
+}
+
+// Audience — "aud" по RFC 7519 бывает и строкой, и массивом строк.
+type Audience []string
+
+func (a *Audience) UnmarshalJSON(b []byte) error {
+	var one string
+	if err := json.Unmarshal(b, &one); err == nil {
+		*a = Audience{one}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(b, &many); err != nil {
+		return err
+	}
+	*a = many
+	return nil
+}
+
+type header struct {
+	Alg string `json:"alg"`
+	Typ string `json:"typ,omitempty"`
+}
+
+// Verifier проверяет токен и возвращает, чей он.
+type Verifier interface {
+	Verify(token string) (Principal, error)
+}
+
+// HMACVerifier проверяет JWT с подписью HS256.
+type HMACVerifier struct {
+	key      []byte
+	issuer   string
+	audience string
+	leeway   time.Duration
+	now      func() time.Time
+}
+
+// VerifierOption — функциональная опция HMACVerifier.
+type VerifierOption func(*HMACVerifier)
+
+// WithIssuer требует iss == issuer.
+func WithIssuer(issuer string) VerifierOption {
+	return func(v *HMACVerifier) { v.issuer = issuer }
+}
+
+// WithAudience требует, чтобы aud содержал audience.
+func WithAudience(audience string) VerifierOption {
+	return func(v *HMACVerifier) { v.audience = audience }
+}
+
+// WithLeeway — допуск на расхождение часов при проверке exp/nbf.
+func WithLeeway(d time.Duration) VerifierOption {
+	return func(v *HMACVerifier) { v.leeway = d }
+}
+
+func NewHMACVerifier(key []byte, opts ...VerifierOption) *HMACVerifier {
+	v := &HMACVerifier{key: key, leeway: 30 * time.Second, now: time.Now}
+	for _, o := range opts {
+		o(v)
+	}
+	return v
+}
+
+func (v *HMACVerifier) Verify(token string) (Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Principal{}, fmt.Errorf("%w: malformed", ErrInvalidToken)
+	}
+
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return Principal{}, fmt.Errorf("%w: header: %v", ErrInvalidToken, err)
+	}
+	if h.Alg != "HS256" {
+		return Principal{}, fmt.Errorf("%w: unsupported alg %q", ErrInvalidToken, h.Alg)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Principal{}, fmt.Errorf("%w: signature encoding", ErrInvalidToken)
+	}
+	if !hmac.Equal(sig, sign(v.key, parts[0]+"."+parts[1])) {
+		return Principal{}, fmt.Errorf("%w: bad signature", ErrInvalidToken)
+	}
+
+	var c Claims
+	if err := decodeSegment(parts[1], &c); err != nil {
+		return Principal{}, fmt.Errorf("%w: claims: %v", ErrInvalidToken, err)
+	}
+	now := v.now()
+	switch {
+	case c.Subject == "":
+		return Principal{}, fmt.Errorf("%w: sub is required", ErrInvalidToken)
+	case c.ExpiresAt == 0:
+		return Principal{}, fmt.Errorf("%w: exp is required", ErrInvalidToken)
+	case now.After(time.Unix(c.ExpiresAt, 0).Add(v.leeway)):
+		return Principal{}, ErrTokenExpired
+	case c.NotBefore != 0 && now.Add(v.leeway).Before(time.Unix(c.NotBefore, 0)):
+		return Principal{}, fmt.Errorf("%w: not valid yet", ErrInvalidToken)
+	case v.issuer != "" && c.Issuer != v.issuer:
+		return Principal{}, fmt.Errorf("%w: unexpected iss %q", ErrInvalidToken, c.Issuer)
+	case v.audience != "" && !slices.Contains(c.Audience, v.audience):
+		return Principal{}, fmt.Errorf("%w: audience mismatch", ErrInvalidToken)
+	}
+	return Principal{Subject: c.Subject}, nil
+}
+
+// SignHS256 выпускает токен с подписью HS256 (для утилит и демо-клиента).
+func SignHS256(key []byte, c Claims) (string, error) {
+	h, err := json.Marshal(header{Alg: "HS256", Typ: "JWT"})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	unsigned := base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(sign(key, unsigned)), nil
+}
+
+func sign(key []byte, data string) []byte {
+	m := hmac.New(sha256.New, key)
+	m.Write([]byte(data))
+	return m.Sum(nil)
+}
+
+func decodeSegment(seg string, v any) error {
+	b, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	testKey = []byte("test-secret")
+	now     = time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+)
+
+// rawToken собирает токен из готовых JSON заголовка и claims, подписывая HS256,
+// как бы ни назывался alg в заголовке, — так проверяется именно отказ по alg.
+func rawToken(header, claims string) string {
+	unsigned := b64(header) + "." + b64(claims)
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(sign(testKey, unsigned))
+}
+
+func b64(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+
+// claims — JSON claims с sub и exp через час; extra дописывается в объект.
+func claims(extra string) string {
+	c := fmt.Sprintf(`{"sub":"alice","exp":%d`, now.Add(time.Hour).Unix())
+	if extra != "" {
+		c += "," + extra
+	}
+	return c + "}"
+}
+
+const hs256 = `{"alg":"HS256","typ":"JWT"}`
+
+func TestVerify(t *testing.T) {
+	valid := rawToken(hs256, claims(""))
+	parts := strings.Split(valid, ".")
+
+	// Подпись HS512 тем же ключом: валидна сама по себе, но alg не тот.
+	hs512 := b64(`{"alg":"HS512","typ":"JWT"}`) + "." + parts[1]
+	m := hmac.New(sha512.New, testKey)
+	m.Write([]byte(hs512))
+	hs512 += "." + base64.RawURLEncoding.EncodeToString(m.Sum(nil))
+
+	tampered := []byte(parts[2])
+	tampered[3] ^= 1
+	otherKey, err := SignHS256([]byte("other-secret"), Claims{Subject: "alice", ExpiresAt: now.Add(time.Hour).Unix()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	at := func(d time.Duration) int64 { return now.Add(d).Unix() }
+	tests := []struct {
+		name  string
+		token string
+		opts  []VerifierOption
+		want  error // nil — токен принят
+	}{
+		{"valid", valid, nil, nil},
+		{"malformed", "a.b", nil, ErrInvalidToken},
+		{"alg none", b64(`{"alg":"none"}`) + "." + parts[1] + ".", nil, ErrInvalidToken},
+		{"alg none with signature", rawToken(`{"alg":"none"}`, claims("")), nil, ErrInvalidToken},
+		{"alg HS512", hs512, nil, ErrInvalidToken},
+		{"tampered payload", parts[0] + "." + b64(`{"sub":"mallory","exp":`+fmt.Sprint(at(time.Hour))+`}`) + "." + parts[2], nil, ErrInvalidToken},
+		{"tampered signature", parts[0] + "." + parts[1] + "." + string(tampered), nil, ErrInvalidToken},
+		{"signed with another key", otherKey, nil, ErrInvalidToken},
+		{"no sub", rawToken(hs256, fmt.Sprintf(`{"exp":%d}`, at(time.Hour))), nil, ErrInvalidToken},
+		{"no exp", rawToken(hs256, `{"sub":"alice"}`), nil, ErrInvalidToken},
+
+		// Допуск по умолчанию — 30 секунд в обе стороны.
+		{"exp inside leeway", rawToken(hs256, fmt.Sprintf(`{"sub":"alice","exp":%d}`, at(-30*time.Second))), nil, nil},
+		{"exp outside leeway", rawToken(hs256, fmt.Sprintf(`{"sub":"alice","exp":%d}`, at(-31*time.Second))), nil, ErrTokenExpired},
+		{"nbf inside leeway", rawToken(hs256, claims(fmt.Sprintf(`"nbf":%d`, at(30*time.Second)))), nil, nil},
+		{"nbf outside leeway", rawToken(hs256, claims(fmt.Sprintf(`"nbf":%d`, at(31*time.Second)))), nil, ErrInvalidToken},
+		{"custom leeway", rawToken(hs256, fmt.Sprintf(`{"sub":"alice","exp":%d}`, at(-30*time.Second))),
+			[]VerifierOption{WithLeeway(10 * time.Second)}, ErrTokenExpired},
+
+		{"iss matches", rawToken(hs256, claims(`"iss":"notes-auth"`)), []VerifierOption{WithIssuer("notes-auth")}, nil},
+		{"iss mismatch", rawToken(hs256, claims(`"iss":"evil"`)), []VerifierOption{WithIssuer("notes-auth")}, ErrInvalidToken},
+		{"iss missing", valid, []VerifierOption{WithIssuer("notes-auth")}, ErrInvalidToken},
+
+		{"aud string", rawToken(hs256, claims(`"aud":"notes"`)), []VerifierOption{WithAudience("notes")}, nil},
+		{"aud array", rawToken(hs256, claims(`"aud":["billing","notes"]`)), []VerifierOption{WithAudience("notes")}, nil},
+		{"aud string mismatch", rawToken(hs256, claims(`"aud":"billing"`)), []VerifierOption{WithAudience("notes")}, ErrInvalidToken},
+		{"aud array mismatch", rawToken(hs256, claims(`"aud":["billing"]`)), []VerifierOption{WithAudience("notes")}, ErrInvalidToken},
+		{"aud missing", valid, []VerifierOption{WithAudience("notes")}, ErrInvalidToken},
+		{"aud not required", rawToken(hs256, claims(`"aud":"billing"`)), nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewHMACVerifier(testKey, tt.opts...)
+			v.now = func() time.Time { return now }
+			p, err := v.Verify(tt.token)
+			if tt.want == nil {
+				if err != nil || p.Subject != "alice" {
+					t.Fatalf("Verify = %+v, %v; want alice", p, err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Verify: err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignHS256RoundTrip(t *testing.T) {
+	tok, err := SignHS256(testKey, Claims{Subject: "bob", Issuer: "notes-auth", Audience: Audience{"notes"},
+		ExpiresAt: now.Add(time.Minute).Unix(), IssuedAt: now.Unix()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := NewHMACVerifier(testKey, WithIssuer("notes-auth"), WithAudience("notes"))
+	v.now = func() time.Time { return now }
+	if p, err := v.Verify(tok); err != nil || p.Subject != "bob" {
+		t.Errorf("Verify = %+v, %v; want bob", p, err)
+	}
+}
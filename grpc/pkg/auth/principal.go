@@ -0,0 +1,22 @@
+package auth
+
+import "context"
+
+// Principal — аутентифицированный вызывающий.
+// Subject — стабильный идентификатор пользователя (claim "sub"), он же владелец заметок.
+type Principal struct {
+	Subject string
+}
+
+type principalKey struct{}
+
+// NewContext кладёт Principal в контекст (это делает перехватчик аутентификации).
+func NewContext(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext достаёт Principal; ok == false — запрос без аутентификации.
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
@@ -0,0 +1,156 @@
+package client
+
+import (
+	"crypto/tls"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
+
+	"github.com/verazalayli/go_studying/grpc/pkg/auth"
+	"github.com/verazalayli/go_studying/grpc/proto/pb"
+)
+
+/*
+	Пакет client — готовый к использованию клиент NoteService поверх сгенерированного стаба.
+
+	Что он добавляет к голому pb.NewNoteServiceClient:
+	  - повторы (retry) идемпотентных чтений — GetNote, ListNotes, SearchNotes — при UNAVAILABLE,
+	    с экспоненциальной задержкой и джиттером; политика задаётся service config'ом gRPC;
+	  - по желанию — hedging тех же чтений (WithHedging): копия запроса уходит, если первая
+	    не ответила за delay, побеждает первый успешный ответ;
+	  - дедлайн по умолчанию для каждого unary-вызова, если у контекста своего нет (WithTimeout);
+	  - keepalive-пинги, чтобы «мёртвое» соединение (NAT, балансировщик) замечалось
+	    до очередного вызова, а не по его таймауту.
+
+	Неидемпотентные вызовы (CreateNote, UpdateNote, DeleteNote, BulkCreateNotes) вслепую не
+	повторяются: ответ мог потеряться уже после того, как сервер создал заметку. gRPC сам
+	повторяет их только «прозрачно» — когда запрос гарантированно не дошёл до сервера.
+
+	  c, err := client.New("localhost:50051", client.WithToken(tok), client.WithTimeout(3*time.Second))
+	  defer c.Close()
+	  resp, err := c.GetNote(ctx, &pb.GetNoteRequest{Id: id})
+*/
+
+// Значения по умолчанию.
+const (
+	DefaultTimeout = 5 * time.Second
+)
+
+// DefaultKeepalive — пинг раз в 30 секунд простоя, соединение считается мёртвым,
+// если ответа нет 10 секунд. Сервер должен разрешать такую частоту
+// (keepalive.EnforcementPolicy.MinTime не больше Time), иначе закроет соединение с too_many_pings.
+var DefaultKeepalive = keepalive.ClientParameters{
+	Time:                30 * time.Second,
+	Timeout:             10 * time.Second,
+	PermitWithoutStream: true,
+}
+
+// Client — NoteServiceClient со встроенными политиками; все методы стаба доступны напрямую.
+type Client struct {
+	pb.NoteServiceClient
+	conn *grpc.ClientConn
+}
+
+type options struct {
+	tls       *tls.Config
+	token     string
+	timeout   time.Duration
+	retry     *RetryPolicy
+	hedging   *HedgingPolicy
+	keepalive keepalive.ClientParameters
+	dialOpts  []grpc.DialOption
+}
+
+// Option — функциональная опция New.
+type Option func(*options)
+
+// WithTLS включает TLS (см. tlsutil.ClientConfig); без неё соединение без шифрования.
+func WithTLS(cfg *tls.Config) Option {
+	return func(o *options) { o.tls = cfg }
+}
+
+// WithToken добавляет "authorization: Bearer <token>" к каждому вызову.
+// Без TLS токен уходит открытым текстом — это разрешено только для локальной разработки.
+func WithToken(token string) Option {
+	return func(o *options) { o.token = token }
+}
+
+// WithTimeout — дедлайн unary-вызова, если контекст пришёл без дедлайна; 0 — не ставить.
+// Потоки (WatchNotes, BulkCreateNotes) не ограничиваются: они живут сколько нужно.
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) { o.timeout = d }
+}
+
+// WithRetry заменяет политику повторов чтений; nil — не повторять.
+func WithRetry(p *RetryPolicy) Option {
+	return func(o *options) { o.retry = p }
+}
+
+// WithHedging включает hedging чтений вместо повторов (gRPC не совмещает их для одного метода).
+func WithHedging(p HedgingPolicy) Option {
+	return func(o *options) { o.hedging = &p }
+}
+
+// WithKeepalive заменяет DefaultKeepalive.
+func WithKeepalive(p keepalive.ClientParameters) Option {
+	return func(o *options) { o.keepalive = p }
+}
+
+// WithDialOptions — дополнительные опции grpc.NewClient (перехватчики, балансировщик и т.п.).
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return func(o *options) { o.dialOpts = append(o.dialOpts, opts...) }
+}
+
+// New создаёт клиент. Соединение поднимается лениво, при первом вызове.
+func New(target string, opts ...Option) (*Client, error) {
+	retry := DefaultRetryPolicy
+	o := options{
+		timeout:   DefaultTimeout,
+		retry:     &retry,
+		keepalive: DefaultKeepalive,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	creds := insecure.NewCredentials()
+	if o.tls != nil {
+		creds = credentials.NewTLS(o.tls)
+	}
+	sc, err := serviceConfig(o.retry, o.hedging != nil)
+	if err != nil {
+		return nil, err
+	}
+	dialOpts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithDefaultServiceConfig(sc),
+		grpc.WithKeepaliveParams(o.keepalive),
+	}
+	if o.token != "" {
+		dialOpts = append(dialOpts, grpc.WithPerRPCCredentials(auth.BearerCredentials{
+			Token:         o.token,
+			AllowInsecure: o.tls == nil,
+		}))
+	}
+	// Дедлайн — внешним: hedging-копии и повторы укладываются в один общий дедлайн вызова.
+	unary := []grpc.UnaryClientInterceptor{timeoutInterceptor(o.timeout)}
+	if o.hedging != nil {
+		unary = append(unary, hedgingInterceptor(*o.hedging))
+	}
+	dialOpts = append(dialOpts, grpc.WithChainUnaryInterceptor(unary...))
+	dialOpts = append(dialOpts, o.dialOpts...)
+
+	conn, err := grpc.NewClient(target, dialOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{NoteServiceClient: pb.NewNoteServiceClient(conn), conn: conn}, nil
+}
+
+// Close закрывает соединение.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
@@ -0,0 +1,82 @@
+package client
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// timeoutInterceptor ставит дедлайн d, если вызывающий не задал свой.
+func timeoutInterceptor(d time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if _, ok := ctx.Deadline(); !ok && d > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, d)
+			defer cancel()
+		}
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// hedgingInterceptor реализует HedgingPolicy для идемпотентных методов.
+// grpc-go читает hedgingPolicy из service config, но не исполняет его, поэтому — перехватчик.
+// Каждая копия пишет ответ в свой экземпляр сообщения; в reply копируется только победитель.
+func hedgingInterceptor(p HedgingPolicy) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		out, ok := reply.(proto.Message)
+		if !ok || !isIdempotent(method) || p.MaxAttempts < 2 {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		// Отмена по выходу гасит копии, которые ещё в полёте.
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		type result struct {
+			reply proto.Message
+			err   error
+		}
+		results := make(chan result, p.MaxAttempts)
+		launched := 0
+		launch := func() {
+			launched++
+			r := out.ProtoReflect().New().Interface()
+			go func() {
+				results <- result{reply: r, err: invoker(ctx, method, req, r, cc, opts...)}
+			}()
+		}
+
+		launch()
+		timer := time.NewTimer(p.Delay)
+		defer timer.Stop()
+		var lastErr error
+		for received := 0; received < launched; {
+			select {
+			case <-timer.C:
+				if launched < p.MaxAttempts {
+					launch()
+					timer.Reset(p.Delay)
+				}
+			case r := <-results:
+				received++
+				if r.err == nil {
+					proto.Reset(out)
+					proto.Merge(out, r.reply)
+					return nil
+				}
+				if !slices.Contains(p.NonFatalCodes, status.Code(r.err)) {
+					return r.err
+				}
+				lastErr = r.err
+				if launched < p.MaxAttempts {
+					launch()
+					timer.Reset(p.Delay)
+				}
+			}
+		}
+		return lastErr
+	}
+}
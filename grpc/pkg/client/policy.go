@@ -0,0 +1,175 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+
+	"github.com/verazalayli/go_studying/grpc/proto/pb"
+)
+
+// idempotentMethods — вызовы, которые безопасно отправить повторно: они только читают.
+var idempotentMethods = []string{
+	pb.NoteService_GetNote_FullMethodName,
+	pb.NoteService_ListNotes_FullMethodName,
+	pb.NoteService_ListTrash_FullMethodName,
+	pb.NoteService_ListTags_FullMethodName,
+	pb.NoteService_ListNoteRevisions_FullMethodName,
+	pb.NoteService_GetNoteRevision_FullMethodName,
+	pb.NoteService_DiffNoteRevisions_FullMethodName,
+	pb.NoteService_SearchNotes_FullMethodName,
+	pb.NoteService_RenderNote_FullMethodName,
+	// Потоки: gRPC повторяет их, только пока клиент не получил ни одного сообщения.
+	pb.NoteService_DownloadAttachment_FullMethodName,
+	pb.NoteService_ExportNotes_FullMethodName,
+}
+
+func isIdempotent(method string) bool {
+	for _, m := range idempotentMethods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// RetryPolicy — retryPolicy из service config gRPC (gRFC A6).
+// Задержка перед попыткой n — случайная в [0, min(InitialBackoff*BackoffMultiplier^(n-1), MaxBackoff)]:
+// экспонента с полным джиттером, чтобы клиенты после сбоя не приходили все разом.
+type RetryPolicy struct {
+	MaxAttempts       int // всего попыток, включая первую; gRPC ограничивает пятью
+	InitialBackoff    time.Duration
+	MaxBackoff        time.Duration
+	BackoffMultiplier float64
+	RetryableCodes    []codes.Code
+}
+
+// DefaultRetryPolicy — до 4 попыток при UNAVAILABLE (сервер перезапускается, соединение порвалось).
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:       4,
+	InitialBackoff:    100 * time.Millisecond,
+	MaxBackoff:        2 * time.Second,
+	BackoffMultiplier: 2,
+	RetryableCodes:    []codes.Code{codes.Unavailable},
+}
+
+// HedgingPolicy — hedging чтений: если ответа нет за Delay, отправляется ещё одна копия
+// (всего не больше MaxAttempts), первый успешный ответ побеждает, остальные отменяются.
+// Ошибка с кодом из NonFatalCodes не прерывает вызов, а сразу запускает следующую копию.
+type HedgingPolicy struct {
+	MaxAttempts   int
+	Delay         time.Duration
+	NonFatalCodes []codes.Code
+}
+
+// DefaultHedgingPolicy — вторая копия через 100 мс, третья ещё через 100 мс.
+var DefaultHedgingPolicy = HedgingPolicy{
+	MaxAttempts:   3,
+	Delay:         100 * time.Millisecond,
+	NonFatalCodes: []codes.Code{codes.Unavailable},
+}
+
+// serviceConfig собирает JSON service config: retryPolicy для идемпотентных методов
+// и retryThrottling, который отключает повторы, когда сервер в основном отвечает ошибками.
+// hedged — чтения хеджирует перехватчик, retryPolicy для них не нужен.
+func serviceConfig(retry *RetryPolicy, hedged bool) (string, error) {
+	type name struct {
+		Service string `json:"service"`
+		Method  string `json:"method"`
+	}
+	type retryPolicy struct {
+		MaxAttempts          int      `json:"maxAttempts"`
+		InitialBackoff       string   `json:"initialBackoff"`
+		MaxBackoff           string   `json:"maxBackoff"`
+		BackoffMultiplier    float64  `json:"backoffMultiplier"`
+		RetryableStatusCodes []string `json:"retryableStatusCodes"`
+	}
+	type methodConfig struct {
+		Name        []name       `json:"name"`
+		RetryPolicy *retryPolicy `json:"retryPolicy,omitempty"`
+	}
+	type throttling struct {
+		MaxTokens  float64 `json:"maxTokens"`
+		TokenRatio float64 `json:"tokenRatio"`
+	}
+	var sc struct {
+		MethodConfig    []methodConfig `json:"methodConfig,omitempty"`
+		RetryThrottling *throttling    `json:"retryThrottling,omitempty"`
+	}
+
+	if retry != nil && !hedged {
+		if retry.MaxAttempts < 2 {
+			return "", fmt.Errorf("client: retry MaxAttempts must be >= 2, got %d", retry.MaxAttempts)
+		}
+		rp := &retryPolicy{
+			MaxAttempts:       retry.MaxAttempts,
+			InitialBackoff:    durationJSON(retry.InitialBackoff),
+			MaxBackoff:        durationJSON(retry.MaxBackoff),
+			BackoffMultiplier: retry.BackoffMultiplier,
+		}
+		for _, c := range retry.RetryableCodes {
+			name, err := codeJSON(c)
+			if err != nil {
+				return "", err
+			}
+			rp.RetryableStatusCodes = append(rp.RetryableStatusCodes, name)
+		}
+		mc := methodConfig{RetryPolicy: rp}
+		for _, m := range idempotentMethods {
+			svc, method := splitMethod(m)
+			mc.Name = append(mc.Name, name{Service: svc, Method: method})
+		}
+		sc.MethodConfig = append(sc.MethodConfig, mc)
+		// Токенов 10, успешный вызов возвращает 0.1, ошибка забирает 1: при сплошных сбоях
+		// повторы прекращаются, пока доля успехов не восстановится.
+		sc.RetryThrottling = &throttling{MaxTokens: 10, TokenRatio: 0.1}
+	}
+	b, err := json.Marshal(sc)
+	return string(b), err
+}
+
+// durationJSON — длительность в формате google.protobuf.Duration ("0.100s").
+func durationJSON(d time.Duration) string {
+	return fmt.Sprintf("%.3fs", d.Seconds())
+}
+
+// codeNames — канонические имена кодов, которые принимает service config
+// (в том числе "CANCELLED" с двумя L, хотя константа — codes.Canceled).
+var codeNames = map[codes.Code]string{
+	codes.OK:                 "OK",
+	codes.Canceled:           "CANCELLED",
+	codes.Unknown:            "UNKNOWN",
+	codes.InvalidArgument:    "INVALID_ARGUMENT",
+	codes.DeadlineExceeded:   "DEADLINE_EXCEEDED",
+	codes.NotFound:           "NOT_FOUND",
+	codes.AlreadyExists:      "ALREADY_EXISTS",
+	codes.PermissionDenied:   "PERMISSION_DENIED",
+	codes.ResourceExhausted:  "RESOURCE_EXHAUSTED",
+	codes.FailedPrecondition: "FAILED_PRECONDITION",
+	codes.Aborted:            "ABORTED",
+	codes.OutOfRange:         "OUT_OF_RANGE",
+	codes.Unimplemented:      "UNIMPLEMENTED",
+	codes.Internal:           "INTERNAL",
+	codes.Unavailable:        "UNAVAILABLE",
+	codes.DataLoss:           "DATA_LOSS",
+	codes.Unauthenticated:    "UNAUTHENTICATED",
+}
+
+// codeJSON — имя кода в service config: "UNAVAILABLE", "DEADLINE_EXCEEDED".
+func codeJSON(c codes.Code) (string, error) {
+	name, ok := codeNames[c]
+	if !ok {
+		return "", fmt.Errorf("client: unknown status code %d in RetryableCodes", uint32(c))
+	}
+	return name, nil
+}
+
+// splitMethod разбирает "/note.v1.NoteService/GetNote" на сервис и метод.
+func splitMethod(full string) (service, method string) {
+	full = strings.TrimPrefix(full, "/")
+	i := strings.LastIndex(full, "/")
+	return full[:i], full[i+1:]
+}
@@ -0,0 +1,41 @@
+package client
+
+import (
+	"encoding/json"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+)
+
+func TestCodeJSON(t *testing.T) {
+	// Имя должно разбираться обратно в тот же код так же, как его разбирает gRPC
+	// при чтении service config.
+	for c := codes.OK; c <= codes.Unauthenticated; c++ {
+		name, err := codeJSON(c)
+		if err != nil {
+			t.Fatalf("%v: %v", c, err)
+		}
+		var back codes.Code
+		if err := json.Unmarshal([]byte(`"`+name+`"`), &back); err != nil || back != c {
+			t.Errorf("%v -> %q -> %v, %v", c, name, back, err)
+		}
+	}
+	if _, err := codeJSON(codes.Code(42)); err == nil {
+		t.Error("unknown code 42 accepted")
+	}
+}
+
+func TestNewWithRetryableCodes(t *testing.T) {
+	retry := DefaultRetryPolicy
+	retry.RetryableCodes = []codes.Code{codes.Unavailable, codes.Canceled, codes.DeadlineExceeded, codes.ResourceExhausted}
+	c, err := New("localhost:50051", WithRetry(&retry))
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	c.Close()
+
+	retry.RetryableCodes = []codes.Code{codes.Code(42)}
+	if _, err := New("localhost:50051", WithRetry(&retry)); err == nil {
+		t.Error("New accepted an unknown retryable code")
+	}
+}
@@ -0,0 +1,240 @@
+// Package diff — построчное сравнение текстов и вывод в формате unified diff (как diff -u).
+//
+// Сравнение — алгоритм Майерса (кратчайший скрипт правок) после отсечения общего
+// начала и конца. Память на трассу растёт как квадрат числа правок, поэтому тексты,
+// различающиеся больше чем на MaxEdits строк, сравниваются грубо: весь старый текст
+// удалён, весь новый добавлен. Такой diff корректен, хоть и не минимален.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultContext — сколько строк контекста показывать вокруг изменений.
+const DefaultContext = 3
+
+// MaxEdits — предел числа правок для поиска минимального скрипта.
+const MaxEdits = 2000
+
+type opKind int8
+
+const (
+	opEq opKind = iota
+	opDel
+	opIns
+)
+
+// edit — одна строка скрипта: a и b — позиции в старом и новом тексте
+// (для вставки a — сколько строк старого уже пройдено, для удаления b — нового).
+type edit struct {
+	kind opKind
+	a, b int
+}
+
+// Unified возвращает diff от a к b с заголовками fromName/toName и context строками
+// контекста. Одинаковые тексты — пустая строка.
+func Unified(fromName, toName, a, b string, context int) string {
+	if a == b {
+		return ""
+	}
+	al, bl := splitLines(a), splitLines(b)
+	script := lineScript(al, bl)
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", fromName, toName)
+	for _, h := range hunks(script, context) {
+		as, ac, bs, bc := h.ranges(script)
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n", rangeString(as, ac), rangeString(bs, bc))
+		for _, e := range script[h.start:h.end] {
+			switch e.kind {
+			case opEq:
+				writeLine(&sb, ' ', al[e.a])
+			case opDel:
+				writeLine(&sb, '-', al[e.a])
+			case opIns:
+				writeLine(&sb, '+', bl[e.b])
+			}
+		}
+	}
+	return sb.String()
+}
+
+// splitLines режет текст на строки вместе с "\n": так "x" и "x\n" различаются,
+// как и положено для последней строки без перевода строки.
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+func writeLine(sb *strings.Builder, prefix byte, line string) {
+	sb.WriteByte(prefix)
+	sb.WriteString(line)
+	if !strings.HasSuffix(line, "\n") {
+		sb.WriteString("\n\\ No newline at end of file\n")
+	}
+}
+
+// rangeString — диапазон строк в заголовке ханка: "start,count", для одной строки
+// просто "start", для пустого диапазона — номер строки перед ним и ",0".
+func rangeString(start, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
+
+// hunk — отрезок скрипта [start, end) вместе с контекстом.
+type hunk struct{ start, end int }
+
+// hunks группирует правки: если между двумя изменениями не больше 2*context
+// общих строк, они попадают в один ханк.
+func hunks(script []edit, context int) []hunk {
+	var out []hunk
+	for i := 0; i < len(script); {
+		if script[i].kind == opEq {
+			i++
+			continue
+		}
+		h := hunk{start: max(i-context, 0)}
+		last := i // последняя правка ханка
+		for j := i + 1; j < len(script) && j-last <= 2*context+1; j++ {
+			if script[j].kind != opEq {
+				last = j
+			}
+		}
+		h.end = min(last+context+1, len(script))
+		out = append(out, h)
+		i = last + 1
+	}
+	return out
+}
+
+// ranges — начало и число строк ханка в старом и новом тексте.
+func (h hunk) ranges(script []edit) (aStart, aCount, bStart, bCount int) {
+	first := script[h.start]
+	aStart, bStart = first.a, first.b
+	for _, e := range script[h.start:h.end] {
+		if e.kind != opIns {
+			aCount++
+		}
+		if e.kind != opDel {
+			bCount++
+		}
+	}
+	return aStart, aCount, bStart, bCount
+}
+
+// lineScript — скрипт правок от a к b: общее начало и конец как есть, середина — Майерс.
+func lineScript(a, b []string) []edit {
+	pre := 0
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		pre++
+	}
+	suf := 0
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		suf++
+	}
+	out := make([]edit, 0, len(a)+len(b))
+	for i := 0; i < pre; i++ {
+		out = append(out, edit{opEq, i, i})
+	}
+	for _, e := range myers(a[pre:len(a)-suf], b[pre:len(b)-suf]) {
+		e.a += pre
+		e.b += pre
+		out = append(out, e)
+	}
+	for i := 0; i < suf; i++ {
+		out = append(out, edit{opEq, len(a) - suf + i, len(b) - suf + i})
+	}
+	return out
+}
+
+// myers ищет кратчайший скрипт правок (E. Myers, "An O(ND) Difference Algorithm").
+// trace[d] — граница x на диагоналях -d-1..d+1 перед шагом d; по ней скрипт
+// восстанавливается с конца.
+func myers(a, b []string) []edit {
+	n, m := len(a), len(b)
+	if n == 0 || m == 0 {
+		return replaceAll(n, m)
+	}
+	limit := min(n+m, MaxEdits)
+	offset := n + m + 1
+	v := make([]int, 2*offset+1)
+	var trace [][]int
+	for d := 0; d <= limit; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1] // шаг вниз: вставка
+			} else {
+				x = v[offset+k-1] + 1 // шаг вправо: удаление
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(trace, n, m)
+			}
+		}
+	}
+	return replaceAll(n, m)
+}
+
+func backtrack(trace [][]int, n, m int) []edit {
+	x, y := n, m
+	var rev []edit
+	for d := len(trace) - 1; d >= 0; d-- {
+		prev := trace[d]
+		at := func(k int) int { return prev[k+d+1] }
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			rev = append(rev, edit{opEq, x, y})
+		}
+		if d == 0 {
+			break
+		}
+		if x == prevX {
+			y--
+			rev = append(rev, edit{opIns, x, y})
+		} else {
+			x--
+			rev = append(rev, edit{opDel, x, y})
+		}
+	}
+	out := make([]edit, len(rev))
+	for i, e := range rev {
+		out[len(rev)-1-i] = e
+	}
+	return out
+}
+
+// replaceAll — грубый скрипт: удалить всё старое, вставить всё новое.
+func replaceAll(n, m int) []edit {
+	out := make([]edit, 0, n+m)
+	for i := 0; i < n; i++ {
+		out = append(out, edit{opDel, i, 0})
+	}
+	for j := 0; j < m; j++ {
+		out = append(out, edit{opIns, n, j})
+	}
+	return out
+}
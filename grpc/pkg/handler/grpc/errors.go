@@ -0,0 +1,89 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+
+	"github.com/verazalayli/go_studying/grpc/pkg/service"
+)
+
+// errorDomain — домен в ErrorInfo: по нему клиент понимает, чьи это Reason.
+const errorDomain = "notes.go_studying"
+
+// ToStatus — единственное место, где ошибки сервиса превращаются в gRPC-статусы
+// (REST-шлюз тоже берёт код и детали отсюда, чтобы транспорты не расходились):
+//
+//	*service.Error           -> код по Kind + детали BadRequest.FieldViolations
+//	                            (если указано поле) и ErrorInfo{Reason, Domain, Metadata};
+//	context.DeadlineExceeded -> DeadlineExceeded;
+//	context.Canceled         -> Canceled;
+//	всё остальное            -> Internal.
+//
+// op — имя операции для текста ошибки ("create", "list", ...).
+func ToStatus(op string, err error) error {
+	if e, ok := service.AsError(err); ok {
+		return domainStatus(e)
+	}
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Errorf(codes.DeadlineExceeded, "%s: %v", op, err)
+	case errors.Is(err, context.Canceled):
+		return status.Errorf(codes.Canceled, "%s: %v", op, err)
+	default:
+		return status.Errorf(codes.Internal, "%s failed: %v", op, err)
+	}
+}
+
+func domainStatus(e *service.Error) error {
+	st := status.New(kindCode(e.Kind), e.Error())
+
+	var details []protoadapt.MessageV1
+	if e.Field != "" {
+		details = append(details, &errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{{
+				Field:       e.Field,
+				Description: e.Message,
+			}},
+		})
+	}
+	if e.Reason != "" {
+		details = append(details, &errdetails.ErrorInfo{
+			Reason:   e.Reason,
+			Domain:   errorDomain,
+			Metadata: e.Metadata,
+		})
+	}
+	if len(details) == 0 {
+		return st.Err()
+	}
+	withDetails, err := st.WithDetails(details...)
+	if err != nil {
+		// Детали не сериализовались — отдаём статус хотя бы с правильным кодом.
+		return st.Err()
+	}
+	return withDetails.Err()
+}
+
+func kindCode(k service.ErrorKind) codes.Code {
+	switch k {
+	case service.KindInvalidArgument:
+		return codes.InvalidArgument
+	case service.KindNotFound:
+		return codes.NotFound
+	case service.KindOutOfRange:
+		return codes.OutOfRange
+	case service.KindPermissionDenied:
+		return codes.PermissionDenied
+	case service.KindFailedPrecondition:
+		return codes.FailedPrecondition
+	case service.KindAborted:
+		return codes.Aborted
+	default:
+		return codes.Unknown
+	}
+}
@@ -2,12 +2,17 @@ package grpc
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
 	"time"
 
 	gogrpc "google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"github.com/verazalayli/go_studying/grpc/pkg/archive"
 	"github.com/verazalayli/go_studying/grpc/pkg/service"
 	"github.com/verazalayli/go_studying/grpc/proto/pb"
 )
@@ -27,47 +32,660 @@ func Register(grpcServer *gogrpc.Server, h *NoteHandler) {
 }
 
 func (h *NoteHandler) CreateNote(ctx context.Context, req *pb.CreateNoteRequest) (*pb.CreateNoteResponse, error) {
-	n, err := h.svc.Create(ctx, req.GetTitle(), req.GetContent())
+	in := service.NoteInput{Title: req.GetTitle(), Content: req.GetContent(), Tags: req.GetTags()}
+	n, err := h.svc.Create(ctx, in, req.GetIdempotencyKey())
 	if err != nil {
-		switch err {
-		case service.ErrBadRequest:
-			return nil, status.Error(codes.InvalidArgument, "title is required")
-		default:
-			return nil, status.Errorf(codes.Internal, "create failed: %v", err)
-		}
+		return nil, ToStatus("create", err)
 	}
-	return &pb.CreateNoteResponse{Note: toPB(n)}, nil
+	return &pb.CreateNoteResponse{Note: NoteToPB(n)}, nil
 }
 
 func (h *NoteHandler) GetNote(ctx context.Context, req *pb.GetNoteRequest) (*pb.GetNoteResponse, error) {
 	n, err := h.svc.Get(ctx, req.GetId())
 	if err != nil {
-		if err == service.ErrNotFound {
-			return nil, status.Error(codes.NotFound, "note not found")
+		return nil, ToStatus("get", err)
+	}
+	return &pb.GetNoteResponse{Note: NoteToPB(n)}, nil
+}
+
+func (h *NoteHandler) ListNotes(ctx context.Context, req *pb.ListNotesRequest) (*pb.ListNotesResponse, error) {
+	match, err := TagMatchFromPB(req.GetTagMatch())
+	if err != nil {
+		return nil, ToStatus("list", err)
+	}
+	page, err := h.svc.List(ctx, service.ListOptions{
+		PageSize:    int(req.GetPageSize()),
+		PageToken:   req.GetPageToken(),
+		OrderBy:     req.GetOrderBy(),
+		TitlePrefix: req.GetTitlePrefix(),
+		Tags:        req.GetTags(),
+		TagMatch:    match,
+	})
+	if err != nil {
+		return nil, ToStatus("list", err)
+	}
+	return &pb.ListNotesResponse{Notes: NotesToPB(page.Notes), NextPageToken: page.NextPageToken}, nil
+}
+
+func (h *NoteHandler) UpdateNote(ctx context.Context, req *pb.UpdateNoteRequest) (*pb.UpdateNoteResponse, error) {
+	upd, err := UpdateFromPB(req)
+	if err != nil {
+		return nil, ToStatus("update", err)
+	}
+	n, err := h.svc.Update(ctx, req.GetId(), upd)
+	if err != nil {
+		return nil, ToStatus("update", err)
+	}
+	return &pb.UpdateNoteResponse{Note: NoteToPB(n)}, nil
+}
+
+func (h *NoteHandler) DeleteNote(ctx context.Context, req *pb.DeleteNoteRequest) (*pb.DeleteNoteResponse, error) {
+	if err := h.svc.Delete(ctx, req.GetId(), req.GetExpectedVersion()); err != nil {
+		return nil, ToStatus("delete", err)
+	}
+	return &pb.DeleteNoteResponse{}, nil
+}
+
+func (h *NoteHandler) ListTrash(ctx context.Context, req *pb.ListTrashRequest) (*pb.ListTrashResponse, error) {
+	page, err := h.svc.ListTrash(ctx, service.ListOptions{
+		PageSize:    int(req.GetPageSize()),
+		PageToken:   req.GetPageToken(),
+		OrderBy:     req.GetOrderBy(),
+		TitlePrefix: req.GetTitlePrefix(),
+	})
+	if err != nil {
+		return nil, ToStatus("list trash", err)
+	}
+	return &pb.ListTrashResponse{Notes: NotesToPB(page.Notes), NextPageToken: page.NextPageToken}, nil
+}
+
+func (h *NoteHandler) RestoreNote(ctx context.Context, req *pb.RestoreNoteRequest) (*pb.RestoreNoteResponse, error) {
+	n, err := h.svc.Restore(ctx, req.GetId(), req.GetExpectedVersion())
+	if err != nil {
+		return nil, ToStatus("restore", err)
+	}
+	return &pb.RestoreNoteResponse{Note: NoteToPB(n)}, nil
+}
+
+func (h *NoteHandler) PurgeNote(ctx context.Context, req *pb.PurgeNoteRequest) (*pb.PurgeNoteResponse, error) {
+	if err := h.svc.Purge(ctx, req.GetId(), req.GetExpectedVersion()); err != nil {
+		return nil, ToStatus("purge", err)
+	}
+	return &pb.PurgeNoteResponse{}, nil
+}
+
+func (h *NoteHandler) AddTags(ctx context.Context, req *pb.AddTagsRequest) (*pb.AddTagsResponse, error) {
+	n, err := h.svc.AddTags(ctx, req.GetId(), req.GetTags(), req.GetExpectedVersion())
+	if err != nil {
+		return nil, ToStatus("add tags", err)
+	}
+	return &pb.AddTagsResponse{Note: NoteToPB(n)}, nil
+}
+
+func (h *NoteHandler) RemoveTags(ctx context.Context, req *pb.RemoveTagsRequest) (*pb.RemoveTagsResponse, error) {
+	n, err := h.svc.RemoveTags(ctx, req.GetId(), req.GetTags(), req.GetExpectedVersion())
+	if err != nil {
+		return nil, ToStatus("remove tags", err)
+	}
+	return &pb.RemoveTagsResponse{Note: NoteToPB(n)}, nil
+}
+
+func (h *NoteHandler) ListTags(ctx context.Context, _ *pb.ListTagsRequest) (*pb.ListTagsResponse, error) {
+	tags, err := h.svc.ListTags(ctx)
+	if err != nil {
+		return nil, ToStatus("list tags", err)
+	}
+	return &pb.ListTagsResponse{Tags: TagCountsToPB(tags)}, nil
+}
+
+func (h *NoteHandler) ListNoteRevisions(ctx context.Context, req *pb.ListNoteRevisionsRequest) (*pb.ListNoteRevisionsResponse, error) {
+	page, err := h.svc.ListRevisions(ctx, req.GetId(), service.RevisionListOptions{
+		PageSize:  int(req.GetPageSize()),
+		PageToken: req.GetPageToken(),
+	})
+	if err != nil {
+		return nil, ToStatus("list revisions", err)
+	}
+	return &pb.ListNoteRevisionsResponse{Revisions: NotesToPB(page.Revisions), NextPageToken: page.NextPageToken}, nil
+}
+
+func (h *NoteHandler) GetNoteRevision(ctx context.Context, req *pb.GetNoteRevisionRequest) (*pb.GetNoteRevisionResponse, error) {
+	n, err := h.svc.GetRevision(ctx, req.GetId(), req.GetVersion())
+	if err != nil {
+		return nil, ToStatus("get revision", err)
+	}
+	return &pb.GetNoteRevisionResponse{Revision: NoteToPB(n)}, nil
+}
+
+func (h *NoteHandler) DiffNoteRevisions(ctx context.Context, req *pb.DiffNoteRevisionsRequest) (*pb.DiffNoteRevisionsResponse, error) {
+	d, err := h.svc.DiffRevisions(ctx, req.GetId(), req.GetFromVersion(), req.GetToVersion())
+	if err != nil {
+		return nil, ToStatus("diff revisions", err)
+	}
+	return DiffToPB(d), nil
+}
+
+func (h *NoteHandler) RevertNote(ctx context.Context, req *pb.RevertNoteRequest) (*pb.RevertNoteResponse, error) {
+	n, err := h.svc.Revert(ctx, req.GetId(), req.GetVersion(), req.GetExpectedVersion())
+	if err != nil {
+		return nil, ToStatus("revert", err)
+	}
+	return &pb.RevertNoteResponse{Note: NoteToPB(n)}, nil
+}
+
+func (h *NoteHandler) SearchNotes(ctx context.Context, req *pb.SearchNotesRequest) (*pb.SearchNotesResponse, error) {
+	results, err := h.svc.Search(ctx, req.GetQuery(), int(req.GetLimit()))
+	if err != nil {
+		return nil, ToStatus("search", err)
+	}
+	out := make([]*pb.SearchHit, 0, len(results))
+	for _, r := range results {
+		out = append(out, &pb.SearchHit{Note: NoteToPB(r.Note), Score: r.Score, Snippets: r.Snippets})
+	}
+	return &pb.SearchNotesResponse{Hits: out}, nil
+}
+
+func (h *NoteHandler) RenderNote(ctx context.Context, req *pb.RenderNoteRequest) (*pb.RenderNoteResponse, error) {
+	r, err := h.svc.Render(ctx, req.GetId(), req.GetVersion())
+	if err != nil {
+		return nil, ToStatus("render", err)
+	}
+	return RenderedToPB(r), nil
+}
+
+// UploadAttachment: первое сообщение потока — metadata, остальные — куски данных.
+// Данные читаются по мере того, как сервис пишет их в хранилище, целиком в памяти
+// файл не держится.
+func (h *NoteHandler) UploadAttachment(stream pb.NoteService_UploadAttachmentServer) error {
+	first, err := stream.Recv()
+	if err != nil && err != io.EOF {
+		return err
+	}
+	meta := first.GetMetadata()
+	if meta == nil {
+		return ToStatus("upload attachment", service.InvalidArgument("metadata", "METADATA_REQUIRED",
+			"the first message must carry metadata"))
+	}
+	in := service.AttachmentInput{
+		Name:            meta.GetName(),
+		ContentType:     meta.GetContentType(),
+		SHA256:          meta.GetSha256(),
+		ExpectedVersion: meta.GetExpectedVersion(),
+	}
+	n, a, err := h.svc.UploadAttachment(stream.Context(), meta.GetNoteId(), in, &chunkReader{stream: stream})
+	if err != nil {
+		var se streamError
+		if errors.As(err, &se) {
+			return se.err
+		}
+		return ToStatus("upload attachment", err)
+	}
+	return stream.SendAndClose(&pb.UploadAttachmentResponse{Note: NoteToPB(n), Attachment: AttachmentToPB(a)})
+}
+
+// chunkReader — данные потока UploadAttachment как io.Reader.
+type chunkReader struct {
+	stream pb.NoteService_UploadAttachmentServer
+	buf    []byte
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		req, err := r.stream.Recv()
+		if err == io.EOF {
+			return 0, io.EOF
+		}
+		if err != nil {
+			return 0, streamError{err}
+		}
+		if req.GetMetadata() != nil {
+			return 0, service.InvalidArgument("metadata", "DUPLICATE_METADATA", "metadata must be sent only in the first message")
+		}
+		r.buf = req.GetChunk()
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// streamError — ошибка самого потока (клиент отключился, отменил вызов).
+// Это уже gRPC-статус, его возвращаем как есть, а не как сбой сервиса.
+type streamError struct{ err error }
+
+func (e streamError) Error() string { return e.err.Error() }
+func (e streamError) Unwrap() error { return e.err }
+
+// downloadChunkSize — размер куска данных в потоке DownloadAttachment.
+const downloadChunkSize = 64 << 10
+
+// DownloadAttachment отправляет метаданные вложения, затем данные кусками по downloadChunkSize.
+// Если содержимое на диске испорчено, поток закончится ошибкой после последнего куска.
+func (h *NoteHandler) DownloadAttachment(req *pb.DownloadAttachmentRequest, stream pb.NoteService_DownloadAttachmentServer) error {
+	a, rc, err := h.svc.OpenAttachment(stream.Context(), req.GetNoteId(), req.GetAttachmentId())
+	if err != nil {
+		return ToStatus("download attachment", err)
+	}
+	defer rc.Close()
+	if err := stream.Send(&pb.DownloadAttachmentResponse{
+		Data: &pb.DownloadAttachmentResponse_Attachment{Attachment: AttachmentToPB(a)},
+	}); err != nil {
+		return err
+	}
+	buf := make([]byte, downloadChunkSize)
+	for {
+		n, err := io.ReadFull(rc, buf)
+		if n > 0 {
+			// Send сериализует сообщение сразу, поэтому buf можно переиспользовать.
+			if err := stream.Send(&pb.DownloadAttachmentResponse{
+				Data: &pb.DownloadAttachmentResponse_Chunk{Chunk: buf[:n]},
+			}); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil
+		}
+		if err != nil {
+			return ToStatus("download attachment", err)
+		}
+	}
+}
+
+// WatchNotes держит поток открытым, пока клиент не отключится.
+// Медленный клиент отключается с ResourceExhausted: он может переподключиться
+// с from_seq = последний полученный seq + 1.
+func (h *NoteHandler) WatchNotes(req *pb.WatchNotesRequest, stream pb.NoteService_WatchNotesServer) error {
+	ctx := stream.Context()
+	sub, err := h.svc.Watch(ctx, req.GetFromSeq())
+	if err != nil {
+		return ToStatus("watch", err)
+	}
+	defer sub.Close()
+
+	var last uint64
+	for ev := range sub.Events() {
+		if err := stream.Send(eventToPB(ev)); err != nil {
+			return err
+		}
+		last = ev.Seq
+	}
+	if ctx.Err() != nil {
+		return status.FromContextError(ctx.Err()).Err()
+	}
+	if errors.Is(sub.Err(), service.ErrSlowConsumer) {
+		return status.Errorf(codes.ResourceExhausted, "%v; resume with from_seq=%d", sub.Err(), last+1)
+	}
+	return nil
+}
+
+// ExportNotes отправляет заметки по одной, последним сообщением — manifest (pkg/archive):
+// по нему клиент видит, что поток не оборвался, и сверяет полученное.
+func (h *NoteHandler) ExportNotes(req *pb.ExportNotesRequest, stream pb.NoteService_ExportNotesServer) error {
+	sum := archive.NewChecksum()
+	opts := service.ExportOptions{SkipTrash: req.GetSkipTrash(), SkipHistory: req.GetSkipHistory()}
+	err := h.svc.Export(stream.Context(), opts, func(a service.ArchivedNote) error {
+		n := ArchivedToPB(a)
+		if err := sum.Add(n); err != nil {
+			return err
+		}
+		if err := stream.Send(&pb.ExportNotesResponse{Data: &pb.ExportNotesResponse_Note{Note: n}}); err != nil {
+			return streamError{err}
+		}
+		return nil
+	})
+	if err != nil {
+		var se streamError
+		if errors.As(err, &se) {
+			return se.err
 		}
-		return nil, status.Errorf(codes.Internal, "get failed: %v", err)
+		return ToStatus("export", err)
 	}
-	return &pb.GetNoteResponse{Note: toPB(n)}, nil
+	return stream.Send(&pb.ExportNotesResponse{Data: &pb.ExportNotesResponse_Manifest{Manifest: sum.Manifest()}})
 }
 
-func (h *NoteHandler) ListNotes(ctx context.Context, _ *pb.ListNotesRequest) (*pb.ListNotesResponse, error) {
-	list, err := h.svc.List(ctx)
+// ImportNotes: options — необязательное первое сообщение, дальше заметки.
+// Сервис записывает заметки по мере чтения потока, целиком он в памяти не держится.
+func (h *NoteHandler) ImportNotes(stream pb.NoteService_ImportNotesServer) error {
+	first, err := stream.Recv()
+	if err != nil && err != io.EOF {
+		return err
+	}
+	mode := service.ImportMerge
+	pending := first.GetNote() // первая заметка, если options не прислали
+	if opts := first.GetOptions(); opts != nil {
+		switch opts.GetMode() {
+		case pb.ImportMode_IMPORT_MODE_UNSPECIFIED, pb.ImportMode_IMPORT_MODE_MERGE:
+		case pb.ImportMode_IMPORT_MODE_REPLACE:
+			mode = service.ImportReplace
+		default:
+			return ToStatus("import", service.InvalidArgument("options.mode", "INVALID_IMPORT_MODE", "unknown import mode %d", opts.GetMode()))
+		}
+	}
+
+	next := func() (service.ArchivedNote, error) {
+		if pending != nil {
+			n := pending
+			pending = nil
+			return ArchivedFromPB(n), nil
+		}
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return service.ArchivedNote{}, io.EOF
+		}
+		if err != nil {
+			return service.ArchivedNote{}, streamError{err}
+		}
+		if req.GetNote() == nil {
+			return service.ArchivedNote{}, service.InvalidArgument("options", "OPTIONS_NOT_FIRST", "options must be sent only in the first message")
+		}
+		return ArchivedFromPB(req.GetNote()), nil
+	}
+	if first == nil {
+		next = func() (service.ArchivedNote, error) { return service.ArchivedNote{}, io.EOF }
+	}
+
+	res, err := h.svc.Import(stream.Context(), mode, next)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "list failed: %v", err)
+		var se streamError
+		if errors.As(err, &se) {
+			return se.err
+		}
+		return ToStatus(fmt.Sprintf("import (after %d imported)", res.Imported), err)
+	}
+	resp := &pb.ImportNotesResponse{
+		Received:  int32(res.Received),
+		Imported:  int32(res.Imported),
+		Unchanged: int32(res.Unchanged),
+		Deleted:   int32(res.Deleted),
+	}
+	for _, is := range res.Issues {
+		resp.Issues = append(resp.Issues, &pb.ImportIssue{Index: int32(is.Index), Id: is.ID, Reason: is.Reason, Message: is.Message})
+	}
+	return stream.SendAndClose(resp)
+}
+
+// bulkBatchSize — сколько элементов потока BulkCreateNotes сохраняем одним SaveMany.
+const bulkBatchSize = 500
+
+// BulkCreateNotes читает поток до конца, сохраняя его пачками по bulkBatchSize.
+// Невалидные элементы не прерывают импорт — они попадают в failures.
+func (h *NoteHandler) BulkCreateNotes(stream pb.NoteService_BulkCreateNotesServer) error {
+	resp := &pb.BulkCreateNotesResponse{}
+	batch := make([]service.NoteInput, 0, bulkBatchSize)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		offset := int(resp.Received) - len(batch)
+		res, err := h.svc.BulkCreate(stream.Context(), batch)
+		if err != nil {
+			return ToStatus(fmt.Sprintf("bulk create (after %d created)", len(resp.CreatedIds)), err)
+		}
+		for _, n := range res.Created {
+			resp.CreatedIds = append(resp.CreatedIds, n.ID)
+		}
+		for _, f := range res.Failures {
+			resp.Failures = append(resp.Failures, &pb.BulkCreateFailure{
+				Index:  int32(offset + f.Index),
+				Field:  f.Field,
+				Reason: f.Reason,
+			})
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		resp.Received++
+		batch = append(batch, service.NoteInput{Title: req.GetTitle(), Content: req.GetContent(), Tags: req.GetTags()})
+		if len(batch) == bulkBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+	return stream.SendAndClose(resp)
+}
+
+// UpdateFromPB превращает UpdateNoteRequest + FieldMask в service.NoteUpdate (нужна и REST-шлюзу).
+// Пустая маска — обновляем все изменяемые поля.
+func UpdateFromPB(req *pb.UpdateNoteRequest) (service.NoteUpdate, error) {
+	upd := service.NoteUpdate{ExpectedVersion: req.GetExpectedVersion()}
+	paths := req.GetUpdateMask().GetPaths()
+	if len(paths) == 0 {
+		paths = []string{"title", "content"}
+	}
+	for _, p := range paths {
+		switch p {
+		case "title":
+			t := req.GetTitle()
+			upd.Title = &t
+		case "content":
+			c := req.GetContent()
+			upd.Content = &c
+		default:
+			return service.NoteUpdate{}, service.InvalidArgument("update_mask", "UNKNOWN_MASK_PATH", "unknown update_mask path %q", p)
+		}
+	}
+	return upd, nil
+}
+
+// TagMatchFromPB — режим фильтра по тегам; UNSPECIFIED означает ANY (нужна и REST-шлюзу).
+func TagMatchFromPB(m pb.TagMatch) (service.TagMatch, error) {
+	switch m {
+	case pb.TagMatch_TAG_MATCH_UNSPECIFIED, pb.TagMatch_TAG_MATCH_ANY:
+		return service.TagMatchAny, nil
+	case pb.TagMatch_TAG_MATCH_ALL:
+		return service.TagMatchAll, nil
+	}
+	return 0, service.InvalidArgument("tag_match", "INVALID_TAG_MATCH", "unknown tag_match %d", m)
+}
+
+// TagCountsToPB — ответ ListTags (нужна и REST-шлюзу).
+func TagCountsToPB(tags []service.TagCount) []*pb.TagCount {
+	out := make([]*pb.TagCount, 0, len(tags))
+	for _, t := range tags {
+		out = append(out, &pb.TagCount{Tag: t.Tag, Count: int32(t.Count)})
 	}
-	out := make([]*pb.Note, 0, len(list))
-	for _, n := range list {
-		out = append(out, toPB(n))
+	return out
+}
+
+// DiffToPB — diff ревизий в ответ DiffNoteRevisions (нужен и REST-шлюзу).
+func DiffToPB(d service.RevisionDiff) *pb.DiffNoteRevisionsResponse {
+	return &pb.DiffNoteRevisionsResponse{Diff: d.Unified, FromVersion: d.From, ToVersion: d.To}
+}
+
+// RenderedToPB — отрисованная заметка в protobuf (нужна и REST-шлюзу).
+func RenderedToPB(r service.RenderedNote) *pb.RenderNoteResponse {
+	toc := make([]*pb.NoteHeading, 0, len(r.TOC))
+	for _, h := range r.TOC {
+		toc = append(toc, &pb.NoteHeading{Level: int32(h.Level), Text: h.Text, Id: h.ID})
+	}
+	links := make([]*pb.NoteLink, 0, len(r.Links))
+	for _, l := range r.Links {
+		links = append(links, &pb.NoteLink{Url: l.URL, Text: l.Text})
+	}
+	return &pb.RenderNoteResponse{
+		Html:      r.HTML,
+		Toc:       toc,
+		TocHtml:   r.TOCHTML,
+		Links:     links,
+		WordCount: int32(r.WordCount),
+		Version:   r.Note.Version,
+		Title:     r.Note.Title,
+	}
+}
+
+// ArchivedToPB — заметка с историей в запись архива. Время — с точностью до наносекунды.
+func ArchivedToPB(a service.ArchivedNote) *pb.ExportedNote {
+	e := &pb.ExportedNote{Note: archivedVersionToPB(a.Note)}
+	for _, r := range a.Revisions {
+		e.Revisions = append(e.Revisions, archivedVersionToPB(r))
+	}
+	return e
+}
+
+// ArchivedFromPB — запись архива в заметку с историей. Владельца назначает сервис.
+func ArchivedFromPB(n *pb.ExportedNote) service.ArchivedNote {
+	a := service.ArchivedNote{Note: archivedVersionFromPB(n.GetNote())}
+	for _, r := range n.GetRevisions() {
+		a.Revisions = append(a.Revisions, archivedVersionFromPB(r))
+	}
+	return a
+}
+
+func archivedVersionToPB(n service.Note) *pb.ArchivedNoteVersion {
+	m := &pb.ArchivedNoteVersion{
+		Id:        n.ID,
+		OwnerId:   n.OwnerID,
+		Title:     n.Title,
+		Content:   n.Content,
+		CreatedAt: timestampOrNil(n.CreatedAt),
+		UpdatedAt: timestampOrNil(n.UpdatedAt),
+		Version:   n.Version,
+		DeletedAt: timestampOrNil(n.DeletedAt),
+		Tags:      n.Tags,
+	}
+	for _, a := range n.Attachments {
+		m.Attachments = append(m.Attachments, &pb.ArchivedAttachment{
+			Id:          a.ID,
+			Name:        a.Name,
+			ContentType: a.ContentType,
+			Size:        a.Size,
+			Sha256:      a.SHA256,
+			CreatedAt:   timestampOrNil(a.CreatedAt),
+		})
+	}
+	return m
+}
+
+func archivedVersionFromPB(m *pb.ArchivedNoteVersion) service.Note {
+	n := service.Note{
+		ID:        m.GetId(),
+		OwnerID:   m.GetOwnerId(),
+		Title:     m.GetTitle(),
+		Content:   m.GetContent(),
+		CreatedAt: timeOrZero(m.GetCreatedAt()),
+		UpdatedAt: timeOrZero(m.GetUpdatedAt()),
+		Version:   m.GetVersion(),
+		DeletedAt: timeOrZero(m.GetDeletedAt()),
+		Tags:      m.GetTags(),
 	}
-	return &pb.ListNotesResponse{Notes: out}, nil
+	for _, a := range m.GetAttachments() {
+		n.Attachments = append(n.Attachments, service.Attachment{
+			ID:          a.GetId(),
+			Name:        a.GetName(),
+			ContentType: a.GetContentType(),
+			Size:        a.GetSize(),
+			SHA256:      a.GetSha256(),
+			CreatedAt:   timeOrZero(a.GetCreatedAt()),
+		})
+	}
+	return n
+}
+
+// timestampOrNil — нулевое время в архиве как отсутствие поля (как unixOrZero в Note).
+func timestampOrNil(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func timeOrZero(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
 }
 
-func toPB(n service.Note) *pb.Note {
+// NoteToPB — доменная заметка в protobuf (нужна и REST-шлюзу).
+func NoteToPB(n service.Note) *pb.Note {
 	return &pb.Note{
 		Id:        n.ID,
+		OwnerId:   n.OwnerID,
 		Title:     n.Title,
 		Content:   n.Content,
 		CreatedAt: n.CreatedAt.Unix(),
+		UpdatedAt: n.UpdatedAt.Unix(),
+		Version:   n.Version,
+		DeletedAt: unixOrZero(n.DeletedAt),
+		Tags:      n.Tags,
+
+		Attachments: AttachmentsToPB(n.Attachments),
+	}
+}
+
+// AttachmentToPB — метаданные вложения в protobuf (нужна и REST-шлюзу).
+func AttachmentToPB(a service.Attachment) *pb.Attachment {
+	return &pb.Attachment{
+		Id:          a.ID,
+		Name:        a.Name,
+		ContentType: a.ContentType,
+		Size:        a.Size,
+		Sha256:      a.SHA256,
+		CreatedAt:   a.CreatedAt.Unix(),
+	}
+}
+
+func AttachmentsToPB(as []service.Attachment) []*pb.Attachment {
+	if len(as) == 0 {
+		return nil
+	}
+	out := make([]*pb.Attachment, 0, len(as))
+	for _, a := range as {
+		out = append(out, AttachmentToPB(a))
+	}
+	return out
+}
+
+// NotesToPB — то же для страницы списка.
+func NotesToPB(notes []service.Note) []*pb.Note {
+	out := make([]*pb.Note, 0, len(notes))
+	for _, n := range notes {
+		out = append(out, NoteToPB(n))
+	}
+	return out
+}
+
+// unixOrZero — нулевое время в protobuf как 0, а не как Unix-время 0001 года.
+func unixOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+func eventToPB(ev service.NoteEvent) *pb.NoteEvent {
+	var typ pb.NoteEventType
+	switch ev.Type {
+	case service.EventCreated:
+		typ = pb.NoteEventType_NOTE_EVENT_TYPE_CREATED
+	case service.EventUpdated:
+		typ = pb.NoteEventType_NOTE_EVENT_TYPE_UPDATED
+	case service.EventDeleted:
+		typ = pb.NoteEventType_NOTE_EVENT_TYPE_DELETED
+	case service.EventRestored:
+		typ = pb.NoteEventType_NOTE_EVENT_TYPE_RESTORED
+	case service.EventPurged:
+		typ = pb.NoteEventType_NOTE_EVENT_TYPE_PURGED
+	}
+	return &pb.NoteEvent{
+		Seq:        ev.Seq,
+		Type:       typ,
+		Note:       NoteToPB(ev.Note),
+		OccurredAt: ev.At.Unix(),
 	}
 }
 
@@ -0,0 +1,49 @@
+package rest
+
+import (
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// httpStatus — HTTP-код для gRPC-кода. Таблица та же, что у grpc-gateway,
+// поэтому клиенту всё равно, через какой транспорт пришла ошибка.
+func httpStatus(c codes.Code) int {
+	switch c {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499 // Client Closed Request (nginx): клиент ушёл, не дождавшись ответа
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	default: // Unknown, Internal, DataLoss
+		return http.StatusInternalServerError
+	}
+}
+
+// writeStatus отдаёт ошибку телом google.rpc.Status — тем же, что видит gRPC-клиент:
+//
+//	{"code": 3, "message": "title is required", "details": [{"@type": "type.googleapis.com/google.rpc.BadRequest", ...}]}
+//
+// err должен быть уже gRPC-статусом (grpch.ToStatus); прочие ошибки станут Unknown.
+func writeStatus(w http.ResponseWriter, err error) {
+	st := status.Convert(err)
+	writeJSON(w, httpStatus(st.Code()), errorMarshalOpts, st.Proto())
+}
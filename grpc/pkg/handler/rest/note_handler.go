@@ -0,0 +1,592 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/verazalayli/go_studying/grpc/pkg/auth"
+	grpch "github.com/verazalayli/go_studying/grpc/pkg/handler/grpc"
+	"github.com/verazalayli/go_studying/grpc/pkg/service"
+	"github.com/verazalayli/go_studying/grpc/proto/pb"
+)
+
+/*
+	REST-шлюз — второй входной адаптер к тому же service.NoteService, для клиентов без gRPC.
+
+	Маршруты:
+	POST /notes        — создать заметку (тело — CreateNoteRequest в JSON;
+	                     ключ идемпотентности — idempotency_key или заголовок Idempotency-Key)
+	GET  /notes/{id}   — получить заметку (ответ — GetNoteResponse)
+	GET  /notes        — страница списка; параметры запроса как поля ListNotesRequest:
+	                     ?page_size=20&page_token=...&order_by=title&title_prefix=...
+	                     &tags=work&tags=urgent&tag_match=all (any — по умолчанию)
+	PATCH  /notes/{id} — изменить заметку (тело — UpdateNoteRequest без id)
+	DELETE /notes/{id} — убрать заметку в корзину
+	POST   /notes/{id}/tags       — добавить теги (тело — AddTagsRequest без id)
+	DELETE /notes/{id}/tags/{tag} — снять тег
+	GET    /tags       — теги вызывающего с числом заметок (ListTagsResponse)
+	GET    /notes/{id}/revisions           — история версий (?page_size=&page_token=)
+	GET    /notes/{id}/revisions/{version} — одна версия (GetNoteRevisionResponse)
+	GET    /notes/{id}/diff                — diff содержимого (?from=&to=, DiffNoteRevisionsResponse)
+	POST   /notes/{id}/revert              — откат к версии (тело — RevertNoteRequest без id)
+	GET    /notes/{id}/render              — текст в очищенном HTML с оглавлением
+	                     (?version=, RenderNoteResponse)
+	POST   /notes/{id}/attachments?name=&sha256= — загрузить вложение: тело — сами данные,
+	                     тип — из Content-Type (ответ — UploadAttachmentResponse)
+	GET    /notes/{id}/attachments/{attachment_id} — данные вложения как есть
+	                     (Content-Type, Content-Length, Content-Disposition с именем файла)
+	GET    /trash      — страница корзины (?page_size=&page_token=&order_by=&title_prefix=)
+	POST   /trash/{id}/restore — вернуть заметку из корзины
+	DELETE /trash/{id} — удалить заметку окончательно
+
+	Версия заметки отдаётся в заголовке ETag ("<version>"). PATCH, DELETE и методы
+	/notes/{id}/tags, /notes/{id}/revert, /notes/{id}/attachments, /trash/{id} принимают её обратно в If-Match (или expected_version в теле PATCH):
+	заметку успели изменить — 409 Conflict с current_version в деталях ошибки.
+
+	Тела — те же protobuf-сообщения, что в note.proto, сериализованные protojson
+	(имена полей как в .proto, int64 — строками, как требует JSON-маппинг protobuf).
+	Ошибки проходят через grpch.ToStatus: HTTP-код выводится из gRPC-кода (httpStatus),
+	а тело — google.rpc.Status с теми же деталями, что получает gRPC-клиент.
+
+	Аутентификация — тот же bearer-токен в заголовке Authorization.
+*/
+
+// maxBodySize — предел тела запроса.
+const maxBodySize = 1 << 20 // 1 MiB
+
+var (
+	// Ответы — со всеми полями, даже пустыми: фронтенду не нужно гадать о форме.
+	marshalOpts = protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true}
+	// Ошибки — без пустых полей деталей (reason: "", localized_message: null).
+	errorMarshalOpts = protojson.MarshalOptions{UseProtoNames: true}
+	unmarshalOpts    = protojson.UnmarshalOptions{}
+)
+
+// Handler — HTTP-обработчик заметок (реализует http.Handler).
+type Handler struct {
+	svc      service.NoteService
+	verifier auth.Verifier
+	log      *slog.Logger
+	mux      *http.ServeMux
+}
+
+// Option — функциональная опция Handler.
+type Option func(*Handler)
+
+// WithVerifier включает проверку bearer-токена; без неё все запросы анонимные.
+func WithVerifier(v auth.Verifier) Option {
+	return func(h *Handler) { h.verifier = v }
+}
+
+// WithLogger — куда писать access-лог (по умолчанию никуда).
+func WithLogger(l *slog.Logger) Option {
+	return func(h *Handler) { h.log = l }
+}
+
+// New — конструктор Handler.
+func New(svc service.NoteService, opts ...Option) *Handler {
+	h := &Handler{svc: svc, log: slog.New(slog.DiscardHandler)}
+	for _, o := range opts {
+		o(h)
+	}
+	h.mux = http.NewServeMux()
+	h.mux.HandleFunc("POST /notes", h.createNote)
+	h.mux.HandleFunc("GET /notes/{id}", h.getNote)
+	h.mux.HandleFunc("GET /notes", h.listNotes)
+	h.mux.HandleFunc("PATCH /notes/{id}", h.updateNote)
+	h.mux.HandleFunc("DELETE /notes/{id}", h.deleteNote)
+	h.mux.HandleFunc("POST /notes/{id}/tags", h.addTags)
+	h.mux.HandleFunc("DELETE /notes/{id}/tags/{tag}", h.removeTag)
+	h.mux.HandleFunc("GET /tags", h.listTags)
+	h.mux.HandleFunc("GET /notes/{id}/revisions", h.listRevisions)
+	h.mux.HandleFunc("GET /notes/{id}/revisions/{version}", h.getRevision)
+	h.mux.HandleFunc("GET /notes/{id}/diff", h.diffRevisions)
+	h.mux.HandleFunc("POST /notes/{id}/revert", h.revertNote)
+	h.mux.HandleFunc("GET /notes/{id}/render", h.renderNote)
+	h.mux.HandleFunc("POST /notes/{id}/attachments", h.uploadAttachment)
+	h.mux.HandleFunc("GET /notes/{id}/attachments/{attachment_id}", h.downloadAttachment)
+	h.mux.HandleFunc("GET /trash", h.listTrash)
+	h.mux.HandleFunc("POST /trash/{id}/restore", h.restoreNote)
+	h.mux.HandleFunc("DELETE /trash/{id}", h.purgeNote)
+	return h
+}
+
+// ServeHTTP проверяет токен, передаёт запрос маршрутизатору и пишет строку access-лога.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	if ctx, err := h.authenticate(r); err != nil {
+		writeStatus(rec, err)
+	} else {
+		h.mux.ServeHTTP(rec, r.WithContext(ctx))
+	}
+
+	level := slog.LevelInfo
+	switch {
+	case rec.status >= 500:
+		level = slog.LevelError
+	case rec.status >= 400:
+		level = slog.LevelWarn
+	}
+	h.log.LogAttrs(r.Context(), level, "http",
+		slog.String("method", r.Method),
+		slog.String("path", r.URL.Path),
+		slog.Int("status", rec.status),
+		slog.Duration("duration", time.Since(start)),
+		slog.String("peer", r.RemoteAddr),
+	)
+}
+
+// authenticate проверяет заголовок Authorization и возвращает контекст с auth.Principal.
+// Ответы те же, что у gRPC-перехватчика (middleware.UnaryAuth).
+func (h *Handler) authenticate(r *http.Request) (context.Context, error) {
+	if h.verifier == nil {
+		return r.Context(), nil
+	}
+	token, ok := auth.ParseBearer(r.Header.Get("Authorization"))
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "missing bearer token")
+	}
+	p, err := h.verifier.Verify(token)
+	if err != nil {
+		if errors.Is(err, auth.ErrTokenExpired) {
+			return nil, status.Error(codes.Unauthenticated, "token expired")
+		}
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+	return auth.NewContext(r.Context(), p), nil
+}
+
+func (h *Handler) createNote(w http.ResponseWriter, r *http.Request) {
+	var req pb.CreateNoteRequest
+	if err := readProto(w, r, &req); err != nil {
+		writeStatus(w, err)
+		return
+	}
+	// Ключ идемпотентности — из тела или, по HTTP-обычаю, из заголовка Idempotency-Key.
+	key := req.GetIdempotencyKey()
+	if key == "" {
+		key = r.Header.Get("Idempotency-Key")
+	}
+	in := service.NoteInput{Title: req.GetTitle(), Content: req.GetContent(), Tags: req.GetTags()}
+	n, err := h.svc.Create(r.Context(), in, key)
+	if err != nil {
+		writeStatus(w, grpch.ToStatus("create", err))
+		return
+	}
+	w.Header().Set("Location", "/notes/"+url.PathEscape(n.ID))
+	setETag(w, n)
+	writeProto(w, http.StatusCreated, &pb.CreateNoteResponse{Note: grpch.NoteToPB(n)})
+}
+
+func (h *Handler) getNote(w http.ResponseWriter, r *http.Request) {
+	n, err := h.svc.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeStatus(w, grpch.ToStatus("get", err))
+		return
+	}
+	setETag(w, n)
+	writeProto(w, http.StatusOK, &pb.GetNoteResponse{Note: grpch.NoteToPB(n)})
+}
+
+func (h *Handler) listNotes(w http.ResponseWriter, r *http.Request) {
+	opts, err := listOptions(r)
+	if err != nil {
+		writeStatus(w, grpch.ToStatus("list", err))
+		return
+	}
+	q := r.URL.Query()
+	opts.TitlePrefix = q.Get("title_prefix")
+	opts.Tags = q["tags"]
+	if opts.TagMatch, err = tagMatch(q.Get("tag_match")); err != nil {
+		writeStatus(w, grpch.ToStatus("list", err))
+		return
+	}
+	page, err := h.svc.List(r.Context(), opts)
+	if err != nil {
+		writeStatus(w, grpch.ToStatus("list", err))
+		return
+	}
+	writeProto(w, http.StatusOK, &pb.ListNotesResponse{Notes: grpch.NotesToPB(page.Notes), NextPageToken: page.NextPageToken})
+}
+
+func (h *Handler) listTrash(w http.ResponseWriter, r *http.Request) {
+	opts, err := listOptions(r)
+	if err != nil {
+		writeStatus(w, grpch.ToStatus("list trash", err))
+		return
+	}
+	opts.TitlePrefix = r.URL.Query().Get("title_prefix")
+	page, err := h.svc.ListTrash(r.Context(), opts)
+	if err != nil {
+		writeStatus(w, grpch.ToStatus("list trash", err))
+		return
+	}
+	writeProto(w, http.StatusOK, &pb.ListTrashResponse{Notes: grpch.NotesToPB(page.Notes), NextPageToken: page.NextPageToken})
+}
+
+func (h *Handler) restoreNote(w http.ResponseWriter, r *http.Request) {
+	v, err := ifMatch(r)
+	if err != nil {
+		writeStatus(w, grpch.ToStatus("restore", err))
+		return
+	}
+	n, err := h.svc.Restore(r.Context(), r.PathValue("id"), v)
+	if err != nil {
+		writeStatus(w, grpch.ToStatus("restore", err))
+		return
+	}
+	setETag(w, n)
+	writeProto(w, http.StatusOK, &pb.RestoreNoteResponse{Note: grpch.NoteToPB(n)})
+}
+
+func (h *Handler) purgeNote(w http.ResponseWriter, r *http.Request) {
+	v, err := ifMatch(r)
+	if err != nil {
+		writeStatus(w, grpch.ToStatus("purge", err))
+		return
+	}
+	if err := h.svc.Purge(r.Context(), r.PathValue("id"), v); err != nil {
+		writeStatus(w, grpch.ToStatus("purge", err))
+		return
+	}
+	writeProto(w, http.StatusOK, &pb.PurgeNoteResponse{})
+}
+
+func (h *Handler) updateNote(w http.ResponseWriter, r *http.Request) {
+	var req pb.UpdateNoteRequest
+	if err := readProto(w, r, &req); err != nil {
+		writeStatus(w, err)
+		return
+	}
+	req.Id = r.PathValue("id")
+	if req.GetExpectedVersion() == 0 {
+		v, err := ifMatch(r)
+		if err != nil {
+			writeStatus(w, grpch.ToStatus("update", err))
+			return
+		}
+		req.ExpectedVersion = v
+	}
+	upd, err := grpch.UpdateFromPB(&req)
+	if err != nil {
+		writeStatus(w, grpch.ToStatus("update", err))
+		return
+	}
+	n, err := h.svc.Update(r.Context(), req.GetId(), upd)
+	if err != nil {
+		writeStatus(w, grpch.ToStatus("update", err))
+		return
+	}
+	setETag(w, n)
+	writeProto(w, http.StatusOK, &pb.UpdateNoteResponse{Note: grpch.NoteToPB(n)})
+}
+
+func (h *Handler) addTags(w http.ResponseWriter, r *http.Request) {
+	var req pb.AddTagsRequest
+	if err := readProto(w, r, &req); err != nil {
+		writeStatus(w, err)
+		return
+	}
+	if req.GetExpectedVersion() == 0 {
+		v, err := ifMatch(r)
+		if err != nil {
+			writeStatus(w, grpch.ToStatus("add tags", err))
+			return
+		}
+		req.ExpectedVersion = v
+	}
+	n, err := h.svc.AddTags(r.Context(), r.PathValue("id"), req.GetTags(), req.GetExpectedVersion())
+	if err != nil {
+		writeStatus(w, grpch.ToStatus("add tags", err))
+		return
+	}
+	setETag(w, n)
+	writeProto(w, http.StatusOK, &pb.AddTagsResponse{Note: grpch.NoteToPB(n)})
+}
+
+func (h *Handler) removeTag(w http.ResponseWriter, r *http.Request) {
+	v, err := ifMatch(r)
+	if err != nil {
+		writeStatus(w, grpch.ToStatus("remove tags", err))
+		return
+	}
+	n, err := h.svc.RemoveTags(r.Context(), r.PathValue("id"), []string{r.PathValue("tag")}, v)
+	if err != nil {
+		writeStatus(w, grpch.ToStatus("remove tags", err))
+		return
+	}
+	setETag(w, n)
+	writeProto(w, http.StatusOK, &pb.RemoveTagsResponse{Note: grpch.NoteToPB(n)})
+}
+
+func (h *Handler) listTags(w http.ResponseWriter, r *http.Request) {
+	tags, err := h.svc.ListTags(r.Context())
+	if err != nil {
+		writeStatus(w, grpch.ToStatus("list tags", err))
+		return
+	}
+	writeProto(w, http.StatusOK, &pb.ListTagsResponse{Tags: grpch.TagCountsToPB(tags)})
+}
+
+func (h *Handler) listRevisions(w http.ResponseWriter, r *http.Request) {
+	opts, err := listOptions(r)
+	if err != nil {
+		writeStatus(w, grpch.ToStatus("list revisions", err))
+		return
+	}
+	page, err := h.svc.ListRevisions(r.Context(), r.PathValue("id"), service.RevisionListOptions{
+		PageSize:  opts.PageSize,
+		PageToken: opts.PageToken,
+	})
+	if err != nil {
+		writeStatus(w, grpch.ToStatus("list revisions", err))
+		return
+	}
+	writeProto(w, http.StatusOK, &pb.ListNoteRevisionsResponse{Revisions: grpch.NotesToPB(page.Revisions), NextPageToken: page.NextPageToken})
+}
+
+func (h *Handler) getRevision(w http.ResponseWriter, r *http.Request) {
+	v, err := versionParam("version", r.PathValue("version"))
+	if err != nil {
+		writeStatus(w, grpch.ToStatus("get revision", err))
+		return
+	}
+	n, err := h.svc.GetRevision(r.Context(), r.PathValue("id"), v)
+	if err != nil {
+		writeStatus(w, grpch.ToStatus("get revision", err))
+		return
+	}
+	writeProto(w, http.StatusOK, &pb.GetNoteRevisionResponse{Revision: grpch.NoteToPB(n)})
+}
+
+func (h *Handler) diffRevisions(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	from, err := versionParam("from", q.Get("from"))
+	if err != nil {
+		writeStatus(w, grpch.ToStatus("diff revisions", err))
+		return
+	}
+	to, err := versionParam("to", q.Get("to"))
+	if err != nil {
+		writeStatus(w, grpch.ToStatus("diff revisions", err))
+		return
+	}
+	d, err := h.svc.DiffRevisions(r.Context(), r.PathValue("id"), from, to)
+	if err != nil {
+		writeStatus(w, grpch.ToStatus("diff revisions", err))
+		return
+	}
+	writeProto(w, http.StatusOK, grpch.DiffToPB(d))
+}
+
+func (h *Handler) renderNote(w http.ResponseWriter, r *http.Request) {
+	v, err := versionParam("version", r.URL.Query().Get("version"))
+	if err != nil {
+		writeStatus(w, grpch.ToStatus("render", err))
+		return
+	}
+	rn, err := h.svc.Render(r.Context(), r.PathValue("id"), v)
+	if err != nil {
+		writeStatus(w, grpch.ToStatus("render", err))
+		return
+	}
+	writeProto(w, http.StatusOK, grpch.RenderedToPB(rn))
+}
+
+func (h *Handler) revertNote(w http.ResponseWriter, r *http.Request) {
+	var req pb.RevertNoteRequest
+	if err := readProto(w, r, &req); err != nil {
+		writeStatus(w, err)
+		return
+	}
+	if req.GetExpectedVersion() == 0 {
+		v, err := ifMatch(r)
+		if err != nil {
+			writeStatus(w, grpch.ToStatus("revert", err))
+			return
+		}
+		req.ExpectedVersion = v
+	}
+	n, err := h.svc.Revert(r.Context(), r.PathValue("id"), req.GetVersion(), req.GetExpectedVersion())
+	if err != nil {
+		writeStatus(w, grpch.ToStatus("revert", err))
+		return
+	}
+	setETag(w, n)
+	writeProto(w, http.StatusOK, &pb.RevertNoteResponse{Note: grpch.NoteToPB(n)})
+}
+
+// uploadAttachment передаёт тело запроса в сервис потоком: лимит размера
+// и SHA-256 проверяет сервис, как и для gRPC.
+func (h *Handler) uploadAttachment(w http.ResponseWriter, r *http.Request) {
+	v, err := ifMatch(r)
+	if err != nil {
+		writeStatus(w, grpch.ToStatus("upload attachment", err))
+		return
+	}
+	q := r.URL.Query()
+	in := service.AttachmentInput{
+		Name:            q.Get("name"),
+		ContentType:     r.Header.Get("Content-Type"),
+		SHA256:          q.Get("sha256"),
+		ExpectedVersion: v,
+	}
+	n, a, err := h.svc.UploadAttachment(r.Context(), r.PathValue("id"), in, r.Body)
+	if err != nil {
+		writeStatus(w, grpch.ToStatus("upload attachment", err))
+		return
+	}
+	w.Header().Set("Location", "/notes/"+url.PathEscape(n.ID)+"/attachments/"+url.PathEscape(a.ID))
+	setETag(w, n)
+	writeProto(w, http.StatusCreated, &pb.UploadAttachmentResponse{Note: grpch.NoteToPB(n), Attachment: grpch.AttachmentToPB(a)})
+}
+
+func (h *Handler) downloadAttachment(w http.ResponseWriter, r *http.Request) {
+	a, rc, err := h.svc.OpenAttachment(r.Context(), r.PathValue("id"), r.PathValue("attachment_id"))
+	if err != nil {
+		writeStatus(w, grpch.ToStatus("download attachment", err))
+		return
+	}
+	defer rc.Close()
+	w.Header().Set("Content-Type", a.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(a.Size, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": a.Name}))
+	w.Header().Set("ETag", strconv.Quote(a.SHA256))
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, rc); err != nil {
+		// Заголовки уже ушли: клиент увидит обрыв по Content-Length, нам остаётся лог.
+		h.log.ErrorContext(r.Context(), "download attachment", slog.String("attachment_id", a.ID), slog.Any("error", err))
+	}
+}
+
+func (h *Handler) deleteNote(w http.ResponseWriter, r *http.Request) {
+	v, err := ifMatch(r)
+	if err != nil {
+		writeStatus(w, grpch.ToStatus("delete", err))
+		return
+	}
+	if err := h.svc.Delete(r.Context(), r.PathValue("id"), v); err != nil {
+		writeStatus(w, grpch.ToStatus("delete", err))
+		return
+	}
+	writeProto(w, http.StatusOK, &pb.DeleteNoteResponse{})
+}
+
+// listOptions — общие параметры пагинации GET /notes и GET /trash.
+func listOptions(r *http.Request) (service.ListOptions, error) {
+	q := r.URL.Query()
+	opts := service.ListOptions{
+		PageToken: q.Get("page_token"),
+		OrderBy:   q.Get("order_by"),
+	}
+	if s := q.Get("page_size"); s != "" {
+		size, err := strconv.Atoi(s)
+		if err != nil {
+			return service.ListOptions{}, service.InvalidArgument("page_size", "INVALID_PAGE_SIZE", "page_size must be an integer")
+		}
+		opts.PageSize = size
+	}
+	return opts, nil
+}
+
+// versionParam разбирает номер версии из пути или запроса; пусто — 0 (по умолчанию).
+func versionParam(field, s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	v, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || v < 0 {
+		return 0, service.InvalidArgument(field, "INVALID_VERSION", "%s must be a non-negative integer", field)
+	}
+	return v, nil
+}
+
+// tagMatch разбирает tag_match: "any", "all" или имя значения enum (TAG_MATCH_ALL).
+func tagMatch(s string) (service.TagMatch, error) {
+	if s == "" {
+		return service.TagMatchAny, nil
+	}
+	name := strings.ToUpper(s)
+	if !strings.HasPrefix(name, "TAG_MATCH_") {
+		name = "TAG_MATCH_" + name
+	}
+	v, ok := pb.TagMatch_value[name]
+	if !ok {
+		return 0, service.InvalidArgument("tag_match", "INVALID_TAG_MATCH", "tag_match must be any or all")
+	}
+	return grpch.TagMatchFromPB(pb.TagMatch(v))
+}
+
+// setETag отдаёт версию заметки как сильный ETag.
+func setETag(w http.ResponseWriter, n service.Note) {
+	w.Header().Set("ETag", strconv.Quote(strconv.FormatInt(n.Version, 10)))
+}
+
+// ifMatch — ожидаемая версия из If-Match: "<version>" (W/ допускается).
+// Заголовка нет или "*" — 0, то есть без проверки.
+func ifMatch(r *http.Request) (int64, error) {
+	h := strings.TrimSpace(r.Header.Get("If-Match"))
+	if h == "" || h == "*" {
+		return 0, nil
+	}
+	tag, err := strconv.Unquote(strings.TrimPrefix(h, "W/"))
+	if err == nil {
+		var v int64
+		if v, err = strconv.ParseInt(tag, 10, 64); err == nil && v > 0 {
+			return v, nil
+		}
+	}
+	return 0, service.InvalidArgument("If-Match", "INVALID_ETAG", "must be a single ETag returned by the server")
+}
+
+// readProto читает тело запроса в protobuf-сообщение; ошибка — уже gRPC-статус.
+func readProto(w http.ResponseWriter, r *http.Request, m proto.Message) error {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "cannot read body: %v", err)
+	}
+	if err := unmarshalOpts.Unmarshal(body, m); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid JSON: %v", err)
+	}
+	return nil
+}
+
+func writeProto(w http.ResponseWriter, code int, m proto.Message) {
+	writeJSON(w, code, marshalOpts, m)
+}
+
+func writeJSON(w http.ResponseWriter, code int, opts protojson.MarshalOptions, m proto.Message) {
+	body, err := opts.Marshal(m)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	_, _ = w.Write(body)
+}
+
+// statusRecorder запоминает код ответа для access-лога.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(code int) {
+	s.status = code
+	s.ResponseWriter.WriteHeader(code)
+}
@@ -0,0 +1,120 @@
+package healthcheck
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+/*
+	Пакет healthcheck связывает стандартный grpc.health.v1 с доступностью хранилища.
+
+	Checker раз в интервал вызывает probe (обычно Ping репозитория) и выставляет
+	SERVING / NOT_SERVING для перечисленных сервисов и для "" — статуса сервера
+	целиком (его спрашивают оркестраторы: grpc_health_probe, Kubernetes gRPC probe).
+
+	Shutdown переводит всё в NOT_SERVING навсегда: его зовут перед GracefulStop,
+	чтобы балансировщик перестал слать новые запросы, пока старые дорабатывают.
+*/
+
+// Значения по умолчанию.
+const (
+	DefaultInterval = 5 * time.Second
+	DefaultTimeout  = 2 * time.Second
+)
+
+type Checker struct {
+	srv      *health.Server
+	probe    func(context.Context) error
+	services []string
+	interval time.Duration
+	timeout  time.Duration
+	onChange func(serving bool, err error)
+
+	mu      sync.Mutex
+	serving *bool // nil — ещё не проверяли
+}
+
+// Option — функциональная опция Checker.
+type Option func(*Checker)
+
+// WithInterval задаёт период проверки.
+func WithInterval(d time.Duration) Option {
+	return func(c *Checker) { c.interval = d }
+}
+
+// WithTimeout ограничивает одну проверку.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Checker) { c.timeout = d }
+}
+
+// WithOnChange вызывается, когда статус меняется (например, чтобы записать в лог).
+func WithOnChange(fn func(serving bool, err error)) Option {
+	return func(c *Checker) { c.onChange = fn }
+}
+
+// New создаёт Checker. probe == nil — проверять нечего, сервер всегда SERVING.
+// services — полные имена gRPC-сервисов ("note.v1.NoteService"), статус "" выставляется всегда.
+func New(srv *health.Server, probe func(context.Context) error, services []string, opts ...Option) *Checker {
+	c := &Checker{
+		srv:      srv,
+		probe:    probe,
+		services: append([]string{""}, services...),
+		interval: DefaultInterval,
+		timeout:  DefaultTimeout,
+		onChange: func(bool, error) {},
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// Run проверяет сразу и затем раз в интервал, пока ctx не отменён.
+func (c *Checker) Run(ctx context.Context) {
+	c.Check(ctx)
+	t := time.NewTicker(c.interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			c.Check(ctx)
+		}
+	}
+}
+
+// Check выполняет одну проверку и обновляет статусы.
+func (c *Checker) Check(ctx context.Context) {
+	var err error
+	if c.probe != nil {
+		pctx, cancel := context.WithTimeout(ctx, c.timeout)
+		err = c.probe(pctx)
+		cancel()
+	}
+	serving := err == nil
+	st := healthpb.HealthCheckResponse_SERVING
+	if !serving {
+		st = healthpb.HealthCheckResponse_NOT_SERVING
+	}
+	for _, s := range c.services {
+		c.srv.SetServingStatus(s, st)
+	}
+
+	c.mu.Lock()
+	changed := c.serving == nil || *c.serving != serving
+	c.serving = &serving
+	c.mu.Unlock()
+	if changed {
+		c.onChange(serving, err)
+	}
+}
+
+// Shutdown выставляет NOT_SERVING всем сервисам; последующие проверки статус не меняют.
+func (c *Checker) Shutdown() {
+	c.srv.Shutdown()
+}
@@ -0,0 +1,85 @@
+package janitor
+
+import (
+	"context"
+	"time"
+)
+
+/*
+	Пакет janitor — фоновая очистка корзины заметок.
+
+	Удалённые заметки (DeleteNote) лежат в корзине, пока их не вернут (RestoreNote)
+	или не удалят окончательно (PurgeNote). Janitor раз в интервал вызывает purge
+	(обычно service.NoteService.PurgeTrash) с границей "сейчас минус срок хранения",
+	так что заметка живёт в корзине не дольше retention + interval.
+
+	Run работает, пока не отменён контекст (у сервера — контекст сигналов),
+	и возвращается, только когда текущий проход закончен: после этого хранилище
+	можно закрывать.
+*/
+
+// DefaultInterval — период очистки по умолчанию.
+const DefaultInterval = time.Hour
+
+type Janitor struct {
+	purge     func(ctx context.Context, before time.Time) (int, error)
+	retention time.Duration
+	interval  time.Duration
+	onPurge   func(purged int, err error)
+}
+
+// Option — функциональная опция Janitor.
+type Option func(*Janitor)
+
+// WithInterval задаёт период очистки.
+func WithInterval(d time.Duration) Option {
+	return func(j *Janitor) { j.interval = d }
+}
+
+// WithOnPurge вызывается после прохода, который что-то удалил или закончился ошибкой
+// (например, чтобы записать в лог).
+func WithOnPurge(fn func(purged int, err error)) Option {
+	return func(j *Janitor) { j.onPurge = fn }
+}
+
+// New создаёт Janitor: заметки, пролежавшие в корзине дольше retention, удаляются через purge.
+func New(purge func(ctx context.Context, before time.Time) (int, error), retention time.Duration, opts ...Option) *Janitor {
+	j := &Janitor{
+		purge:     purge,
+		retention: retention,
+		interval:  DefaultInterval,
+		onPurge:   func(int, error) {},
+	}
+	for _, o := range opts {
+		o(j)
+	}
+	return j
+}
+
+// Run чистит сразу и затем раз в интервал, пока ctx не отменён.
+func (j *Janitor) Run(ctx context.Context) {
+	j.Purge(ctx)
+	t := time.NewTicker(j.interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			j.Purge(ctx)
+		}
+	}
+}
+
+// Purge выполняет один проход и возвращает, сколько заметок удалено.
+// Проход, прерванный отменой ctx, ошибкой не считается.
+func (j *Janitor) Purge(ctx context.Context) int {
+	n, err := j.purge(ctx, time.Now().Add(-j.retention))
+	if ctx.Err() != nil {
+		err = nil
+	}
+	if n > 0 || err != nil {
+		j.onPurge(n, err)
+	}
+	return n
+}
@@ -0,0 +1,201 @@
+// Package markdown — превращение текста заметки (Markdown, диалект GitHub) в безопасный HTML.
+//
+// Разбор и вывод делает goldmark с расширением GFM (таблицы, зачёркивание,
+// списки задач, автоссылки). Встроенный HTML из текста сохраняется, но весь
+// результат проходит через bluemonday по политике для пользовательского контента:
+// <script>, <style>, <iframe>, обработчики on*, style и ссылки javascript: вырезаются.
+//
+// Заголовкам выдаются якоря (атрибут id) по их тексту, с поддержкой не-ASCII:
+// "## Введение" — id="введение", повтор — "введение-1". Из тех же заголовков
+// строится оглавление. Заодно из документа извлекаются ссылки и число слов.
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	east "github.com/yuin/goldmark/extension/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+)
+
+// Heading — заголовок документа: уровень (1–6), текст без разметки и якорь.
+type Heading struct {
+	Level int
+	Text  string
+	ID    string
+}
+
+// Link — ссылка из документа: адрес как в тексте и её текст (для автоссылок — сам адрес).
+type Link struct {
+	URL  string
+	Text string
+}
+
+// Document — результат Render.
+type Document struct {
+	// HTML — очищенный фрагмент HTML (без <html>/<body>).
+	HTML string
+	// TOC — заголовки в порядке появления; TOCHTML — они же вложенными списками <ul>
+	// со ссылками на якоря. Заголовков нет — TOCHTML пустой.
+	TOC     []Heading
+	TOCHTML string
+	// Links — ссылки, которые остались в HTML после очистки, без повторов, в порядке появления.
+	Links []Link
+	// WordCount — число слов в тексте; блоки кода и встроенный HTML не считаются.
+	WordCount int
+}
+
+// Renderer переводит Markdown в HTML. Безопасен для одновременного использования.
+type Renderer struct {
+	md     goldmark.Markdown
+	policy *bluemonday.Policy
+}
+
+// headingID — какие якоря заголовков пропускает очистка: те, что генерирует headingIDs.
+var headingID = regexp.MustCompile(`^[\p{L}\p{N}_-]+$`)
+
+func NewRenderer() *Renderer {
+	md := goldmark.New(
+		goldmark.WithExtensions(extension.GFM),
+		goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+		// Встроенный HTML пропускаем в вывод: его безопасность — забота bluemonday ниже.
+		goldmark.WithRendererOptions(html.WithUnsafe()),
+	)
+
+	p := bluemonday.UGCPolicy()
+	// Стандартная политика пропускает только ASCII-id, а якоря бывают кириллическими.
+	p.AllowAttrs("id").Matching(headingID).OnElements("h1", "h2", "h3", "h4", "h5", "h6")
+	// Списки задач GFM: <input type="checkbox" disabled>.
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("checked", "disabled").Matching(regexp.MustCompile(`^$`)).OnElements("input")
+	// Подсветку языка в блоках кода (class="language-go") оставляем, прочие классы — нет.
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w+-]+$`)).OnElements("code")
+	p.RequireNoFollowOnLinks(true)
+
+	return &Renderer{md: md, policy: p}
+}
+
+// Render разбирает src и возвращает очищенный HTML с оглавлением и метаданными.
+func (r *Renderer) Render(src string) (Document, error) {
+	source := []byte(src)
+	ctx := parser.NewContext(parser.WithIDs(newHeadingIDs()))
+	root := r.md.Parser().Parse(text.NewReader(source), parser.WithContext(ctx))
+
+	var buf bytes.Buffer
+	if err := r.md.Renderer().Render(&buf, source, root); err != nil {
+		return Document{}, fmt.Errorf("render markdown: %w", err)
+	}
+	doc := Document{HTML: r.policy.Sanitize(buf.String())}
+	doc.TOC, doc.Links, doc.WordCount = r.inspect(root, source)
+	doc.TOCHTML = tocHTML(doc.TOC)
+	return doc, nil
+}
+
+// inspect обходит дерево документа и собирает заголовки, ссылки и число слов.
+func (r *Renderer) inspect(root ast.Node, source []byte) ([]Heading, []Link, int) {
+	var (
+		headings []Heading
+		links    []Link
+		seen     = make(map[string]bool)
+		words    int
+	)
+	addLink := func(dest, label string) {
+		if seen[dest] || !safeURL(dest) {
+			return
+		}
+		seen[dest] = true
+		links = append(links, Link{URL: dest, Text: label})
+	}
+
+	_ = ast.Walk(root, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *ast.Heading:
+			id, _ := n.AttributeString("id")
+			idb, _ := id.([]byte)
+			h := Heading{Level: n.Level, Text: plainText(n, source), ID: string(idb)}
+			headings = append(headings, h)
+			words += countWords(h.Text)
+		case *ast.Paragraph, *ast.TextBlock, *east.TableCell:
+			// Слова считаем по блоку целиком: "foo**bar**" — одно слово, а не два.
+			words += countWords(plainText(n, source))
+		case *ast.Link:
+			addLink(string(n.Destination), plainText(n, source))
+		case *ast.AutoLink:
+			dest := string(n.URL(source))
+			if n.AutoLinkType == ast.AutoLinkEmail && !strings.HasPrefix(strings.ToLower(dest), "mailto:") {
+				dest = "mailto:" + dest // как в href, который выводит goldmark
+			}
+			addLink(dest, string(n.Label(source)))
+		}
+		return ast.WalkContinue, nil
+	})
+	return headings, links, words
+}
+
+// safeURL — переживёт ли ссылка очистку: относительная или с разрешённой схемой.
+func safeURL(dest string) bool {
+	u, err := url.Parse(dest)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "", "http", "https", "mailto":
+		return true
+	}
+	return false
+}
+
+// plainText — текст узла без разметки: строки, код в строке и переносы (как пробелы).
+// Встроенный HTML пропускается.
+func plainText(n ast.Node, source []byte) string {
+	var sb strings.Builder
+	_ = ast.Walk(n, func(c ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch c := c.(type) {
+		case *ast.RawHTML:
+			return ast.WalkSkipChildren, nil
+		case *ast.Text:
+			sb.Write(c.Segment.Value(source))
+			if c.SoftLineBreak() || c.HardLineBreak() {
+				sb.WriteByte(' ')
+			}
+		case *ast.String:
+			sb.Write(c.Value)
+		case *ast.AutoLink:
+			sb.Write(c.Label(source))
+		}
+		return ast.WalkContinue, nil
+	})
+	return strings.TrimSpace(sb.String())
+}
+
+// countWords считает слова: последовательности букв и цифр, в которые могут входить
+// дефис и апостроф ("из-за", "don't" — по одному слову).
+func countWords(s string) int {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsMark(r) &&
+			r != '-' && r != '\'' && r != '’' && r != '_'
+	})
+	n := 0
+	for _, f := range fields {
+		if strings.IndexFunc(f, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) >= 0 {
+			n++
+		}
+	}
+	return n
+}
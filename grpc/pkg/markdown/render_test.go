@@ -0,0 +1,185 @@
+package markdown
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// go test ./pkg/markdown -update — перезаписать testdata/*.golden по текущему выводу.
+var update = flag.Bool("update", false, "rewrite testdata/*.golden")
+
+// dump — Document в виде для golden-файла: по разделу на поле, построчно, чтобы diff читался.
+func dump(doc Document) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "== html\n%s\n", strings.TrimSpace(doc.HTML))
+	sb.WriteString("== toc\n")
+	for _, h := range doc.TOC {
+		fmt.Fprintf(&sb, "%d %s %q\n", h.Level, h.ID, h.Text)
+	}
+	fmt.Fprintf(&sb, "== toc_html\n%s\n", strings.TrimSpace(doc.TOCHTML))
+	sb.WriteString("== links\n")
+	for _, l := range doc.Links {
+		fmt.Fprintf(&sb, "%s %q\n", l.URL, l.Text)
+	}
+	fmt.Fprintf(&sb, "== word_count\n%d\n", doc.WordCount)
+	return sb.String()
+}
+
+func TestRenderGolden(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join("testdata", "*.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(inputs) == 0 {
+		t.Fatal("no testdata/*.md")
+	}
+	r := NewRenderer()
+	for _, in := range inputs {
+		name := strings.TrimSuffix(filepath.Base(in), ".md")
+		t.Run(name, func(t *testing.T) {
+			src, err := os.ReadFile(in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			doc, err := r.Render(string(src))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := dump(doc)
+
+			golden := strings.TrimSuffix(in, ".md") + ".golden"
+			if *update {
+				if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v (run with -update to create it)", err)
+			}
+			if got != string(want) {
+				t.Errorf("output differs from %s (run with -update after checking the change):\n--- got\n%s\n--- want\n%s", golden, got, want)
+			}
+		})
+	}
+}
+
+// Проверки ниже не зависят от golden-файлов: -update не должен незаметно
+// узаконить вывод, в котором очистка или метаданные сломались.
+
+var (
+	scriptTag = regexp.MustCompile(`(?i)<\s*script`)
+	onAttr    = regexp.MustCompile(`(?i)\son[a-z]+\s*=`)
+)
+
+func render(t *testing.T, file string) Document {
+	t.Helper()
+	src, err := os.ReadFile(filepath.Join("testdata", file))
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := NewRenderer().Render(string(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestRenderSanitizes(t *testing.T) {
+	doc := render(t, "sanitize.md")
+	for _, bad := range []*regexp.Regexp{scriptTag, onAttr, regexp.MustCompile(`(?i)javascript:`),
+		regexp.MustCompile(`(?i)<\s*(iframe|style)`), regexp.MustCompile(`(?i)\sstyle\s*=`)} {
+		if loc := bad.FindStringIndex(doc.HTML); loc != nil {
+			t.Errorf("HTML still contains %q:\n%s", doc.HTML[loc[0]:loc[1]], doc.HTML)
+		}
+	}
+	if !strings.Contains(doc.HTML, `rel="nofollow"`) {
+		t.Errorf("links have no rel=nofollow:\n%s", doc.HTML)
+	}
+	for _, l := range doc.Links {
+		if strings.HasPrefix(strings.ToLower(l.URL), "javascript:") {
+			t.Errorf("unsafe link in metadata: %+v", l)
+		}
+	}
+}
+
+func TestRenderHeadings(t *testing.T) {
+	doc := render(t, "headings.md")
+	want := []Heading{
+		{1, "Что нового?", "что-нового"},
+		{2, "Установка", "установка"},
+		{3, "Из исходников", "из-исходников"},
+		{2, "Установка", "установка-1"},
+		{4, "Глубоко", "глубоко"},
+		{2, "code и жирный", "code-и-жирный"},
+		{1, "!!!", "section"},
+	}
+	if len(doc.TOC) != len(want) {
+		t.Fatalf("TOC = %+v, want %+v", doc.TOC, want)
+	}
+	for i, h := range doc.TOC {
+		if h != want[i] {
+			t.Errorf("TOC[%d] = %+v, want %+v", i, h, want[i])
+		}
+		if !strings.Contains(doc.HTML, fmt.Sprintf(`id="%s"`, h.ID)) {
+			t.Errorf("HTML has no anchor %q", h.ID)
+		}
+		if !strings.Contains(doc.TOCHTML, fmt.Sprintf(`href="#%s"`, h.ID)) {
+			t.Errorf("TOC HTML has no link to %q", h.ID)
+		}
+	}
+	if open, closed := strings.Count(doc.TOCHTML, "<ul>"), strings.Count(doc.TOCHTML, "</ul>"); open != closed {
+		t.Errorf("TOC HTML has %d <ul> and %d </ul>:\n%s", open, closed, doc.TOCHTML)
+	}
+}
+
+func TestRenderMetadata(t *testing.T) {
+	doc := render(t, "metadata.md")
+	want := []Link{
+		{"https://example.com/docs", "the docs"},
+		{"https://go.dev", "https://go.dev"},
+		{"../notes/1", "relative"},
+		{"mailto:mail@example.com", "mail@example.com"},
+	}
+	if fmt.Sprint(doc.Links) != fmt.Sprint(want) {
+		t.Errorf("Links = %+v, want %+v", doc.Links, want)
+	}
+	// Блок кода не считается; "from-scratch" и "don't" — по одному слову,
+	// адреса — по частям ("https://go.dev" — три).
+	if doc.WordCount != 37 {
+		t.Errorf("WordCount = %d, want 37", doc.WordCount)
+	}
+}
+
+func TestRenderDuplicateHeadings(t *testing.T) {
+	// Якоря повторов подбираются без перебора всех предыдущих суффиксов:
+	// с квадратичным перебором такой документ отрисовывался бы десятки секунд.
+	const n = 20000
+	src := strings.Repeat("# A\n\n", n) + "# A-5\n"
+	doc, err := NewRenderer().Render(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.TOC) != n+1 {
+		t.Fatalf("TOC has %d headings, want %d", len(doc.TOC), n+1)
+	}
+	seen := make(map[string]bool, len(doc.TOC))
+	for _, h := range doc.TOC {
+		if seen[h.ID] {
+			t.Fatalf("duplicate anchor %q", h.ID)
+		}
+		seen[h.ID] = true
+	}
+	if got, want := doc.TOC[n-1].ID, fmt.Sprintf("a-%d", n-1); got != want {
+		t.Errorf("last repeated anchor = %q, want %q", got, want)
+	}
+	// "A-5" уже занят пятым повтором "A" — получает свой суффикс.
+	if got := doc.TOC[n].ID; got != "a-5-1" {
+		t.Errorf(`anchor of "A-5" = %q, want "a-5-1"`, got)
+	}
+}
@@ -0,0 +1,100 @@
+package markdown
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+
+	"github.com/yuin/goldmark/ast"
+)
+
+// headingIDs — генератор якорей заголовков (parser.IDs) на один документ.
+// В отличие от стандартного в goldmark, не выбрасывает не-ASCII буквы:
+// "Что нового?" — "что-нового". Пустой результат — "section".
+type headingIDs struct {
+	used map[string]bool
+	next map[string]int // с какого суффикса продолжать поиск свободного якоря для base
+}
+
+func newHeadingIDs() *headingIDs {
+	return &headingIDs{used: make(map[string]bool), next: make(map[string]int)}
+}
+
+// Generate — slug, а для повтора — slug-1, slug-2, ... Поиск суффикса продолжается
+// с места прошлой остановки: тысячи одинаковых заголовков не дают квадратичного перебора.
+func (g *headingIDs) Generate(value []byte, _ ast.NodeKind) []byte {
+	id := slug(string(value))
+	if id == "" {
+		id = "section"
+	}
+	base := id
+	i := max(g.next[base], 1)
+	for ; g.used[id]; i++ {
+		id = fmt.Sprintf("%s-%d", base, i)
+	}
+	g.next[base] = i
+	g.used[id] = true
+	return []byte(id)
+}
+
+func (g *headingIDs) Put(value []byte) {
+	g.used[string(value)] = true
+}
+
+// slug — текст в нижнем регистре: буквы и цифры остаются, пробелы и дефисы
+// становятся одним дефисом, остальное (разметка, пунктуация) выбрасывается.
+func slug(s string) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			if dash && sb.Len() > 0 {
+				sb.WriteByte('-')
+			}
+			dash = false
+			sb.WriteRune(r)
+		case unicode.IsSpace(r) || r == '-':
+			dash = true
+		}
+	}
+	return sb.String()
+}
+
+// tocHTML строит оглавление вложенными <ul> по уровням заголовков.
+// Пропуск уровня (## сразу после #### и наоборот) не ломает вложенность:
+// глубина считается относительно самого мелкого уровня в документе.
+func tocHTML(headings []Heading) string {
+	if len(headings) == 0 {
+		return ""
+	}
+	top := headings[0].Level
+	for _, h := range headings {
+		top = min(top, h.Level)
+	}
+
+	var sb strings.Builder
+	depth := 0 // сколько <ul> открыто
+	for i, h := range headings {
+		level := h.Level - top + 1
+		switch {
+		case level > depth:
+			for ; depth < level; depth++ {
+				sb.WriteString("<ul>\n<li>")
+			}
+		default:
+			for ; depth > level; depth-- {
+				sb.WriteString("</li>\n</ul>\n")
+			}
+			if i > 0 {
+				sb.WriteString("</li>\n<li>")
+			}
+		}
+		fmt.Fprintf(&sb, `<a href="#%s">%s</a>`, html.EscapeString(h.ID), html.EscapeString(h.Text))
+	}
+	for ; depth > 0; depth-- {
+		sb.WriteString("</li>\n</ul>\n")
+	}
+	return sb.String()
+}
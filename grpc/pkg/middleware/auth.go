@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/verazalayli/go_studying/grpc/pkg/auth"
+)
+
+// UnaryAuth проверяет "authorization: Bearer <token>" из метаданных и кладёт
+// auth.Principal в контекст. Нет токена или он не прошёл проверку — Unauthenticated.
+// public — методы, доступные без токена: полное имя ("/pkg.Service/Method")
+// или весь сервис ("/pkg.Service/").
+func UnaryAuth(v auth.Verifier, public ...string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if isPublic(info.FullMethod, public) {
+			return handler(ctx, req)
+		}
+		ctx, err := authenticate(ctx, v)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamAuth — то же для потоковых RPC.
+func StreamAuth(v auth.Verifier, public ...string) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if isPublic(info.FullMethod, public) {
+			return handler(srv, ss)
+		}
+		ctx, err := authenticate(ss.Context(), v)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &wrappedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func isPublic(method string, public []string) bool {
+	for _, p := range public {
+		if method == p || (strings.HasSuffix(p, "/") && strings.HasPrefix(method, p)) {
+			return true
+		}
+	}
+	return false
+}
+
+func authenticate(ctx context.Context, v auth.Verifier) (context.Context, error) {
+	token, ok := bearerToken(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "missing bearer token")
+	}
+	p, err := v.Verify(token)
+	if err != nil {
+		if errors.Is(err, auth.ErrTokenExpired) {
+			return nil, status.Error(codes.Unauthenticated, "token expired")
+		}
+		// Подробности (какая именно проверка не прошла) клиенту не отдаём.
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+	return auth.NewContext(ctx, p), nil
+}
+
+func bearerToken(ctx context.Context) (string, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", false
+	}
+	for _, v := range md.Get("authorization") {
+		if token, ok := auth.ParseBearer(v); ok {
+			return token, true
+		}
+	}
+	return "", false
+}
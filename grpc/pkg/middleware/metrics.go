@@ -0,0 +1,269 @@
+package middleware
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+/*
+	Metrics — счётчики RPC в памяти процесса, которые отдаются по HTTP
+	в текстовом формате Prometheus (Metrics реализует http.Handler).
+
+	Имена и метки совпадают с go-grpc-prometheus, чтобы подходили готовые дашборды:
+	  grpc_server_started_total{grpc_type,grpc_service,grpc_method}
+	  grpc_server_handled_total{grpc_type,grpc_service,grpc_method,grpc_code}
+	  grpc_server_msg_received_total / grpc_server_msg_sent_total — сообщения в потоках
+	  grpc_server_handling_seconds — гистограмма длительности RPC
+	  grpc_server_in_flight — сколько RPC выполняется прямо сейчас
+*/
+
+// DefaultBuckets — границы гистограммы длительности в секундах (как в клиенте Prometheus).
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// rpcKey — набор меток одного метода.
+type rpcKey struct {
+	typ, service, method string
+}
+
+type handledKey struct {
+	rpcKey
+	code codes.Code
+}
+
+type histogram struct {
+	counts []uint64 // counts[i] — наблюдений <= buckets[i] (не накопительно)
+	sum    float64
+	count  uint64
+}
+
+type Metrics struct {
+	buckets []float64
+
+	mu        sync.Mutex
+	started   map[rpcKey]uint64
+	handled   map[handledKey]uint64
+	received  map[rpcKey]uint64
+	sent      map[rpcKey]uint64
+	durations map[rpcKey]*histogram
+	inFlight  int64
+}
+
+// MetricsOption — функциональная опция Metrics.
+type MetricsOption func(*Metrics)
+
+// WithBuckets задаёт свои границы гистограммы длительности (в секундах, по возрастанию).
+func WithBuckets(b ...float64) MetricsOption {
+	return func(m *Metrics) { m.buckets = slices.Clone(b) }
+}
+
+func NewMetrics(opts ...MetricsOption) *Metrics {
+	m := &Metrics{
+		buckets:   slices.Clone(DefaultBuckets),
+		started:   make(map[rpcKey]uint64),
+		handled:   make(map[handledKey]uint64),
+		received:  make(map[rpcKey]uint64),
+		sent:      make(map[rpcKey]uint64),
+		durations: make(map[rpcKey]*histogram),
+	}
+	for _, o := range opts {
+		o(m)
+	}
+	slices.Sort(m.buckets)
+	return m
+}
+
+// UnaryInterceptor считает unary-вызовы.
+func (m *Metrics) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		key := newRPCKey("unary", info.FullMethod)
+		start := m.begin(key)
+		resp, err := handler(ctx, req)
+		m.end(key, start, err)
+		return resp, err
+	}
+}
+
+// StreamInterceptor считает потоковые вызовы и сообщения в них.
+func (m *Metrics) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		key := newRPCKey(streamType(info), info.FullMethod)
+		start := m.begin(key)
+		err := handler(srv, &countingStream{ServerStream: ss, m: m, key: key})
+		m.end(key, start, err)
+		return err
+	}
+}
+
+func newRPCKey(typ, fullMethod string) rpcKey {
+	service, method := splitMethod(fullMethod)
+	return rpcKey{typ: typ, service: service, method: method}
+}
+
+func streamType(info *grpc.StreamServerInfo) string {
+	switch {
+	case info.IsClientStream && info.IsServerStream:
+		return "bidi_stream"
+	case info.IsClientStream:
+		return "client_stream"
+	default:
+		return "server_stream"
+	}
+}
+
+func (m *Metrics) begin(key rpcKey) time.Time {
+	m.mu.Lock()
+	m.started[key]++
+	m.inFlight++
+	m.mu.Unlock()
+	return time.Now()
+}
+
+func (m *Metrics) end(key rpcKey, start time.Time, err error) {
+	sec := time.Since(start).Seconds()
+	code := status.Code(err)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inFlight--
+	m.handled[handledKey{rpcKey: key, code: code}]++
+	h, ok := m.durations[key]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(m.buckets))}
+		m.durations[key] = h
+	}
+	if i, _ := slices.BinarySearch(m.buckets, sec); i < len(m.buckets) {
+		h.counts[i]++
+	}
+	h.sum += sec
+	h.count++
+}
+
+// countingStream считает сообщения, прошедшие через поток.
+type countingStream struct {
+	grpc.ServerStream
+	m   *Metrics
+	key rpcKey
+}
+
+func (s *countingStream) RecvMsg(msg any) error {
+	err := s.ServerStream.RecvMsg(msg)
+	if err == nil {
+		s.m.mu.Lock()
+		s.m.received[s.key]++
+		s.m.mu.Unlock()
+	}
+	return err
+}
+
+func (s *countingStream) SendMsg(msg any) error {
+	err := s.ServerStream.SendMsg(msg)
+	if err == nil {
+		s.m.mu.Lock()
+		s.m.sent[s.key]++
+		s.m.mu.Unlock()
+	}
+	return err
+}
+
+// ServeHTTP отдаёт все метрики в текстовом формате Prometheus (version 0.0.4).
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WriteText(w)
+}
+
+// WriteText пишет снимок метрик. Серии отсортированы, чтобы вывод был стабильным.
+func (m *Metrics) WriteText(out io.Writer) {
+	w := bufio.NewWriter(out)
+	defer w.Flush()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	writeCounter(w, "grpc_server_started_total", "Total number of RPCs started on the server.", m.started)
+
+	fmt.Fprintf(w, "# HELP grpc_server_handled_total Total number of RPCs completed on the server, regardless of success or failure.\n")
+	fmt.Fprintf(w, "# TYPE grpc_server_handled_total counter\n")
+	handled := make([]handledKey, 0, len(m.handled))
+	for k := range m.handled {
+		handled = append(handled, k)
+	}
+	slices.SortFunc(handled, func(a, b handledKey) int {
+		if c := compareKeys(a.rpcKey, b.rpcKey); c != 0 {
+			return c
+		}
+		return strings.Compare(a.code.String(), b.code.String())
+	})
+	for _, k := range handled {
+		fmt.Fprintf(w, "grpc_server_handled_total{%s,grpc_code=%q} %d\n", k.labels(), k.code.String(), m.handled[k])
+	}
+
+	writeCounter(w, "grpc_server_msg_received_total", "Total number of stream messages received from the client.", m.received)
+	writeCounter(w, "grpc_server_msg_sent_total", "Total number of stream messages sent by the server.", m.sent)
+
+	fmt.Fprintf(w, "# HELP grpc_server_handling_seconds Histogram of response latency (seconds) of RPCs handled by the server.\n")
+	fmt.Fprintf(w, "# TYPE grpc_server_handling_seconds histogram\n")
+	for _, k := range sortedKeys(m.durations) {
+		h := m.durations[k]
+		var cum uint64
+		for i, le := range m.buckets {
+			cum += h.counts[i]
+			fmt.Fprintf(w, "grpc_server_handling_seconds_bucket{%s,le=%q} %d\n", k.labels(), formatFloat(le), cum)
+		}
+		fmt.Fprintf(w, "grpc_server_handling_seconds_bucket{%s,le=\"+Inf\"} %d\n", k.labels(), h.count)
+		fmt.Fprintf(w, "grpc_server_handling_seconds_sum{%s} %s\n", k.labels(), formatFloat(h.sum))
+		fmt.Fprintf(w, "grpc_server_handling_seconds_count{%s} %d\n", k.labels(), h.count)
+	}
+
+	fmt.Fprintf(w, "# HELP grpc_server_in_flight Number of RPCs currently being handled.\n")
+	fmt.Fprintf(w, "# TYPE grpc_server_in_flight gauge\n")
+	fmt.Fprintf(w, "grpc_server_in_flight %d\n", m.inFlight)
+}
+
+func writeCounter(w io.Writer, name, help string, values map[rpcKey]uint64) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(w, "# TYPE %s counter\n", name)
+	for _, k := range sortedKeys(values) {
+		fmt.Fprintf(w, "%s{%s} %d\n", name, k.labels(), values[k])
+	}
+}
+
+func sortedKeys[V any](m map[rpcKey]V) []rpcKey {
+	keys := make([]rpcKey, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, compareKeys)
+	return keys
+}
+
+func compareKeys(a, b rpcKey) int {
+	if c := strings.Compare(a.service, b.service); c != 0 {
+		return c
+	}
+	if c := strings.Compare(a.method, b.method); c != 0 {
+		return c
+	}
+	return strings.Compare(a.typ, b.typ)
+}
+
+// labels — метки метода в синтаксисе Prometheus. %q экранирует \, " и перевод
+// строки так же, как требует формат (имена методов gRPC — ASCII).
+func (k rpcKey) labels() string {
+	return fmt.Sprintf("grpc_method=%q,grpc_service=%q,grpc_type=%q", k.method, k.service, k.typ)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
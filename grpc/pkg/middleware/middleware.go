@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"google.golang.org/grpc"
+
+	"github.com/verazalayli/go_studying/grpc/pkg/auth"
+)
+
+/*
+	Пакет middleware — перехватчики (interceptors) gRPC-сервера.
+
+	Каждая возможность — пара перехватчиков, unary и stream:
+	  RequestID — берёт x-request-id из метаданных запроса (или генерирует новый),
+	              кладёт в контекст и возвращает клиенту в заголовке ответа;
+	  Logging   — access-лог через log/slog: метод, код ответа, длительность, request id;
+	  Metrics   — счётчики и гистограммы длительности в формате Prometheus;
+	  Auth      — проверка bearer-токена и auth.Principal в контексте;
+	  Recovery  — паника в обработчике превращается в codes.Internal, а не роняет процесс.
+
+	Порядок важен, поэтому его задаёт ServerOptions: request id — самым внешним
+	(чтобы он попал в логи), recovery — самым внутренним (чтобы лог и метрики
+	увидели уже codes.Internal вместо паники).
+*/
+
+// ServerOptions собирает цепочку перехватчиков в опции grpc.NewServer.
+// metrics == nil — метрики не собираются; verifier == nil — аутентификация выключена.
+// Auth стоит после логов и метрик, чтобы отказы Unauthenticated тоже были видны;
+// public — методы без аутентификации (см. UnaryAuth), например health-check.
+func ServerOptions(log *slog.Logger, metrics *Metrics, verifier auth.Verifier, public ...string) []grpc.ServerOption {
+	unary := []grpc.UnaryServerInterceptor{UnaryRequestID(), UnaryLogging(log)}
+	stream := []grpc.StreamServerInterceptor{StreamRequestID(), StreamLogging(log)}
+	if metrics != nil {
+		unary = append(unary, metrics.UnaryInterceptor())
+		stream = append(stream, metrics.StreamInterceptor())
+	}
+	if verifier != nil {
+		unary = append(unary, UnaryAuth(verifier, public...))
+		stream = append(stream, StreamAuth(verifier, public...))
+	}
+	unary = append(unary, UnaryRecovery(log))
+	stream = append(stream, StreamRecovery(log))
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	}
+}
+
+// splitMethod разбирает "/note.v1.NoteService/CreateNote" на сервис и метод.
+func splitMethod(full string) (service, method string) {
+	full = strings.TrimPrefix(full, "/")
+	if i := strings.LastIndex(full, "/"); i >= 0 {
+		return full[:i], full[i+1:]
+	}
+	return "unknown", full
+}
+
+// wrappedStream подменяет контекст потока: stream-перехватчики не могут
+// передать дальше новый ctx иначе, чем через обёртку над ServerStream.
+type wrappedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *wrappedStream) Context() context.Context { return s.ctx }
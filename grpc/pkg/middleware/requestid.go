@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// RequestIDKey — ключ метаданных с идентификатором запроса (в запросе и в заголовке ответа).
+const RequestIDKey = "x-request-id"
+
+// maxRequestIDLen — длиннее не принимаем от клиента: id попадает в каждую строку лога.
+const maxRequestIDLen = 128
+
+type requestIDCtxKey struct{}
+
+// RequestIDFromContext возвращает id запроса, проставленный перехватчиком RequestID.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDCtxKey{}).(string)
+	return id
+}
+
+// UnaryRequestID пробрасывает x-request-id из метаданных клиента
+// (или генерирует новый) в контекст и в заголовок ответа.
+func UnaryRequestID() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, id := withRequestID(ctx)
+		_ = grpc.SetHeader(ctx, metadata.Pairs(RequestIDKey, id))
+		return handler(ctx, req)
+	}
+}
+
+// StreamRequestID — то же для потоковых RPC.
+func StreamRequestID() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, id := withRequestID(ss.Context())
+		_ = ss.SetHeader(metadata.Pairs(RequestIDKey, id))
+		return handler(srv, &wrappedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func withRequestID(ctx context.Context) (context.Context, string) {
+	var id string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if v := md.Get(RequestIDKey); len(v) > 0 && validRequestID(v[0]) {
+			id = v[0]
+		}
+	}
+	if id == "" {
+		id = uuid.NewString()
+	}
+	return context.WithValue(ctx, requestIDCtxKey{}, id), id
+}
+
+// validRequestID — непустая строка из печатных ASCII-символов разумной длины.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,172 @@
+package blob
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/verazalayli/go_studying/grpc/pkg/service"
+)
+
+/*
+	Store — содержимое вложений на локальном диске (адаптер к порту service.BlobStore).
+
+	Файл адресуется своим SHA-256: <dir>/<первые 2 hex>/<hex целиком>. Одинаковое
+	содержимое хранится один раз, сколько бы заметок его ни прикрепили, а имя файла
+	само по себе — контрольная сумма.
+
+	Put пишет поток во временный файл в <dir>/tmp, считая хеш и размер по ходу.
+	Превышен лимит или хеш не совпал с заявленным — временный файл удаляется,
+	в хранилище ничего не появляется. Иначе fsync, rename на место и fsync каталога:
+	после Put содержимое либо лежит целиком, либо его нет. Такой файл уже есть — новый
+	просто отбрасывается.
+
+	Open при чтении снова считает хеш и в конце сверяет его с именем: испорченный
+	на диске файл даст ошибку, а не тихо отдаст клиенту другие байты.
+
+	Файлы не удаляются: на одно содержимое может ссылаться несколько заметок,
+	а сборка мусора по ссылкам из хранилища заметок пока не сделана.
+*/
+
+const tmpDir = "tmp"
+
+type Store struct {
+	dir string
+}
+
+// NewStore создаёт каталоги хранилища, если их нет, и убирает временные файлы,
+// оставшиеся от прерванных загрузок.
+func NewStore(dir string) (*Store, error) {
+	if err := os.RemoveAll(filepath.Join(dir, tmpDir)); err != nil {
+		return nil, fmt.Errorf("clean blob tmp dir: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, tmpDir), 0o755); err != nil {
+		return nil, fmt.Errorf("create blob dir: %w", err)
+	}
+	return &Store{dir: dir}, nil
+}
+
+// path — где лежит содержимое с хешем sum (sum уже проверен validSum).
+func (s *Store) path(sum string) string {
+	return filepath.Join(s.dir, sum[:2], sum)
+}
+
+func (s *Store) Put(ctx context.Context, r io.Reader, limit int64, sum string) (int64, error) {
+	if !validSum(sum) {
+		return 0, service.ErrChecksumMismatch
+	}
+	tmp, err := os.CreateTemp(filepath.Join(s.dir, tmpDir), "upload-*")
+	if err != nil {
+		return 0, fmt.Errorf("create blob: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // после rename удалять уже нечего
+	defer tmp.Close()
+
+	h := sha256.New()
+	// Читаем на байт больше лимита: так видно, что поток длиннее, не дочитывая его до конца.
+	size, err := io.Copy(io.MultiWriter(tmp, h), io.LimitReader(ctxReader{ctx, r}, limit+1))
+	if err != nil {
+		return 0, fmt.Errorf("write blob: %w", err)
+	}
+	if size > limit {
+		return 0, service.ErrBlobTooLarge
+	}
+	if hex.EncodeToString(h.Sum(nil)) != sum {
+		return 0, service.ErrChecksumMismatch
+	}
+
+	dst := s.path(sum)
+	if _, err := os.Stat(dst); err == nil {
+		return size, nil
+	}
+	if err := tmp.Sync(); err != nil {
+		return 0, fmt.Errorf("sync blob: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, fmt.Errorf("close blob: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return 0, fmt.Errorf("create blob dir: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), dst); err != nil {
+		return 0, fmt.Errorf("rename blob: %w", err)
+	}
+	if err := syncDir(filepath.Dir(dst)); err != nil {
+		return 0, fmt.Errorf("sync blob dir: %w", err)
+	}
+	return size, nil
+}
+
+func (s *Store) Open(ctx context.Context, sum string) (io.ReadCloser, error) {
+	if !validSum(sum) {
+		return nil, service.ErrRecordNotFound
+	}
+	f, err := os.Open(s.path(sum))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, service.ErrRecordNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open blob: %w", err)
+	}
+	return &verifyingReader{f: f, h: sha256.New(), sum: sum}, nil
+}
+
+// verifyingReader отдаёт содержимое файла и на io.EOF сверяет его хеш с ожидаемым.
+type verifyingReader struct {
+	f   *os.File
+	h   hash.Hash
+	sum string
+}
+
+func (v *verifyingReader) Read(p []byte) (int, error) {
+	n, err := v.f.Read(p)
+	v.h.Write(p[:n])
+	if err == io.EOF && hex.EncodeToString(v.h.Sum(nil)) != v.sum {
+		return n, fmt.Errorf("blob %s is corrupted: checksum mismatch", v.sum)
+	}
+	return n, err
+}
+
+func (v *verifyingReader) Close() error { return v.f.Close() }
+
+// ctxReader прекращает чтение, когда запрос отменён.
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c ctxReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
+
+// validSum — 64 hex-символа в нижнем регистре. Хеш становится путём к файлу,
+// поэтому ничего другого (в том числе "../") сюда попасть не должно.
+func validSum(sum string) bool {
+	if len(sum) != sha256.Size*2 {
+		return false
+	}
+	for _, c := range sum {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
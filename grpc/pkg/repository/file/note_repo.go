@@ -0,0 +1,371 @@
+package file
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/verazalayli/go_studying/grpc/pkg/repository/internal/history"
+	"github.com/verazalayli/go_studying/grpc/pkg/repository/internal/tagindex"
+	"github.com/verazalayli/go_studying/grpc/pkg/service"
+)
+
+// SyncPolicy — когда вызывать fsync для журнала.
+type SyncPolicy int
+
+const (
+	// SyncAlways — fsync после каждой записи: подтверждённая запись переживёт падение ОС.
+	SyncAlways SyncPolicy = iota
+	// SyncInterval — fsync в фоне раз в интервал: быстрее, но можно потерять последние записи.
+	SyncInterval
+	// SyncNever — на усмотрение ОС: переживает падение процесса, но не питания.
+	SyncNever
+)
+
+// Значения по умолчанию.
+const (
+	DefaultCompactEvery = 1000
+	DefaultSyncInterval = time.Second
+)
+
+// NoteRepo — хранилище заметок на диске (адаптер к порту service.NoteRepository).
+// Все заметки держим в памяти, а каждое изменение сначала дописываем в WAL.
+// При старте состояние восстанавливается из снимка и журнала.
+type NoteRepo struct {
+	mu    sync.RWMutex
+	dir   string
+	items map[string]service.Note
+	tags  *tagindex.Index  // строится из items при старте, дальше обновляется в commitLocked
+	revs  *history.History // история версий: в снимке и журнале вместе с заметками
+
+	wal        *os.File
+	walSize    int64 // смещение конца последней целой записи
+	walRecords int   // записей в журнале с последней компакции
+
+	syncPolicy   SyncPolicy
+	syncInterval time.Duration
+	compactEvery int
+
+	done      chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// errClosed — повторный Close.
+var errClosed = errors.New("file repository is already closed")
+
+// Option — функциональная опция файлового хранилища.
+type Option func(*NoteRepo)
+
+// WithSyncPolicy задаёт политику fsync (по умолчанию SyncAlways).
+func WithSyncPolicy(p SyncPolicy) Option {
+	return func(r *NoteRepo) { r.syncPolicy = p }
+}
+
+// WithSyncInterval задаёт период fsync для SyncInterval.
+func WithSyncInterval(d time.Duration) Option {
+	return func(r *NoteRepo) { r.syncInterval = d }
+}
+
+// WithCompactEvery задаёт, после скольких записей в WAL делать снимок и очищать журнал.
+// 0 — компактить только при Close.
+func WithCompactEvery(n int) Option {
+	return func(r *NoteRepo) { r.compactEvery = n }
+}
+
+// NewNoteRepo открывает (или создаёт) хранилище в каталоге dir и проигрывает журнал.
+func NewNoteRepo(dir string, opts ...Option) (*NoteRepo, error) {
+	r := &NoteRepo{
+		dir:          dir,
+		items:        make(map[string]service.Note),
+		revs:         history.New(),
+		syncPolicy:   SyncAlways,
+		syncInterval: DefaultSyncInterval,
+		compactEvery: DefaultCompactEvery,
+		done:         make(chan struct{}),
+	}
+	for _, o := range opts {
+		o(r)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create data dir: %w", err)
+	}
+	if err := loadSnapshot(dir, r.items, r.revs); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(filepath.Join(dir, walFile), os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open wal: %w", err)
+	}
+	records, size, err := replay(f, r.items, r.revs)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	r.wal, r.walSize, r.walRecords = f, size, records
+	r.tags = tagindex.New()
+	for _, n := range r.items {
+		r.tags.Put(n)
+	}
+
+	if r.syncPolicy == SyncInterval {
+		r.wg.Add(1)
+		go r.syncLoop()
+	}
+	return r, nil
+}
+
+func (r *NoteRepo) Save(ctx context.Context, n service.Note) error {
+	return r.SaveMany(ctx, []service.Note{n})
+}
+
+func (r *NoteRepo) SaveMany(ctx context.Context, notes []service.Note) error {
+	rec := walRecord{Op: opPut, Notes: make([]noteRecord, 0, len(notes))}
+	for _, n := range notes {
+		rec.Notes = append(rec.Notes, toRecord(n))
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// Проверяем отмену уже под блокировкой: пока ждали мьютекс, клиент мог уйти.
+	// После начала записи в журнал операция доводится до конца.
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	// Версии проверяем под той же блокировкой, что и запись: между проверкой
+	// и commitLocked никто не вклинится.
+	pending := make(map[string]service.Note, len(notes))
+	for _, n := range notes {
+		cur, ok := pending[n.ID]
+		if !ok {
+			cur, ok = r.items[n.ID]
+		}
+		if !service.CanSave(cur, ok, n) {
+			return service.ErrVersionConflict
+		}
+		pending[n.ID] = n
+	}
+	return r.commitLocked(rec)
+}
+
+func (r *NoteRepo) GetByID(ctx context.Context, id string) (service.Note, error) {
+	if err := ctx.Err(); err != nil {
+		return service.Note{}, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	n, ok := r.items[id]
+	if !ok {
+		return service.Note{}, service.ErrRecordNotFound
+	}
+	return n, nil
+}
+
+func (r *NoteRepo) List(ctx context.Context, q service.ListQuery) ([]service.Note, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	out := r.tags.Select(r.items, q)
+	r.mu.RUnlock()
+	slices.SortFunc(out, q.Order.Compare)
+	if q.Limit > 0 && len(out) > q.Limit {
+		out = out[:q.Limit]
+	}
+	return out, nil
+}
+
+func (r *NoteRepo) Delete(ctx context.Context, id string, expectedVersion int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	cur, ok := r.items[id]
+	if !ok {
+		return service.ErrRecordNotFound
+	}
+	if !service.CanDelete(cur, expectedVersion) {
+		return service.ErrVersionConflict
+	}
+	return r.commitLocked(walRecord{Op: opDel, ID: id})
+}
+
+func (r *NoteRepo) Replace(ctx context.Context, id string, expectedVersion int64, states []service.Note) error {
+	rec := walRecord{Op: opReplace, ID: id, Notes: make([]noteRecord, 0, len(states))}
+	for _, n := range states {
+		rec.Notes = append(rec.Notes, toRecord(n))
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	cur, ok := r.items[id]
+	if !ok {
+		return service.ErrRecordNotFound
+	}
+	if !service.CanDelete(cur, expectedVersion) || !service.CanSaveHistory(id, states) {
+		return service.ErrVersionConflict
+	}
+	return r.commitLocked(rec)
+}
+
+func (r *NoteRepo) ListTags(ctx context.Context, owner string) ([]service.TagCount, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.tags.Counts(owner), nil
+}
+
+func (r *NoteRepo) ListRevisions(ctx context.Context, id string, before int64, limit int) ([]service.Note, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.revs.List(id, before, limit), nil
+}
+
+func (r *NoteRepo) GetRevision(ctx context.Context, id string, version int64) (service.Note, error) {
+	if err := ctx.Err(); err != nil {
+		return service.Note{}, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	n, ok := r.revs.Get(id, version)
+	if !ok {
+		return service.Note{}, service.ErrRecordNotFound
+	}
+	return n, nil
+}
+
+// Compact записывает снимок текущего состояния и очищает журнал.
+func (r *NoteRepo) Compact() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.compactLocked()
+}
+
+// Ping проверяет, что журнал открыт (после Close и при проблемах с файлом — ошибка).
+func (r *NoteRepo) Ping(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if _, err := r.wal.Stat(); err != nil {
+		return fmt.Errorf("wal: %w", err)
+	}
+	return nil
+}
+
+// Close делает финальную компакцию и закрывает журнал.
+// Повторный вызов ничего не делает и возвращает ошибку.
+func (r *NoteRepo) Close() error {
+	err := errClosed
+	r.closeOnce.Do(func() { err = r.close() })
+	return err
+}
+
+func (r *NoteRepo) close() error {
+	close(r.done)
+	r.wg.Wait()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	err := r.compactLocked()
+	if cerr := r.wal.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// commitLocked дописывает запись в журнал и только после успеха меняет состояние в памяти.
+func (r *NoteRepo) commitLocked(rec walRecord) error {
+	buf, err := encodeRecord(rec)
+	if err != nil {
+		return err
+	}
+	if _, err := r.wal.Write(buf); err != nil {
+		// Частичная запись оставила бы мусор посреди журнала,
+		// за которым потерялись бы все следующие записи. Откатываем хвост.
+		r.rollbackLocked()
+		return fmt.Errorf("append wal: %w", err)
+	}
+	if r.syncPolicy == SyncAlways {
+		if err := r.wal.Sync(); err != nil {
+			r.rollbackLocked()
+			return fmt.Errorf("sync wal: %w", err)
+		}
+	}
+	r.walSize += int64(len(buf))
+	r.walRecords++
+	apply(r.items, r.revs, rec)
+	switch rec.Op {
+	case opPut:
+		for _, nr := range rec.Notes {
+			r.tags.Put(r.items[nr.ID])
+		}
+	case opDel:
+		r.tags.Delete(rec.ID)
+	case opReplace:
+		r.tags.Put(r.items[rec.ID])
+	}
+
+	if r.compactEvery > 0 && r.walRecords >= r.compactEvery {
+		// Запись уже надёжно в журнале: ошибка компакции не отменяет её,
+		// журнал просто продолжит расти до следующей попытки.
+		_ = r.compactLocked()
+	}
+	return nil
+}
+
+func (r *NoteRepo) rollbackLocked() {
+	_ = r.wal.Truncate(r.walSize)
+	_, _ = r.wal.Seek(r.walSize, io.SeekStart)
+}
+
+func (r *NoteRepo) compactLocked() error {
+	if r.walRecords == 0 {
+		return nil
+	}
+	if err := writeSnapshot(r.dir, r.items, r.revs); err != nil {
+		return err
+	}
+	if err := r.wal.Truncate(0); err != nil {
+		return fmt.Errorf("truncate wal: %w", err)
+	}
+	if _, err := r.wal.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("seek wal: %w", err)
+	}
+	if err := r.wal.Sync(); err != nil {
+		return fmt.Errorf("sync wal: %w", err)
+	}
+	r.walSize, r.walRecords = 0, 0
+	return nil
+}
+
+func (r *NoteRepo) syncLoop() {
+	defer r.wg.Done()
+	t := time.NewTicker(r.syncInterval)
+	defer t.Stop()
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-t.C:
+			r.mu.Lock()
+			_ = r.wal.Sync()
+			r.mu.Unlock()
+		}
+	}
+}
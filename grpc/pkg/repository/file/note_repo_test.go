@@ -0,0 +1,275 @@
+package file
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/verazalayli/go_studying/grpc/pkg/service"
+)
+
+var t0 = time.Date(2025, 1, 2, 3, 4, 5, 123456789, time.UTC)
+
+func note(id, title string, version int64, tags ...string) service.Note {
+	return service.Note{ID: id, OwnerID: "alice", Title: title, Content: "text of " + id,
+		CreatedAt: t0, UpdatedAt: t0.Add(time.Duration(version) * time.Second), Version: version, Tags: tags}
+}
+
+func open(t *testing.T, dir string, opts ...Option) *NoteRepo {
+	t.Helper()
+	r, err := NewNoteRepo(dir, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+// crash бросает хранилище без Close, как при падении процесса: ни компакции,
+// ни снимка — после рестарта состояние восстанавливается только из журнала.
+func crash(t *testing.T, r *NoteRepo) {
+	t.Helper()
+	r.closeOnce.Do(func() {
+		close(r.done)
+		r.wg.Wait()
+		r.wal.Close()
+	})
+}
+
+func save(t *testing.T, r *NoteRepo, notes ...service.Note) {
+	t.Helper()
+	if err := r.SaveMany(context.Background(), notes); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// check сверяет заметку и её историю после перезапуска.
+func check(t *testing.T, r *NoteRepo, want service.Note, versions ...int64) {
+	t.Helper()
+	ctx := context.Background()
+	got, err := r.GetByID(ctx, want.ID)
+	if err != nil {
+		t.Fatalf("GetByID(%s): %v", want.ID, err)
+	}
+	if got.Title != want.Title || got.Version != want.Version || !got.UpdatedAt.Equal(want.UpdatedAt) ||
+		!slices.Equal(got.Tags, want.Tags) {
+		t.Errorf("GetByID(%s) = %+v, want %+v", want.ID, got, want)
+	}
+	revs, err := r.ListRevisions(ctx, want.ID, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gotVersions []int64
+	for _, n := range revs {
+		gotVersions = append(gotVersions, n.Version)
+	}
+	if !slices.Equal(gotVersions, versions) {
+		t.Errorf("revisions of %s = %v, want %v", want.ID, gotVersions, versions)
+	}
+}
+
+func walSize(t *testing.T, dir string) int64 {
+	t.Helper()
+	fi, err := os.Stat(filepath.Join(dir, walFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fi.Size()
+}
+
+func TestReplayAfterCrash(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	r := open(t, dir)
+	a1, a2 := note("a", "A", 1, "go"), note("a", "A2", 2, "grpc")
+	save(t, r, a1, note("b", "B", 1), note("c", "C", 1, "go"))
+	save(t, r, a2)
+	if err := r.Delete(ctx, "b", 1); err != nil {
+		t.Fatal(err)
+	}
+	c2 := note("c", "C (imported)", 1, "sql")
+	if err := r.Replace(ctx, "c", 1, []service.Note{c2}); err != nil {
+		t.Fatal(err)
+	}
+	crash(t, r)
+
+	r = open(t, dir)
+	defer r.Close()
+	check(t, r, a2, 2, 1)
+	check(t, r, c2, 1)
+	if _, err := r.GetByID(ctx, "b"); !errors.Is(err, service.ErrRecordNotFound) {
+		t.Errorf("deleted note after replay: err = %v", err)
+	}
+	tags, err := r.ListTags(ctx, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []service.TagCount{{Tag: "grpc", Count: 1}, {Tag: "sql", Count: 1}}; !slices.Equal(tags, want) {
+		t.Errorf("tags after replay = %v, want %v", tags, want)
+	}
+}
+
+func TestTornTail(t *testing.T) {
+	tests := []struct {
+		name string
+		tail func(good []byte) []byte
+	}{
+		{"partial header", func([]byte) []byte { return []byte{0, 0, 1} }},
+		{"partial payload", func(good []byte) []byte { return good[:len(good)-5] }},
+		{"bad crc", func(good []byte) []byte {
+			bad := slices.Clone(good)
+			binary.BigEndian.PutUint32(bad[4:8], binary.BigEndian.Uint32(bad[4:8])+1)
+			return bad
+		}},
+		{"bad payload", func(good []byte) []byte {
+			bad := slices.Clone(good)
+			bad[len(bad)-2] ^= 0xff
+			return bad
+		}},
+		{"length over the limit", func([]byte) []byte {
+			hdr := make([]byte, headerSize)
+			binary.BigEndian.PutUint32(hdr, maxRecordSize+1)
+			return hdr
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			r := open(t, dir)
+			a := note("a", "A", 1)
+			save(t, r, a)
+			crash(t, r)
+			size := walSize(t, dir)
+
+			// Недописанная запись: так выглядит хвост, если процесс упал посреди Write.
+			good, err := encodeRecord(walRecord{Op: opPut, Notes: []noteRecord{toRecord(note("b", "B", 1))}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			f, err := os.OpenFile(filepath.Join(dir, walFile), os.O_WRONLY|os.O_APPEND, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := f.Write(tt.tail(good)); err != nil {
+				t.Fatal(err)
+			}
+			f.Close()
+
+			r = open(t, dir)
+			check(t, r, a, 1)
+			if _, err := r.GetByID(context.Background(), "b"); !errors.Is(err, service.ErrRecordNotFound) {
+				t.Errorf("note from the torn record: err = %v", err)
+			}
+			if got := walSize(t, dir); got != size {
+				t.Errorf("wal size after replay = %d, want %d (torn tail cut off)", got, size)
+			}
+			// Следующая запись ложится сразу за целой, а не за мусором.
+			c := note("c", "C", 1)
+			save(t, r, c)
+			crash(t, r)
+
+			r = open(t, dir)
+			defer r.Close()
+			check(t, r, a, 1)
+			check(t, r, c, 1)
+		})
+	}
+}
+
+func TestCompactionThenReplay(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	r := open(t, dir, WithCompactEvery(3))
+	for v := int64(1); v <= 4; v++ {
+		save(t, r, note("a", fmt.Sprintf("A%d", v), v, "go"))
+	}
+	save(t, r, note("b", "B", 1))
+	if err := r.Delete(ctx, "b", 1); err != nil {
+		t.Fatal(err)
+	}
+	// 6 записей при компакции каждые 3: снимок есть, журнал после него пуст.
+	if _, err := os.Stat(filepath.Join(dir, snapshotFile)); err != nil {
+		t.Fatalf("no snapshot after compaction: %v", err)
+	}
+	if got := walSize(t, dir); got != 0 {
+		t.Errorf("wal size right after compaction = %d, want 0", got)
+	}
+	a5 := note("a", "A5", 5)
+	save(t, r, a5) // поверх снимка — одна запись в журнале
+	crash(t, r)
+
+	r = open(t, dir, WithCompactEvery(3))
+	check(t, r, a5, 5, 4, 3, 2, 1)
+	if _, err := r.GetByID(ctx, "b"); !errors.Is(err, service.ErrRecordNotFound) {
+		t.Errorf("note deleted before compaction: err = %v", err)
+	}
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Close компактит: после него журнал пуст, всё — в снимке.
+	if got := walSize(t, dir); got != 0 {
+		t.Errorf("wal size after Close = %d, want 0", got)
+	}
+	r = open(t, dir)
+	defer r.Close()
+	check(t, r, a5, 5, 4, 3, 2, 1)
+}
+
+func TestRecordTooLarge(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	r := open(t, dir)
+	before := walSize(t, dir)
+
+	// Пачка, которая не помещается в одну запись журнала, отклоняется целиком
+	// до записи: иначе replay счёл бы её рваной и отрезал вместе со всем, что после.
+	content := strings.Repeat("x", 4<<20)
+	var big []service.Note
+	for i := range maxRecordSize/len(content) + 1 {
+		n := note(fmt.Sprintf("big-%d", i), "big", 1)
+		n.Content = content
+		big = append(big, n)
+	}
+	if err := r.SaveMany(ctx, big); !errors.Is(err, errRecordTooLarge) {
+		t.Fatalf("SaveMany over the limit: err = %v, want errRecordTooLarge", err)
+	}
+	if got := walSize(t, dir); got != before {
+		t.Errorf("wal grew by %d bytes after a rejected record", got-before)
+	}
+	if _, err := r.GetByID(ctx, "big-0"); !errors.Is(err, service.ErrRecordNotFound) {
+		t.Errorf("note from a rejected batch is visible: err = %v", err)
+	}
+
+	// Пачка под лимитом и запись после неё переживают рестарт.
+	save(t, r, big[:3]...)
+	small := note("small", "S", 1)
+	save(t, r, small)
+	crash(t, r)
+
+	r = open(t, dir)
+	defer r.Close()
+	check(t, r, small, 1)
+	for _, n := range big[:3] {
+		got, err := r.GetByID(ctx, n.ID)
+		if err != nil || len(got.Content) != len(content) {
+			t.Errorf("GetByID(%s): %d bytes, err %v", n.ID, len(got.Content), err)
+		}
+	}
+}
+
+func TestCloseTwice(t *testing.T) {
+	r := open(t, t.TempDir())
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Close(); !errors.Is(err, errClosed) {
+		t.Errorf("second Close: err = %v, want errClosed", err)
+	}
+}
@@ -0,0 +1,323 @@
+package file
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/verazalayli/go_studying/grpc/pkg/repository/internal/history"
+	"github.com/verazalayli/go_studying/grpc/pkg/service"
+)
+
+/*
+	Формат write-ahead log (wal.log):
+
+	Файл — последовательность записей, каждая запись:
+	  [4 байта длина payload, big-endian][4 байта CRC32(payload)][payload — JSON walRecord]
+
+	Запись на диск идёт только дозаписью в конец. Если процесс упал посреди записи,
+	в хвосте окажется "рваная" запись: неполная длина/payload или неверный CRC.
+	При старте мы читаем записи, пока они целые, и обрезаем файл по последней
+	целой записи — всё после неё никогда не было подтверждено клиенту.
+
+	Снимок (snapshot.json) — полное состояние на момент компакции: заметки и история
+	их версий (каждый put пополняет историю, del стирает её вместе с заметкой,
+	replace — del и put новой истории в одной записи).
+	Состояние = снимок + все записи WAL поверх него. Операции put/del/replace идемпотентны,
+	поэтому падение между записью снимка и очисткой WAL безопасно: записи просто
+	применятся повторно.
+*/
+
+const (
+	walFile      = "wal.log"
+	snapshotFile = "snapshot.json"
+
+	headerSize = 8
+	// maxRecordSize — предел payload одной записи. Один и тот же для записи и чтения:
+	// encodeRecord не пишет запись больше, а readRecord считает большую длину мусором
+	// в хвосте файла. Заодно длина всегда помещается в uint32 заголовка.
+	maxRecordSize = 64 << 20
+)
+
+const (
+	opPut     = "put"
+	opDel     = "del"
+	opReplace = "replace" // del заметки ID и put её новой истории Notes одной записью
+)
+
+// walRecord — одна операция в журнале. Put может содержать пачку заметок (SaveMany),
+// пачка применяется атомарно: либо вся запись целая, либо её нет. Так же и replace.
+type walRecord struct {
+	Op    string       `json:"op"`
+	Notes []noteRecord `json:"notes,omitempty"`
+	ID    string       `json:"id,omitempty"`
+}
+
+// noteRecord — формат заметки на диске. Отделён от service.Note, чтобы доменная
+// модель не зависела от тегов сериализации.
+type noteRecord struct {
+	ID        string    `json:"id"`
+	OwnerID   string    `json:"owner_id,omitempty"`
+	Title     string    `json:"title"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Version   int64     `json:"version,omitempty"`
+	DeletedAt time.Time `json:"deleted_at,omitzero"`
+	Tags      []string  `json:"tags,omitempty"`
+
+	Attachments []attachmentRecord `json:"attachments,omitempty"`
+}
+
+// attachmentRecord — формат вложения на диске.
+type attachmentRecord struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	SHA256      string    `json:"sha256"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func toAttachmentRecords(as []service.Attachment) []attachmentRecord {
+	if len(as) == 0 {
+		return nil
+	}
+	out := make([]attachmentRecord, len(as))
+	for i, a := range as {
+		out[i] = attachmentRecord(a)
+	}
+	return out
+}
+
+func toAttachments(rs []attachmentRecord) []service.Attachment {
+	if len(rs) == 0 {
+		return nil
+	}
+	out := make([]service.Attachment, len(rs))
+	for i, r := range rs {
+		out[i] = service.Attachment(r)
+	}
+	return out
+}
+
+func toRecord(n service.Note) noteRecord {
+	return noteRecord{
+		ID:        n.ID,
+		OwnerID:   n.OwnerID,
+		Title:     n.Title,
+		Content:   n.Content,
+		CreatedAt: n.CreatedAt,
+		UpdatedAt: n.UpdatedAt,
+		Version:   n.Version,
+		DeletedAt: n.DeletedAt,
+		Tags:      n.Tags,
+
+		Attachments: toAttachmentRecords(n.Attachments),
+	}
+}
+
+// toNote: записи, сделанные до появления версий, считаются версией 1.
+func (r noteRecord) toNote() service.Note {
+	v := r.Version
+	if v == 0 {
+		v = 1
+	}
+	return service.Note{
+		ID:        r.ID,
+		OwnerID:   r.OwnerID,
+		Title:     r.Title,
+		Content:   r.Content,
+		CreatedAt: r.CreatedAt,
+		UpdatedAt: r.UpdatedAt,
+		Version:   v,
+		DeletedAt: r.DeletedAt,
+		Tags:      r.Tags,
+
+		Attachments: toAttachments(r.Attachments),
+	}
+}
+
+// snapshot — содержимое snapshot.json.
+type snapshot struct {
+	Notes     []noteRecord `json:"notes"`
+	Revisions []noteRecord `json:"revisions,omitempty"`
+}
+
+// errRecordTooLarge — операция не помещается в одну запись журнала.
+var errRecordTooLarge = errors.New("wal record too large")
+
+// encodeRecord собирает запись WAL целиком, чтобы записать её одним Write.
+// Запись больше maxRecordSize не собирается: при чтении она выглядела бы рваной,
+// и replay отрезал бы её вместе со всеми следующими.
+func encodeRecord(rec walRecord) ([]byte, error) {
+	payload, err := json.Marshal(rec)
+	if err != nil {
+		return nil, fmt.Errorf("marshal wal record: %w", err)
+	}
+	if len(payload) > maxRecordSize {
+		return nil, fmt.Errorf("%w: %d bytes, limit %d", errRecordTooLarge, len(payload), maxRecordSize)
+	}
+	buf := make([]byte, headerSize+len(payload))
+	binary.BigEndian.PutUint32(buf[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(buf[4:8], crc32.ChecksumIEEE(payload))
+	copy(buf[headerSize:], payload)
+	return buf, nil
+}
+
+// errTorn — хвост журнала повреждён (незавершённая запись).
+var errTorn = errors.New("torn wal record")
+
+// readRecord читает одну запись. io.EOF — журнал закончился ровно на границе записи.
+func readRecord(r io.Reader) (walRecord, int64, error) {
+	var hdr [headerSize]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		if err == io.EOF {
+			return walRecord{}, 0, io.EOF
+		}
+		return walRecord{}, 0, errTorn
+	}
+	size := binary.BigEndian.Uint32(hdr[0:4])
+	if size > maxRecordSize {
+		return walRecord{}, 0, errTorn
+	}
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return walRecord{}, 0, errTorn
+	}
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(hdr[4:8]) {
+		return walRecord{}, 0, errTorn
+	}
+	var rec walRecord
+	if err := json.Unmarshal(payload, &rec); err != nil {
+		return walRecord{}, 0, errTorn
+	}
+	return rec, int64(headerSize) + int64(size), nil
+}
+
+// apply применяет запись к состоянию. Повтор записи не дублирует историю:
+// history.Put заменяет уже записанную версию.
+func apply(items map[string]service.Note, revs *history.History, rec walRecord) {
+	switch rec.Op {
+	case opPut:
+		for _, nr := range rec.Notes {
+			n := nr.toNote()
+			items[nr.ID] = n
+			revs.Put(n)
+		}
+	case opDel:
+		delete(items, rec.ID)
+		revs.Delete(rec.ID)
+	case opReplace:
+		delete(items, rec.ID)
+		revs.Delete(rec.ID)
+		apply(items, revs, walRecord{Op: opPut, Notes: rec.Notes})
+	}
+}
+
+// loadSnapshot читает снимок; отсутствие файла — пустое состояние.
+func loadSnapshot(dir string, items map[string]service.Note, revs *history.History) error {
+	data, err := os.ReadFile(filepath.Join(dir, snapshotFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read snapshot: %w", err)
+	}
+	var snap snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return fmt.Errorf("decode snapshot: %w", err)
+	}
+	for _, nr := range snap.Notes {
+		items[nr.ID] = nr.toNote()
+	}
+	for _, nr := range snap.Revisions {
+		revs.Put(nr.toNote())
+	}
+	return nil
+}
+
+// replay проигрывает WAL поверх состояния и возвращает число целых записей
+// и смещение конца последней из них. Повреждённый хвост отрезается.
+func replay(f *os.File, items map[string]service.Note, revs *history.History) (records int, size int64, err error) {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return 0, 0, err
+	}
+	br := bufio.NewReader(f)
+	for {
+		rec, n, err := readRecord(br)
+		if err == io.EOF {
+			break
+		}
+		if errors.Is(err, errTorn) {
+			if err := f.Truncate(size); err != nil {
+				return 0, 0, fmt.Errorf("truncate torn wal tail: %w", err)
+			}
+			if err := f.Sync(); err != nil {
+				return 0, 0, fmt.Errorf("sync wal: %w", err)
+			}
+			break
+		}
+		apply(items, revs, rec)
+		records++
+		size += n
+	}
+	if _, err := f.Seek(size, io.SeekStart); err != nil {
+		return 0, 0, err
+	}
+	return records, size, nil
+}
+
+// writeSnapshot атомарно заменяет snapshot.json: пишем во временный файл,
+// fsync, rename и fsync каталога.
+func writeSnapshot(dir string, items map[string]service.Note, revs *history.History) error {
+	snap := snapshot{Notes: make([]noteRecord, 0, len(items))}
+	for _, n := range items {
+		snap.Notes = append(snap.Notes, toRecord(n))
+	}
+	for _, n := range revs.All() {
+		snap.Revisions = append(snap.Revisions, toRecord(n))
+	}
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return fmt.Errorf("marshal snapshot: %w", err)
+	}
+
+	tmp := filepath.Join(dir, snapshotFile+".tmp")
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("create snapshot: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return fmt.Errorf("write snapshot: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("sync snapshot: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close snapshot: %w", err)
+	}
+	if err := os.Rename(tmp, filepath.Join(dir, snapshotFile)); err != nil {
+		return fmt.Errorf("rename snapshot: %w", err)
+	}
+	return syncDir(dir)
+}
+
+// syncDir фиксирует на диске изменения каталога (rename/создание файлов).
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
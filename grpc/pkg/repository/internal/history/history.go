@@ -0,0 +1,88 @@
+// Package history — история версий заметок для хранилищ, которые держат всё в памяти
+// (memory и file).
+package history
+
+import (
+	"slices"
+
+	"github.com/verazalayli/go_studying/grpc/pkg/service"
+)
+
+// History — все сохранённые версии каждой заметки по возрастанию номера.
+// Не потокобезопасна: вызывающий держит свою блокировку хранилища.
+type History struct {
+	byID map[string][]service.Note
+}
+
+func New() *History {
+	return &History{byID: make(map[string][]service.Note)}
+}
+
+// Put добавляет версию заметки. Повтор уже записанной версии её заменяет
+// (file проигрывает журнал поверх снимка), более старая версия игнорируется.
+func (h *History) Put(n service.Note) {
+	revs := h.byID[n.ID]
+	if len(revs) > 0 {
+		switch last := revs[len(revs)-1].Version; {
+		case n.Version == last:
+			revs[len(revs)-1] = n
+			return
+		case n.Version < last:
+			i, found := slices.BinarySearchFunc(revs, n.Version, byVersion)
+			if found {
+				revs[i] = n
+			}
+			return
+		}
+	}
+	h.byID[n.ID] = append(revs, n)
+}
+
+// Delete забывает всю историю заметки.
+func (h *History) Delete(id string) {
+	delete(h.byID, id)
+}
+
+// List — версии с номером меньше before (0 — все), от новых к старым, не больше limit
+// (<= 0 — без ограничения).
+func (h *History) List(id string, before int64, limit int) []service.Note {
+	revs := h.byID[id]
+	end := len(revs)
+	if before > 0 {
+		end, _ = slices.BinarySearchFunc(revs, before, byVersion)
+	}
+	out := make([]service.Note, 0, end)
+	for i := end - 1; i >= 0 && (limit <= 0 || len(out) < limit); i-- {
+		out = append(out, revs[i])
+	}
+	return out
+}
+
+// Get — версия version заметки id.
+func (h *History) Get(id string, version int64) (service.Note, bool) {
+	revs := h.byID[id]
+	i, found := slices.BinarySearchFunc(revs, version, byVersion)
+	if !found {
+		return service.Note{}, false
+	}
+	return revs[i], true
+}
+
+// All — все версии всех заметок (для снимка file).
+func (h *History) All() []service.Note {
+	var out []service.Note
+	for _, revs := range h.byID {
+		out = append(out, revs...)
+	}
+	return out
+}
+
+func byVersion(n service.Note, v int64) int {
+	switch {
+	case n.Version < v:
+		return -1
+	case n.Version > v:
+		return 1
+	}
+	return 0
+}
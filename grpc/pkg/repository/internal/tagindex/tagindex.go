@@ -0,0 +1,130 @@
+// Package tagindex — индекс тегов для хранилищ, которые держат все заметки в памяти
+// (memory и file). Без него выборка по тегу перебирала бы все заметки.
+package tagindex
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/verazalayli/go_studying/grpc/pkg/service"
+)
+
+// key — раздел индекса: заметки одного владельца, отдельно живые и из корзины,
+// чтобы ListNotes и ListTrash не смешивались.
+type key struct {
+	owner string
+	trash bool
+}
+
+type entry struct {
+	key  key
+	tags []string
+}
+
+// Index — тег → множество ID заметок в каждом разделе.
+// Не потокобезопасен: вызывающий держит свою блокировку хранилища.
+type Index struct {
+	sets  map[key]map[string]map[string]struct{}
+	notes map[string]entry // что сейчас проиндексировано для ID — чтобы Put мог снять старое
+}
+
+func New() *Index {
+	return &Index{
+		sets:  make(map[key]map[string]map[string]struct{}),
+		notes: make(map[string]entry),
+	}
+}
+
+// Put индексирует заметку, заменяя её прежнюю версию.
+func (x *Index) Put(n service.Note) {
+	x.Delete(n.ID)
+	if len(n.Tags) == 0 {
+		return
+	}
+	k := key{owner: n.OwnerID, trash: n.Deleted()}
+	byTag := x.sets[k]
+	if byTag == nil {
+		byTag = make(map[string]map[string]struct{})
+		x.sets[k] = byTag
+	}
+	for _, t := range n.Tags {
+		ids := byTag[t]
+		if ids == nil {
+			ids = make(map[string]struct{})
+			byTag[t] = ids
+		}
+		ids[n.ID] = struct{}{}
+	}
+	x.notes[n.ID] = entry{key: k, tags: n.Tags}
+}
+
+// Delete убирает заметку из индекса; пустые множества удаляются сразу,
+// чтобы Counts не показывал теги, у которых не осталось заметок.
+func (x *Index) Delete(id string) {
+	e, ok := x.notes[id]
+	if !ok {
+		return
+	}
+	delete(x.notes, id)
+	byTag := x.sets[e.key]
+	for _, t := range e.tags {
+		delete(byTag[t], id)
+		if len(byTag[t]) == 0 {
+			delete(byTag, t)
+		}
+	}
+	if len(byTag) == 0 {
+		delete(x.sets, e.key)
+	}
+}
+
+// Counts — теги живых заметок владельца с их числом, по алфавиту.
+func (x *Index) Counts(owner string) []service.TagCount {
+	byTag := x.sets[key{owner: owner}]
+	out := make([]service.TagCount, 0, len(byTag))
+	for _, t := range slices.Sorted(maps.Keys(byTag)) {
+		out = append(out, service.TagCount{Tag: t, Count: len(byTag[t])})
+	}
+	return out
+}
+
+// Select отбирает из items заметки, подходящие под q (без сортировки и Limit).
+// Если в запросе есть теги, перебираются только заметки из индекса.
+func (x *Index) Select(items map[string]service.Note, q service.ListQuery) []service.Note {
+	if len(q.Tags) == 0 || q.AnyOwner {
+		out := make([]service.Note, 0, len(items))
+		for _, n := range items {
+			if q.Match(n) {
+				out = append(out, n)
+			}
+		}
+		return out
+	}
+	var out []service.Note
+	for id := range x.candidates(q) {
+		if n, ok := items[id]; ok && q.Match(n) {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// candidates — ID заметок с любым из тегов или со всеми (тогда идём по самому
+// маленькому множеству). Остальные условия проверит q.Match.
+func (x *Index) candidates(q service.ListQuery) map[string]struct{} {
+	byTag := x.sets[key{owner: q.OwnerID, trash: q.Trash}]
+	if q.TagMatch == service.TagMatchAll {
+		smallest := byTag[q.Tags[0]]
+		for _, t := range q.Tags[1:] {
+			if len(byTag[t]) < len(smallest) {
+				smallest = byTag[t]
+			}
+		}
+		return smallest
+	}
+	out := make(map[string]struct{})
+	for _, t := range q.Tags {
+		maps.Copy(out, byTag[t])
+	}
+	return out
+}
@@ -1,8 +1,11 @@
 package memory
 
 import (
-	"errors"
+	"context"
+	"github.com/verazalayli/go_studying/grpc/pkg/repository/internal/history"
+	"github.com/verazalayli/go_studying/grpc/pkg/repository/internal/tagindex"
 	"github.com/verazalayli/go_studying/grpc/pkg/service"
+	"slices"
 	"sync"
 )
 
@@ -10,35 +13,162 @@ import (
 type NoteRepo struct {
 	mu    sync.RWMutex
 	items map[string]service.Note
+	tags  *tagindex.Index
+	revs  *history.History
 }
 
 func NewNoteRepo() *NoteRepo {
-	return &NoteRepo{items: make(map[string]service.Note)}
+	return &NoteRepo{items: make(map[string]service.Note), tags: tagindex.New(), revs: history.New()}
 }
 
-func (r *NoteRepo) Save(n service.Note) error {
+func (r *NoteRepo) Save(ctx context.Context, n service.Note) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	r.mu.Lock()
 	defer r.mu.Unlock()
+	cur, ok := r.items[n.ID]
+	if !service.CanSave(cur, ok, n) {
+		return service.ErrVersionConflict
+	}
 	r.items[n.ID] = n
+	r.tags.Put(n)
+	r.revs.Put(n)
+	return nil
+}
+
+func (r *NoteRepo) SaveMany(ctx context.Context, notes []service.Note) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// Сначала проверяем все, потом пишем: пачка сохраняется целиком или никак.
+	// Дубликаты ID внутри пачки проверяются друг против друга.
+	pending := make(map[string]service.Note, len(notes))
+	for _, n := range notes {
+		cur, ok := pending[n.ID]
+		if !ok {
+			cur, ok = r.items[n.ID]
+		}
+		if !service.CanSave(cur, ok, n) {
+			return service.ErrVersionConflict
+		}
+		pending[n.ID] = n
+	}
+	for id, n := range pending {
+		r.items[id] = n
+		r.tags.Put(n)
+	}
+	for _, n := range notes {
+		r.revs.Put(n)
+	}
 	return nil
 }
 
-func (r *NoteRepo) GetByID(id string) (service.Note, error) {
+func (r *NoteRepo) GetByID(ctx context.Context, id string) (service.Note, error) {
+	if err := ctx.Err(); err != nil {
+		return service.Note{}, err
+	}
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 	n, ok := r.items[id]
 	if !ok {
-		return service.Note{}, errors.New("not found")
+		return service.Note{}, service.ErrRecordNotFound
 	}
 	return n, nil
 }
 
-func (r *NoteRepo) List() ([]service.Note, error) {
+// List фильтрует и сортирует заметки в памяти: map не даёт стабильного порядка,
+// поэтому порядок задаёт q.Order (с добивкой по ID).
+func (r *NoteRepo) List(ctx context.Context, q service.ListQuery) ([]service.Note, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	r.mu.RLock()
-	defer r.mu.RUnlock()
-	out := make([]service.Note, 0, len(r.items))
-	for _, n := range r.items {
-		out = append(out, n)
+	out := r.tags.Select(r.items, q)
+	r.mu.RUnlock()
+	slices.SortFunc(out, q.Order.Compare)
+	if q.Limit > 0 && len(out) > q.Limit {
+		out = out[:q.Limit]
 	}
 	return out, nil
 }
+
+// Ping — хранилище в памяти доступно всегда.
+func (r *NoteRepo) Ping(ctx context.Context) error {
+	return ctx.Err()
+}
+
+func (r *NoteRepo) Delete(ctx context.Context, id string, expectedVersion int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cur, ok := r.items[id]
+	if !ok {
+		return service.ErrRecordNotFound
+	}
+	if !service.CanDelete(cur, expectedVersion) {
+		return service.ErrVersionConflict
+	}
+	delete(r.items, id)
+	r.tags.Delete(id)
+	r.revs.Delete(id)
+	return nil
+}
+
+func (r *NoteRepo) Replace(ctx context.Context, id string, expectedVersion int64, states []service.Note) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cur, ok := r.items[id]
+	if !ok {
+		return service.ErrRecordNotFound
+	}
+	if !service.CanDelete(cur, expectedVersion) || !service.CanSaveHistory(id, states) {
+		return service.ErrVersionConflict
+	}
+	r.revs.Delete(id)
+	for _, n := range states {
+		r.revs.Put(n)
+	}
+	n := states[len(states)-1]
+	r.items[id] = n
+	r.tags.Put(n)
+	return nil
+}
+
+func (r *NoteRepo) ListRevisions(ctx context.Context, id string, before int64, limit int) ([]service.Note, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.revs.List(id, before, limit), nil
+}
+
+func (r *NoteRepo) GetRevision(ctx context.Context, id string, version int64) (service.Note, error) {
+	if err := ctx.Err(); err != nil {
+		return service.Note{}, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	n, ok := r.revs.Get(id, version)
+	if !ok {
+		return service.Note{}, service.ErrRecordNotFound
+	}
+	return n, nil
+}
+
+func (r *NoteRepo) ListTags(ctx context.Context, owner string) ([]service.TagCount, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.tags.Counts(owner), nil
+}
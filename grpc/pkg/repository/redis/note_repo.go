@@ -0,0 +1,712 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/verazalayli/go_studying/grpc/pkg/service"
+)
+
+/*
+	NoteRepo — хранилище заметок в Redis (адаптер к порту service.NoteRepository).
+	Устроено так же, как redis/pkg/repository: JSON-значения под ключами с префиксом
+	и функциональные опции.
+
+	Ключи (prefix по умолчанию "notes:"):
+	  <prefix>note:<id>                  — заметка в JSON
+	  <prefix>owner:<owner>:by_created   — заметки владельца, упорядоченные по CreatedAt
+	  <prefix>owner:<owner>:by_title     — заметки владельца, упорядоченные по Title
+	  <prefix>by_created, <prefix>by_title — те же индексы по всем заметкам
+	                                       (для служебных выборок с ListQuery.AnyOwner)
+	  <prefix>owner:<owner>:tag:<tag>       — множество ID живых заметок владельца с тегом
+	  <prefix>owner:<owner>:trash_tag:<tag> — то же для заметок в корзине
+	  <prefix>owner:<owner>:tags            — имена тегов, которые встречались у живых
+	                                          заметок (пустые множества ListTags пропускает)
+	  <prefix>revisions:<id>                — история версий: JSON заметки, score = версия
+
+	Сегменты <owner>, <id> и <tag> экранируются (keySegment): ':' и '%' заменяются
+	на %3A и %25. Иначе владелец "alice:tag:go" получил бы ключи, которые совпадают
+	с ключами тегов владельца "alice". Обычные ID (UUID) и владельцы без ':' не меняются,
+	поэтому ключи, записанные до экранирования, читаются как прежде.
+
+	WithTTL задаёт срок жизни заметки: ключ заметки и её история истекают через TTL
+	после последней записи. Индексы не истекают — элементы истёкших заметок
+	убираются из них лениво: List и ListTags пропускают их и удаляют из прочитанных индексов.
+
+	Заметки из корзины остаются в тех же индексах: List отсеивает их
+	(или, для корзины, все остальные) через ListQuery.Match. Выборка по тегам идёт
+	не по индексу сортировки, а через SUNION/SINTER множеств тегов.
+
+	Во всех индексах у элементов score = 0, а порядок задаёт сам элемент:
+	"<ключ сортировки>\x00<id>". Redis сравнивает такие элементы побайтово
+	(ZRANGEBYLEX), то есть ровно как service.ListOrder.Compare. Score (float64)
+	не подошёл бы: в нём не помещаются наносекунды CreatedAt.
+
+	Запись заметки и её индексов идёт в MULTI/EXEC под WATCH, чтобы индексы
+	не разъехались с данными при конкурентных изменениях. Версию заметки
+	проверяем там же, между WATCH и EXEC: если её изменили после проверки,
+	EXEC не выполнится и транзакция повторится уже с новой версией.
+*/
+
+// listBatch — сколько элементов индекса читаем за раз в List.
+const listBatch = 100
+
+// maxTxRetries — сколько раз повторяем транзакцию, если WATCH-ключ изменили.
+const maxTxRetries = 10
+
+type NoteRepo struct {
+	rdb       *redis.Client
+	keyPrefix string
+	ttl       time.Duration // 0 — заметки не истекают
+}
+
+// Option — функциональная опция репозитория.
+type Option func(*NoteRepo)
+
+// WithKeyPrefix задаёт префикс всех ключей (по умолчанию "notes:").
+func WithKeyPrefix(prefix string) Option {
+	return func(r *NoteRepo) { r.keyPrefix = prefix }
+}
+
+// WithTTL задаёт срок жизни заметки с момента последней записи (по умолчанию 0 — бессрочно).
+// Так хранилище можно использовать для временных заметок: истёкшая заметка пропадает целиком, с историей.
+func WithTTL(ttl time.Duration) Option {
+	return func(r *NoteRepo) { r.ttl = ttl }
+}
+
+func NewNoteRepo(rdb *redis.Client, opts ...Option) *NoteRepo {
+	r := &NoteRepo{rdb: rdb, keyPrefix: "notes:"}
+	for _, o := range opts {
+		o(r)
+	}
+	return r
+}
+
+// noteRecord — формат заметки в Redis.
+type noteRecord struct {
+	ID        string    `json:"id"`
+	OwnerID   string    `json:"owner_id,omitempty"`
+	Title     string    `json:"title"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Version   int64     `json:"version,omitempty"`
+	DeletedAt time.Time `json:"deleted_at,omitzero"`
+	Tags      []string  `json:"tags,omitempty"`
+
+	Attachments []attachmentRecord `json:"attachments,omitempty"`
+}
+
+// attachmentRecord — формат вложения в JSON заметки.
+type attachmentRecord struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	SHA256      string    `json:"sha256"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func toAttachmentRecords(as []service.Attachment) []attachmentRecord {
+	if len(as) == 0 {
+		return nil
+	}
+	out := make([]attachmentRecord, len(as))
+	for i, a := range as {
+		out[i] = attachmentRecord(a)
+	}
+	return out
+}
+
+func toAttachments(rs []attachmentRecord) []service.Attachment {
+	if len(rs) == 0 {
+		return nil
+	}
+	out := make([]service.Attachment, len(rs))
+	for i, r := range rs {
+		out[i] = service.Attachment(r)
+	}
+	return out
+}
+
+func toRecord(n service.Note) noteRecord {
+	return noteRecord{ID: n.ID, OwnerID: n.OwnerID, Title: n.Title, Content: n.Content, CreatedAt: n.CreatedAt, UpdatedAt: n.UpdatedAt, Version: n.Version, DeletedAt: n.DeletedAt, Tags: n.Tags, Attachments: toAttachmentRecords(n.Attachments)}
+}
+
+// toNote: записи, сделанные до появления версий, считаются версией 1.
+func (rec noteRecord) toNote() service.Note {
+	v := rec.Version
+	if v == 0 {
+		v = 1
+	}
+	return service.Note{ID: rec.ID, OwnerID: rec.OwnerID, Title: rec.Title, Content: rec.Content, CreatedAt: rec.CreatedAt, UpdatedAt: rec.UpdatedAt, Version: v, DeletedAt: rec.DeletedAt, Tags: rec.Tags, Attachments: toAttachments(rec.Attachments)}
+}
+
+// Имена индексов.
+const (
+	byCreated = "by_created"
+	byTitle   = "by_title"
+)
+
+// keySegmentEscaper экранирует разделитель ':' и сам символ экранирования.
+var keySegmentEscaper = strings.NewReplacer("%", "%25", ":", "%3A")
+
+// keySegment — значение (владелец, ID, тег) как часть ключа: без ':', чтобы
+// значения с двоеточием не давали ключи, совпадающие с чужими.
+func keySegment(s string) string { return keySegmentEscaper.Replace(s) }
+
+func (r *NoteRepo) noteKey(id string) string { return r.keyPrefix + "note:" + keySegment(id) }
+
+func (r *NoteRepo) revisionsKey(id string) string { return r.keyPrefix + "revisions:" + keySegment(id) }
+
+func (r *NoteRepo) ownerKey(owner, rest string) string {
+	return r.keyPrefix + "owner:" + keySegment(owner) + ":" + rest
+}
+
+// indexKeys — ключи индекса name, в которых числится заметка владельца owner:
+// общий и владельца.
+func (r *NoteRepo) indexKeys(owner, name string) [2]string {
+	return [2]string{r.keyPrefix + name, r.ownerKey(owner, name)}
+}
+
+// indexKey — индекс, по которому идёт List.
+func (r *NoteRepo) indexKey(q service.ListQuery, name string) string {
+	keys := r.indexKeys(q.OwnerID, name)
+	if q.AnyOwner {
+		return keys[0]
+	}
+	return keys[1]
+}
+
+// tagKey — множество заметок владельца с тегом (живых или из корзины).
+func (r *NoteRepo) tagKey(owner string, trash bool, tag string) string {
+	if trash {
+		return r.ownerKey(owner, "trash_tag:"+keySegment(tag))
+	}
+	return r.ownerKey(owner, "tag:"+keySegment(tag))
+}
+
+func (r *NoteRepo) tagNamesKey(owner string) string {
+	return r.ownerKey(owner, "tags")
+}
+
+// unindex/index убирают заметку из всех её индексов и добавляют в них.
+func (r *NoteRepo) unindex(ctx context.Context, p redis.Pipeliner, n service.Note) {
+	for _, k := range r.indexKeys(n.OwnerID, byCreated) {
+		p.ZRem(ctx, k, createdMember(n.CreatedAt, n.ID))
+	}
+	for _, k := range r.indexKeys(n.OwnerID, byTitle) {
+		p.ZRem(ctx, k, titleMember(n.Title, n.ID))
+	}
+	for _, t := range n.Tags {
+		p.SRem(ctx, r.tagKey(n.OwnerID, n.Deleted(), t), n.ID)
+	}
+}
+
+func (r *NoteRepo) index(ctx context.Context, p redis.Pipeliner, n service.Note) {
+	for _, k := range r.indexKeys(n.OwnerID, byCreated) {
+		p.ZAdd(ctx, k, redis.Z{Member: createdMember(n.CreatedAt, n.ID)})
+	}
+	for _, k := range r.indexKeys(n.OwnerID, byTitle) {
+		p.ZAdd(ctx, k, redis.Z{Member: titleMember(n.Title, n.ID)})
+	}
+	for _, t := range n.Tags {
+		p.SAdd(ctx, r.tagKey(n.OwnerID, n.Deleted(), t), n.ID)
+		if !n.Deleted() {
+			p.SAdd(ctx, r.tagNamesKey(n.OwnerID), t)
+		}
+	}
+}
+
+// createdMember — элемент индекса by_created. Время — 20 цифр с ведущими нулями,
+// чтобы побайтовое сравнение совпадало с числовым.
+func createdMember(t time.Time, id string) string {
+	return fmt.Sprintf("%020d\x00%s", t.UnixNano(), id)
+}
+
+func titleMember(title, id string) string {
+	return title + "\x00" + id
+}
+
+// Ping проверяет соединение с Redis.
+func (r *NoteRepo) Ping(ctx context.Context) error {
+	return r.rdb.Ping(ctx).Err()
+}
+
+func (r *NoteRepo) Save(ctx context.Context, n service.Note) error {
+	return r.SaveMany(ctx, []service.Note{n})
+}
+
+// SaveMany сохраняет пачку атомарно: одна транзакция MULTI/EXEC на все заметки.
+func (r *NoteRepo) SaveMany(ctx context.Context, notes []service.Note) error {
+	keys := make([]string, len(notes))
+	for i, n := range notes {
+		keys[i] = r.noteKey(n.ID)
+	}
+	return r.retryTx(ctx, func(tx *redis.Tx) error {
+		old, err := r.getMany(ctx, tx, keys)
+		if err != nil {
+			return err
+		}
+		// pending — что будет лежать под ID после предыдущих заметок пачки (на случай дубликатов).
+		pending := make(map[string]service.Note, len(notes))
+		for i, n := range notes {
+			cur, ok := pending[n.ID]
+			if !ok {
+				cur, ok = old[i]
+			}
+			if !service.CanSave(cur, ok, n) {
+				return service.ErrVersionConflict
+			}
+			pending[n.ID] = n
+		}
+		_, err = tx.TxPipelined(ctx, func(p redis.Pipeliner) error {
+			for i, n := range notes {
+				if prev, ok := old[i]; ok {
+					r.unindex(ctx, p, prev)
+				}
+				if err := r.put(ctx, p, n); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		return err
+	}, keys...)
+}
+
+// put записывает заметку, её индексы и версию в истории (прежние индексы снимает вызывающий).
+func (r *NoteRepo) put(ctx context.Context, p redis.Pipeliner, n service.Note) error {
+	data, err := json.Marshal(toRecord(n))
+	if err != nil {
+		return fmt.Errorf("marshal note: %w", err)
+	}
+	p.Set(ctx, r.noteKey(n.ID), data, r.ttl)
+	r.index(ctx, p, n)
+	// Версия в истории одна: повтор той же версии заменяет запись.
+	v := strconv.FormatInt(n.Version, 10)
+	p.ZRemRangeByScore(ctx, r.revisionsKey(n.ID), v, v)
+	p.ZAdd(ctx, r.revisionsKey(n.ID), redis.Z{Score: float64(n.Version), Member: data})
+	if r.ttl > 0 {
+		p.Expire(ctx, r.revisionsKey(n.ID), r.ttl)
+	}
+	return nil
+}
+
+func (r *NoteRepo) GetByID(ctx context.Context, id string) (service.Note, error) {
+	val, err := r.rdb.Get(ctx, r.noteKey(id)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return service.Note{}, service.ErrRecordNotFound
+		}
+		return service.Note{}, fmt.Errorf("redis get: %w", err)
+	}
+	var rec noteRecord
+	if err := json.Unmarshal([]byte(val), &rec); err != nil {
+		return service.Note{}, fmt.Errorf("unmarshal note: %w", err)
+	}
+	return rec.toNote(), nil
+}
+
+func (r *NoteRepo) Delete(ctx context.Context, id string, expectedVersion int64) error {
+	key := r.noteKey(id)
+	return r.retryTx(ctx, func(tx *redis.Tx) error {
+		old, err := r.getMany(ctx, tx, []string{key})
+		if err != nil {
+			return err
+		}
+		prev, ok := old[0]
+		if !ok {
+			return service.ErrRecordNotFound
+		}
+		if !service.CanDelete(prev, expectedVersion) {
+			return service.ErrVersionConflict
+		}
+		_, err = tx.TxPipelined(ctx, func(p redis.Pipeliner) error {
+			p.Del(ctx, key, r.revisionsKey(id))
+			r.unindex(ctx, p, prev)
+			return nil
+		})
+		return err
+	}, key)
+}
+
+// Replace — удаление и запись новой истории в одной транзакции MULTI/EXEC.
+func (r *NoteRepo) Replace(ctx context.Context, id string, expectedVersion int64, states []service.Note) error {
+	if !service.CanSaveHistory(id, states) {
+		return service.ErrVersionConflict
+	}
+	key := r.noteKey(id)
+	return r.retryTx(ctx, func(tx *redis.Tx) error {
+		old, err := r.getMany(ctx, tx, []string{key})
+		if err != nil {
+			return err
+		}
+		prev, ok := old[0]
+		if !ok {
+			return service.ErrRecordNotFound
+		}
+		if !service.CanDelete(prev, expectedVersion) {
+			return service.ErrVersionConflict
+		}
+		_, err = tx.TxPipelined(ctx, func(p redis.Pipeliner) error {
+			p.Del(ctx, key, r.revisionsKey(id))
+			r.unindex(ctx, p, prev)
+			for _, n := range states {
+				if n.Version > 1 {
+					r.unindex(ctx, p, states[n.Version-2])
+				}
+				if err := r.put(ctx, p, n); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		return err
+	}, key)
+}
+
+// List идёт по нужному индексу пачками от курсора и догружает заметки через MGET.
+// Для сортировки по title фильтр по префиксу сужает сам диапазон индекса,
+// для сортировки по created_at — проверяется на каждой заметке.
+func (r *NoteRepo) List(ctx context.Context, q service.ListQuery) ([]service.Note, error) {
+	if len(q.Tags) > 0 && !q.AnyOwner {
+		return r.listByTags(ctx, q)
+	}
+	key, lo, hi := r.listRange(q)
+
+	var out []service.Note
+	for {
+		var (
+			members []string
+			err     error
+		)
+		rng := &redis.ZRangeBy{Min: lo, Max: hi, Count: listBatch}
+		if q.Order.Desc {
+			members, err = r.rdb.ZRevRangeByLex(ctx, key, rng).Result()
+		} else {
+			members, err = r.rdb.ZRangeByLex(ctx, key, rng).Result()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("redis zrangebylex: %w", err)
+		}
+		if len(members) == 0 {
+			return out, nil
+		}
+
+		keys := make([]string, len(members))
+		for i, m := range members {
+			keys[i] = r.noteKey(memberID(m))
+		}
+		notes, err := r.getMany(ctx, r.rdb, keys)
+		if err != nil {
+			return nil, err
+		}
+		var expired []any
+		for i, m := range members {
+			if _, ok := notes[i]; !ok {
+				expired = append(expired, m)
+			}
+		}
+		if err := r.prune(ctx, key, expired, r.rdb.ZRem); err != nil {
+			return nil, err
+		}
+		for i := range members {
+			n, ok := notes[i]
+			if !ok || !q.Match(n) {
+				continue
+			}
+			out = append(out, n)
+			if q.Limit > 0 && len(out) == q.Limit {
+				return out, nil
+			}
+		}
+		if len(members) < listBatch {
+			return out, nil
+		}
+
+		// Следующая пачка начинается строго после последнего просмотренного элемента.
+		last := "(" + members[len(members)-1]
+		if q.Order.Desc {
+			hi = last
+		} else {
+			lo = last
+		}
+	}
+}
+
+// listByTags берёт кандидатов из множеств тегов (объединение или пересечение),
+// остальные условия, порядок и Limit применяет в памяти.
+func (r *NoteRepo) listByTags(ctx context.Context, q service.ListQuery) ([]service.Note, error) {
+	keys := make([]string, len(q.Tags))
+	for i, t := range q.Tags {
+		keys[i] = r.tagKey(q.OwnerID, q.Trash, t)
+	}
+	var (
+		ids []string
+		err error
+	)
+	if q.TagMatch == service.TagMatchAll {
+		ids, err = r.rdb.SInter(ctx, keys...).Result()
+	} else {
+		ids, err = r.rdb.SUnion(ctx, keys...).Result()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("redis tag sets: %w", err)
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	noteKeys := make([]string, len(ids))
+	for i, id := range ids {
+		noteKeys[i] = r.noteKey(id)
+	}
+	notes, err := r.getMany(ctx, r.rdb, noteKeys)
+	if err != nil {
+		return nil, err
+	}
+	var expired []any
+	for i, id := range ids {
+		if _, ok := notes[i]; !ok {
+			expired = append(expired, id)
+		}
+	}
+	for _, k := range keys {
+		if err := r.prune(ctx, k, expired, r.rdb.SRem); err != nil {
+			return nil, err
+		}
+	}
+	out := make([]service.Note, 0, len(notes))
+	for _, n := range notes {
+		if q.Match(n) {
+			out = append(out, n)
+		}
+	}
+	slices.SortFunc(out, q.Order.Compare)
+	if q.Limit > 0 && len(out) > q.Limit {
+		out = out[:q.Limit]
+	}
+	return out, nil
+}
+
+func (r *NoteRepo) ListRevisions(ctx context.Context, id string, before int64, limit int) ([]service.Note, error) {
+	rng := &redis.ZRangeBy{Min: "-inf", Max: "+inf"}
+	if before > 0 {
+		rng.Max = "(" + strconv.FormatInt(before, 10)
+	}
+	if limit > 0 {
+		rng.Count = int64(limit)
+	}
+	vals, err := r.rdb.ZRevRangeByScore(ctx, r.revisionsKey(id), rng).Result()
+	if err != nil {
+		return nil, fmt.Errorf("redis zrevrangebyscore: %w", err)
+	}
+	return decodeRevisions(vals)
+}
+
+func (r *NoteRepo) GetRevision(ctx context.Context, id string, version int64) (service.Note, error) {
+	v := strconv.FormatInt(version, 10)
+	vals, err := r.rdb.ZRangeByScore(ctx, r.revisionsKey(id), &redis.ZRangeBy{Min: v, Max: v}).Result()
+	if err != nil {
+		return service.Note{}, fmt.Errorf("redis zrangebyscore: %w", err)
+	}
+	revs, err := decodeRevisions(vals)
+	if err != nil {
+		return service.Note{}, err
+	}
+	if len(revs) == 0 {
+		return service.Note{}, service.ErrRecordNotFound
+	}
+	return revs[0], nil
+}
+
+func decodeRevisions(vals []string) ([]service.Note, error) {
+	out := make([]service.Note, 0, len(vals))
+	for _, s := range vals {
+		var rec noteRecord
+		if err := json.Unmarshal([]byte(s), &rec); err != nil {
+			return nil, fmt.Errorf("unmarshal revision: %w", err)
+		}
+		out = append(out, rec.toNote())
+	}
+	return out, nil
+}
+
+// ListTags читает имена тегов владельца и размеры их множеств одним конвейером.
+func (r *NoteRepo) ListTags(ctx context.Context, owner string) ([]service.TagCount, error) {
+	names, err := r.rdb.SMembers(ctx, r.tagNamesKey(owner)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("redis smembers: %w", err)
+	}
+	slices.Sort(names)
+	if r.ttl > 0 {
+		if err := r.pruneTags(ctx, owner, names); err != nil {
+			return nil, err
+		}
+	}
+	cmds := make([]*redis.IntCmd, len(names))
+	if _, err := r.rdb.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for i, t := range names {
+			cmds[i] = p.SCard(ctx, r.tagKey(owner, false, t))
+		}
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("redis scard: %w", err)
+	}
+	out := []service.TagCount{}
+	for i, t := range names {
+		if c := cmds[i].Val(); c > 0 {
+			out = append(out, service.TagCount{Tag: t, Count: int(c)})
+		}
+	}
+	return out, nil
+}
+
+// prune удаляет из индекса key элементы заметок, которых уже нет (истёк TTL).
+// Без TTL таких элементов не бывает, и prune ничего не делает.
+func (r *NoteRepo) prune(ctx context.Context, key string, members []any, rem func(context.Context, string, ...any) *redis.IntCmd) error {
+	if r.ttl <= 0 || len(members) == 0 {
+		return nil
+	}
+	if err := rem(ctx, key, members...).Err(); err != nil {
+		return fmt.Errorf("redis prune %s: %w", key, err)
+	}
+	return nil
+}
+
+// pruneTags убирает из множеств тегов владельца истёкшие заметки, чтобы ListTags
+// не считал их. Дорого (читает все множества), поэтому вызывается только с TTL.
+func (r *NoteRepo) pruneTags(ctx context.Context, owner string, names []string) error {
+	for _, t := range names {
+		key := r.tagKey(owner, false, t)
+		ids, err := r.rdb.SMembers(ctx, key).Result()
+		if err != nil {
+			return fmt.Errorf("redis smembers: %w", err)
+		}
+		if len(ids) == 0 {
+			continue
+		}
+		keys := make([]string, len(ids))
+		for i, id := range ids {
+			keys[i] = r.noteKey(id)
+		}
+		vals, err := r.rdb.MGet(ctx, keys...).Result()
+		if err != nil {
+			return fmt.Errorf("redis mget: %w", err)
+		}
+		var expired []any
+		for i, v := range vals {
+			if v == nil {
+				expired = append(expired, ids[i])
+			}
+		}
+		if err := r.prune(ctx, key, expired, r.rdb.SRem); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listRange выбирает индекс и границы ZRANGEBYLEX для запроса.
+func (r *NoteRepo) listRange(q service.ListQuery) (key, lo, hi string) {
+	lo, hi = "-", "+"
+	if q.Order.Field == service.SortByTitle {
+		key = r.indexKey(q, byTitle)
+		if q.TitlePrefix != "" {
+			lo = "[" + q.TitlePrefix
+			if upper, ok := prefixUpperBound(q.TitlePrefix); ok {
+				hi = "(" + upper
+			}
+		}
+		if q.After != nil {
+			bound := "(" + titleMember(q.After.Title, q.After.ID)
+			if q.Order.Desc {
+				hi = bound
+			} else {
+				lo = bound
+			}
+		}
+		return key, lo, hi
+	}
+
+	key = r.indexKey(q, byCreated)
+	if q.After != nil {
+		bound := "(" + createdMember(q.After.CreatedAt, q.After.ID)
+		if q.Order.Desc {
+			hi = bound
+		} else {
+			lo = bound
+		}
+	}
+	return key, lo, hi
+}
+
+// getMany читает заметки по ключам; отсутствующих ключей нет в результате.
+func (r *NoteRepo) getMany(ctx context.Context, c redis.Cmdable, keys []string) (map[int]service.Note, error) {
+	vals, err := c.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("redis mget: %w", err)
+	}
+	out := make(map[int]service.Note, len(vals))
+	for i, v := range vals {
+		s, ok := v.(string)
+		if !ok {
+			continue
+		}
+		var rec noteRecord
+		if err := json.Unmarshal([]byte(s), &rec); err != nil {
+			return nil, fmt.Errorf("unmarshal note: %w", err)
+		}
+		out[i] = rec.toNote()
+	}
+	return out, nil
+}
+
+// retryTx выполняет оптимистичную транзакцию WATCH/MULTI/EXEC и повторяет её,
+// если кто-то успел изменить наблюдаемые ключи.
+func (r *NoteRepo) retryTx(ctx context.Context, fn func(*redis.Tx) error, keys ...string) error {
+	for i := 0; i < maxTxRetries; i++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		err := r.rdb.Watch(ctx, fn, keys...)
+		if errors.Is(err, redis.TxFailedErr) {
+			continue
+		}
+		if err != nil && !errors.Is(err, service.ErrRecordNotFound) && !errors.Is(err, service.ErrVersionConflict) {
+			return fmt.Errorf("redis tx: %w", err)
+		}
+		return err
+	}
+	return fmt.Errorf("redis tx: too much contention on %v", keys)
+}
+
+// memberID достаёт id из элемента индекса "<ключ>\x00<id>".
+func memberID(m string) string {
+	for i := len(m) - 1; i >= 0; i-- {
+		if m[i] == 0 {
+			return m[i+1:]
+		}
+	}
+	return m
+}
+
+// prefixUpperBound — наименьшая строка, которая больше всех строк с данным префиксом.
+func prefixUpperBound(p string) (string, bool) {
+	b := []byte(p)
+	for i := len(b) - 1; i >= 0; i-- {
+		if b[i] < 0xff {
+			b[i]++
+			return string(b[:i+1]), true
+		}
+	}
+	return "", false
+}
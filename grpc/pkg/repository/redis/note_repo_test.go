@@ -0,0 +1,350 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/verazalayli/go_studying/grpc/pkg/service"
+)
+
+// newRepo — репозиторий поверх miniredis (in-process Redis), свой на каждый тест.
+func newRepo(t *testing.T, opts ...Option) (*NoteRepo, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+	return NewNoteRepo(rdb, opts...), mr
+}
+
+var t0 = time.Date(2025, 1, 2, 3, 4, 5, 123456789, time.UTC)
+
+func note(id, owner, title string, created time.Time, tags ...string) service.Note {
+	return service.Note{ID: id, OwnerID: owner, Title: title, Content: "text of " + id,
+		CreatedAt: created, UpdatedAt: created, Version: 1, Tags: tags}
+}
+
+func ids(notes []service.Note) []string {
+	out := make([]string, len(notes))
+	for i, n := range notes {
+		out[i] = n.ID
+	}
+	return out
+}
+
+func TestKeyPrefix(t *testing.T) {
+	ctx := context.Background()
+	r, mr := newRepo(t, WithKeyPrefix("test:"))
+	if err := r.Save(ctx, note("a", "alice", "A", t0, "go")); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"test:by_created", "test:by_title", "test:note:a",
+		"test:owner:alice:by_created", "test:owner:alice:by_title",
+		"test:owner:alice:tag:go", "test:owner:alice:tags", "test:revisions:a",
+	}
+	if got := mr.Keys(); !slices.Equal(got, want) {
+		t.Errorf("keys = %v, want %v", got, want)
+	}
+	if ttl := mr.TTL("test:note:a"); ttl != 0 {
+		t.Errorf("TTL without WithTTL = %v, want none", ttl)
+	}
+	if n, err := r.GetByID(ctx, "a"); err != nil || n.Title != "A" {
+		t.Errorf("GetByID = %+v, %v", n, err)
+	}
+}
+
+func TestKeySegmentsEscaped(t *testing.T) {
+	ctx := context.Background()
+	r, mr := newRepo(t)
+	// Без экранирования множество тегов "alice" с тегом "go:tags" и список имён
+	// тегов владельца "alice:tag:go" — один и тот же ключ.
+	err := r.SaveMany(ctx, []service.Note{
+		note("a", "alice", "A", t0, "go:tags"),
+		note("b", "alice:tag:go", "B", t0, "x"),
+		note("c:1", "bob", "C", t0),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for owner, want := range map[string][]service.TagCount{
+		"alice":        {{Tag: "go:tags", Count: 1}},
+		"alice:tag:go": {{Tag: "x", Count: 1}},
+	} {
+		got, err := r.ListTags(ctx, owner)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("ListTags(%q) = %v, want %v", owner, got, want)
+		}
+	}
+	for _, k := range []string{"notes:owner:alice:tag:go%3Atags", "notes:owner:alice%3Atag%3Ago:tags", "notes:note:c%3A1"} {
+		if !mr.Exists(k) {
+			t.Errorf("key %q does not exist; keys: %v", k, mr.Keys())
+		}
+	}
+	if n, err := r.GetByID(ctx, "c:1"); err != nil || n.ID != "c:1" {
+		t.Errorf("GetByID(c:1) = %+v, %v", n, err)
+	}
+	notes, err := r.List(ctx, service.ListQuery{OwnerID: "alice:tag:go"})
+	if err != nil || !slices.Equal(ids(notes), []string{"b"}) {
+		t.Errorf("List(alice:tag:go) = %v, %v; want [b]", ids(notes), err)
+	}
+}
+
+func TestTTL(t *testing.T) {
+	ctx := context.Background()
+	r, mr := newRepo(t, WithTTL(time.Hour))
+	if err := r.Save(ctx, note("a", "alice", "A", t0, "go")); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Save(ctx, note("b", "alice", "B", t0.Add(time.Second), "go")); err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range []string{"notes:note:a", "notes:revisions:a"} {
+		if ttl := mr.TTL(k); ttl != time.Hour {
+			t.Errorf("TTL(%s) = %v, want 1h", k, ttl)
+		}
+	}
+
+	// Запись продлевает срок: "b" изменена за полчаса до того, как истекла бы "a".
+	mr.FastForward(30 * time.Minute)
+	b := note("b", "alice", "B2", t0.Add(time.Second), "go")
+	b.Version = 2
+	if err := r.Save(ctx, b); err != nil {
+		t.Fatal(err)
+	}
+	mr.FastForward(40 * time.Minute)
+
+	if _, err := r.GetByID(ctx, "a"); !errors.Is(err, service.ErrRecordNotFound) {
+		t.Errorf("GetByID of an expired note: err = %v, want ErrRecordNotFound", err)
+	}
+	if revs, err := r.ListRevisions(ctx, "a", 0, 0); err != nil || len(revs) != 0 {
+		t.Errorf("revisions of an expired note = %v, %v; want none", revs, err)
+	}
+	if notes, err := r.List(ctx, service.ListQuery{OwnerID: "alice"}); err != nil || !slices.Equal(ids(notes), []string{"b"}) {
+		t.Errorf("List = %v, %v; want [b]", ids(notes), err)
+	}
+	if notes, err := r.List(ctx, service.ListQuery{OwnerID: "alice", Tags: []string{"go"}}); err != nil || !slices.Equal(ids(notes), []string{"b"}) {
+		t.Errorf("List by tag = %v, %v; want [b]", ids(notes), err)
+	}
+	if tags, err := r.ListTags(ctx, "alice"); err != nil || !slices.Equal(tags, []service.TagCount{{Tag: "go", Count: 1}}) {
+		t.Errorf("ListTags = %v, %v; want [go 1]", tags, err)
+	}
+	// Прочитанные индексы почищены от истёкшей заметки.
+	if members, _ := mr.ZMembers("notes:owner:alice:by_created"); len(members) != 1 {
+		t.Errorf("by_created still has %d members, want 1", len(members))
+	}
+	if members, _ := mr.Members("notes:owner:alice:tag:go"); !slices.Equal(members, []string{"b"}) {
+		t.Errorf("tag set = %v, want [b]", members)
+	}
+}
+
+func TestListPagination(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newRepo(t)
+	// Больше listBatch заметок, тройки с одинаковыми временем и заголовком
+	// (порядок в них решает id), вперемешку с чужими и удалёнными — чтобы
+	// фильтр и курсор работали через границы пачек индекса.
+	var all, batch []service.Note
+	for i := range 250 {
+		n := note(fmt.Sprintf("n%03d", 249-i), "alice", fmt.Sprintf("title %02d", i/3), t0.Add(time.Duration(i/3)*time.Second))
+		switch i % 5 {
+		case 1:
+			n.OwnerID = "bob"
+		case 3:
+			n.DeletedAt = t0
+		default:
+			all = append(all, n)
+		}
+		batch = append(batch, n)
+	}
+	if err := r.SaveMany(ctx, batch); err != nil {
+		t.Fatal(err)
+	}
+
+	orders := []service.ListOrder{
+		{Field: service.SortByCreatedAt},
+		{Field: service.SortByCreatedAt, Desc: true},
+		{Field: service.SortByTitle},
+		{Field: service.SortByTitle, Desc: true},
+	}
+	for _, order := range orders {
+		t.Run(order.String(), func(t *testing.T) {
+			want := slices.Clone(all)
+			slices.SortFunc(want, order.Compare)
+
+			var got []service.Note
+			q := service.ListQuery{OwnerID: "alice", Order: order, Limit: 40}
+			for page := 0; ; page++ {
+				if page > len(all) {
+					t.Fatal("pagination does not end")
+				}
+				notes, err := r.List(ctx, q)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, notes...)
+				if len(notes) < q.Limit {
+					break
+				}
+				q.After = service.CursorOf(notes[len(notes)-1])
+			}
+			if !slices.Equal(ids(got), ids(want)) {
+				t.Errorf("pages = %v\nwant %v", ids(got), ids(want))
+			}
+		})
+	}
+
+	notes, err := r.List(ctx, service.ListQuery{OwnerID: "alice", Order: service.ListOrder{Field: service.SortByTitle}, TitlePrefix: "title 1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range notes {
+		if !strings.HasPrefix(n.Title, "title 1") {
+			t.Errorf("title prefix filter returned %q", n.Title)
+		}
+	}
+	if len(notes) != 18 { // "title 10".."title 19": 30 заметок, 3 из 5 — живые alice
+		t.Errorf("title prefix: %d notes, want 18", len(notes))
+	}
+}
+
+func TestVersionConflict(t *testing.T) {
+	ctx := context.Background()
+	r, mr := newRepo(t)
+	n := note("a", "alice", "A", t0)
+	if err := r.Save(ctx, n); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Save(ctx, n); !errors.Is(err, service.ErrVersionConflict) {
+		t.Errorf("second insert of version 1: err = %v, want ErrVersionConflict", err)
+	}
+	if err := r.Delete(ctx, "a", 2); !errors.Is(err, service.ErrVersionConflict) {
+		t.Errorf("Delete at a wrong version: err = %v, want ErrVersionConflict", err)
+	}
+	if err := r.Delete(ctx, "missing", 0); !errors.Is(err, service.ErrRecordNotFound) {
+		t.Errorf("Delete of a missing note: err = %v, want ErrRecordNotFound", err)
+	}
+
+	// Параллельные записи одной версии: WATCH пропускает ровно одну, остальные
+	// после повтора транзакции видят новую версию и получают конфликт.
+	const writers = 8
+	var (
+		wg   sync.WaitGroup
+		errs = make([]error, writers)
+	)
+	for i := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			next := n
+			next.Version, next.Title = 2, fmt.Sprintf("A by %d", i)
+			errs[i] = r.Save(ctx, next)
+		}()
+	}
+	wg.Wait()
+	won := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			won++
+		case !errors.Is(err, service.ErrVersionConflict):
+			t.Errorf("concurrent Save: unexpected error %v", err)
+		}
+	}
+	if won != 1 {
+		t.Fatalf("%d concurrent writers succeeded, want exactly 1", won)
+	}
+	// Индексы не разъехались с данными: в by_title ровно один элемент, и он — текущий заголовок.
+	cur, err := r.GetByID(ctx, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	members, _ := mr.ZMembers("notes:owner:alice:by_title")
+	if !slices.Equal(members, []string{titleMember(cur.Title, "a")}) {
+		t.Errorf("by_title = %q, want only %q", members, titleMember(cur.Title, "a"))
+	}
+}
+
+func TestTagsAndRevisions(t *testing.T) {
+	ctx := context.Background()
+	r, mr := newRepo(t)
+	n := note("a", "alice", "A", t0, "go", "grpc")
+	if err := r.Save(ctx, n); err != nil {
+		t.Fatal(err)
+	}
+	n.Version, n.Title, n.Tags = 2, "A2", []string{"go"}
+	if err := r.Save(ctx, n); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Save(ctx, note("b", "alice", "B", t0.Add(time.Second), "grpc")); err != nil {
+		t.Fatal(err)
+	}
+
+	if members, _ := mr.Members("notes:owner:alice:tag:grpc"); !slices.Equal(members, []string{"b"}) {
+		t.Errorf("tag grpc = %v, want [b] (a lost the tag in version 2)", members)
+	}
+	tests := []struct {
+		tags  []string
+		match service.TagMatch
+		want  []string
+	}{
+		{[]string{"go"}, service.TagMatchAny, []string{"a"}},
+		{[]string{"go", "grpc"}, service.TagMatchAny, []string{"a", "b"}},
+		{[]string{"go", "grpc"}, service.TagMatchAll, nil},
+	}
+	for _, tt := range tests {
+		notes, err := r.List(ctx, service.ListQuery{OwnerID: "alice", Tags: tt.tags, TagMatch: tt.match})
+		if err != nil || !slices.Equal(ids(notes), tt.want) {
+			t.Errorf("tags %v (match %d) = %v, %v; want %v", tt.tags, tt.match, ids(notes), err, tt.want)
+		}
+	}
+
+	revs, err := r.ListRevisions(ctx, "a", 0, 0)
+	if err != nil || len(revs) != 2 || revs[0].Title != "A2" || revs[1].Title != "A" {
+		t.Fatalf("ListRevisions = %+v, %v; want versions 2, 1", revs, err)
+	}
+	if revs, err := r.ListRevisions(ctx, "a", 2, 1); err != nil || len(revs) != 1 || revs[0].Version != 1 {
+		t.Errorf("ListRevisions(before 2) = %+v, %v; want version 1", revs, err)
+	}
+	if rev, err := r.GetRevision(ctx, "a", 1); err != nil || !slices.Equal(rev.Tags, []string{"go", "grpc"}) {
+		t.Errorf("GetRevision(1) = %+v, %v", rev, err)
+	}
+	if _, err := r.GetRevision(ctx, "a", 3); !errors.Is(err, service.ErrRecordNotFound) {
+		t.Errorf("GetRevision(3): err = %v, want ErrRecordNotFound", err)
+	}
+
+	// В корзине заметка переезжает в trash_tag и перестаёт считаться в ListTags.
+	n.Version, n.DeletedAt = 3, t0
+	if err := r.Save(ctx, n); err != nil {
+		t.Fatal(err)
+	}
+	if members, _ := mr.Members("notes:owner:alice:trash_tag:go"); !slices.Equal(members, []string{"a"}) {
+		t.Errorf("trash_tag go = %v, want [a]", members)
+	}
+	if tags, err := r.ListTags(ctx, "alice"); err != nil || !slices.Equal(tags, []service.TagCount{{Tag: "grpc", Count: 1}}) {
+		t.Errorf("ListTags = %v, %v; want [grpc 1]", tags, err)
+	}
+	if notes, err := r.List(ctx, service.ListQuery{OwnerID: "alice", Trash: true, Tags: []string{"go"}}); err != nil || !slices.Equal(ids(notes), []string{"a"}) {
+		t.Errorf("trash by tag = %v, %v; want [a]", ids(notes), err)
+	}
+
+	if err := r.Delete(ctx, "a", 3); err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range []string{"notes:note:a", "notes:revisions:a", "notes:owner:alice:trash_tag:go"} {
+		if mr.Exists(k) {
+			t.Errorf("key %s still exists after Delete", k)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+-- Заметки. Время храним в Unix-наносекундах (INTEGER): так сортировка и курсоры
+-- работают точно так же, как в остальных хранилищах.
+CREATE TABLE notes (
+    id         TEXT    PRIMARY KEY,
+    title      TEXT    NOT NULL,
+    content    TEXT    NOT NULL,
+    created_at INTEGER NOT NULL,
+    updated_at INTEGER NOT NULL
+);
+
+-- Индексы под упорядоченную курсорную пагинацию ListNotes:
+-- ORDER BY <ключ>, id с условием (<ключ>, id) > (?, ?) читается прямо из индекса.
+CREATE INDEX notes_created_at_id ON notes (created_at, id);
+CREATE INDEX notes_title_id ON notes (title, id);
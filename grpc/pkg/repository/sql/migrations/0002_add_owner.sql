@@ -0,0 +1,9 @@
+-- Владелец заметки (sub из токена). Старые заметки остаются без владельца ('').
+ALTER TABLE notes ADD COLUMN owner_id TEXT NOT NULL DEFAULT '';
+
+-- ListNotes теперь всегда отбирает заметки одного владельца, поэтому
+-- owner_id встаёт первым в индексах пагинации.
+DROP INDEX notes_created_at_id;
+DROP INDEX notes_title_id;
+CREATE INDEX notes_owner_created_at_id ON notes (owner_id, created_at, id);
+CREATE INDEX notes_owner_title_id ON notes (owner_id, title, id);
@@ -0,0 +1,2 @@
+-- Версия заметки для оптимистичной блокировки. Существующие заметки — версия 1.
+ALTER TABLE notes ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
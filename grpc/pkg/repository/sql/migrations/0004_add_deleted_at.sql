@@ -0,0 +1,2 @@
+-- Корзина: время мягкого удаления в Unix-наносекундах, 0 — заметка не удалена.
+ALTER TABLE notes ADD COLUMN deleted_at INTEGER NOT NULL DEFAULT 0;
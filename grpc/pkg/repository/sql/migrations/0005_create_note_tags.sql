@@ -0,0 +1,10 @@
+-- Теги заметок — отдельная таблица, она же индекс для выборки по тегу.
+-- owner_id продублирован из notes (он у заметки не меняется), чтобы и фильтр
+-- ListNotes, и подсчёт тегов владельца шли по первичному ключу.
+CREATE TABLE note_tags (
+    owner_id TEXT NOT NULL,
+    tag      TEXT NOT NULL,
+    note_id  TEXT NOT NULL,
+    PRIMARY KEY (owner_id, tag, note_id)
+);
+CREATE INDEX note_tags_note_id ON note_tags (note_id);
@@ -0,0 +1,16 @@
+-- История версий: каждая записанная версия заметки, удаляется вместе с заметкой.
+-- Колонки те же, что у notes; теги — строкой через запятую, как их читает scanNote.
+-- Заметки, записанные до этой миграции, истории не имеют: сервис берёт
+-- их текущую версию из notes.
+CREATE TABLE note_revisions (
+    note_id    TEXT    NOT NULL,
+    owner_id   TEXT    NOT NULL,
+    title      TEXT    NOT NULL,
+    content    TEXT    NOT NULL,
+    created_at INTEGER NOT NULL,
+    updated_at INTEGER NOT NULL,
+    version    INTEGER NOT NULL,
+    deleted_at INTEGER NOT NULL,
+    tags       TEXT    NOT NULL DEFAULT '',
+    PRIMARY KEY (note_id, version)
+);
@@ -0,0 +1,5 @@
+-- Вложения заметки — JSON-массив метаданных (само содержимое лежит в хранилище блобов).
+-- Отдельная таблица не нужна: вложения читаются только вместе с заметкой и по ним не ищут.
+-- Пустая строка — вложений нет.
+ALTER TABLE notes ADD COLUMN attachments TEXT NOT NULL DEFAULT '';
+ALTER TABLE note_revisions ADD COLUMN attachments TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,584 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/verazalayli/go_studying/grpc/pkg/service"
+)
+
+/*
+	NoteRepo — хранилище заметок на database/sql (адаптер к порту service.NoteRepository).
+
+	SQL написан под SQLite (плейсхолдеры "?", ON CONFLICT DO NOTHING, row values
+	в условии курсора). Драйвер пакет не импортирует: его подключает composition root,
+	а сюда приходит уже открытый *sql.DB.
+
+	Версии проверяются в самом запросе: новая заметка вставляется, только если
+	такого id ещё нет, изменение — UPDATE ... WHERE version = <предыдущая>.
+	Ноль затронутых строк означает, что версия не совпала.
+
+	Теги лежат в таблице note_tags и переписываются в той же транзакции, что и сама
+	заметка. Читаются они подзапросом (tagsColumn), фильтр по тегам в List — это
+	подзапрос к note_tags по первичному ключу (owner_id, tag, note_id).
+	Каждая записанная версия копируется в note_revisions в той же транзакции.
+	Метаданные вложений — JSON-массив в колонке attachments (по ним не фильтруют).
+
+	Частые запросы подготавливаются один раз (prepared statements).
+	Для List вариантов запроса много (порядок × курсор × фильтр), поэтому они
+	подготавливаются лениво и кешируются по "форме" запроса.
+*/
+
+const noteColumns = `id, owner_id, title, content, created_at, updated_at, version, deleted_at, attachments`
+
+// tagsColumn — теги заметки одной строкой через запятую (в самих тегах запятых нет).
+const tagsColumn = `(SELECT group_concat(tag, ',') FROM note_tags WHERE note_id = notes.id)`
+
+// selectColumns — колонки, которые читает scanNote.
+const selectColumns = noteColumns + `, ` + tagsColumn
+
+// revisionColumns — колонки note_revisions в том же порядке, что selectColumns.
+const revisionColumns = `note_id, owner_id, title, content, created_at, updated_at, version, deleted_at, attachments, tags`
+
+type NoteRepo struct {
+	db *sql.DB
+
+	insert   *sql.Stmt
+	update   *sql.Stmt
+	getByID  *sql.Stmt
+	del      *sql.Stmt
+	addTag   *sql.Stmt
+	dropTags *sql.Stmt
+	tagCount *sql.Stmt
+	addRev   *sql.Stmt
+	dropRevs *sql.Stmt
+	listRevs *sql.Stmt
+	getRev   *sql.Stmt
+
+	mu   sync.Mutex
+	list map[listShape]*sql.Stmt
+}
+
+// NewNoteRepo применяет миграции и подготавливает запросы.
+func NewNoteRepo(ctx context.Context, db *sql.DB) (*NoteRepo, error) {
+	if err := Migrate(ctx, db); err != nil {
+		return nil, err
+	}
+	r := &NoteRepo{db: db, list: make(map[listShape]*sql.Stmt)}
+	var err error
+	if r.insert, err = db.PrepareContext(ctx, `INSERT INTO notes (`+noteColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO NOTHING`); err != nil {
+		return nil, fmt.Errorf("prepare insert: %w", err)
+	}
+	if r.update, err = db.PrepareContext(ctx, `UPDATE notes SET
+			title = ?, content = ?, updated_at = ?, version = ?, deleted_at = ?, attachments = ?
+		WHERE id = ? AND version = ?`); err != nil {
+		r.Close()
+		return nil, fmt.Errorf("prepare update: %w", err)
+	}
+	if r.getByID, err = db.PrepareContext(ctx, `SELECT `+selectColumns+` FROM notes WHERE id = ?`); err != nil {
+		r.Close()
+		return nil, fmt.Errorf("prepare get: %w", err)
+	}
+	if r.del, err = db.PrepareContext(ctx, `DELETE FROM notes WHERE id = ? AND (? = 0 OR version = ?)`); err != nil {
+		r.Close()
+		return nil, fmt.Errorf("prepare delete: %w", err)
+	}
+	if r.addTag, err = db.PrepareContext(ctx, `INSERT INTO note_tags (owner_id, tag, note_id) VALUES (?, ?, ?)`); err != nil {
+		r.Close()
+		return nil, fmt.Errorf("prepare add tag: %w", err)
+	}
+	if r.dropTags, err = db.PrepareContext(ctx, `DELETE FROM note_tags WHERE note_id = ?`); err != nil {
+		r.Close()
+		return nil, fmt.Errorf("prepare drop tags: %w", err)
+	}
+	if r.tagCount, err = db.PrepareContext(ctx, `SELECT t.tag, COUNT(*) FROM note_tags t
+		JOIN notes n ON n.id = t.note_id
+		WHERE t.owner_id = ? AND n.deleted_at = 0
+		GROUP BY t.tag ORDER BY t.tag`); err != nil {
+		r.Close()
+		return nil, fmt.Errorf("prepare tag count: %w", err)
+	}
+	if r.addRev, err = db.PrepareContext(ctx, `INSERT INTO note_revisions (`+revisionColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`); err != nil {
+		r.Close()
+		return nil, fmt.Errorf("prepare add revision: %w", err)
+	}
+	if r.dropRevs, err = db.PrepareContext(ctx, `DELETE FROM note_revisions WHERE note_id = ?`); err != nil {
+		r.Close()
+		return nil, fmt.Errorf("prepare drop revisions: %w", err)
+	}
+	if r.listRevs, err = db.PrepareContext(ctx, `SELECT `+revisionColumns+` FROM note_revisions
+		WHERE note_id = ? AND (? = 0 OR version < ?) ORDER BY version DESC LIMIT ?`); err != nil {
+		r.Close()
+		return nil, fmt.Errorf("prepare list revisions: %w", err)
+	}
+	if r.getRev, err = db.PrepareContext(ctx, `SELECT `+revisionColumns+` FROM note_revisions WHERE note_id = ? AND version = ?`); err != nil {
+		r.Close()
+		return nil, fmt.Errorf("prepare get revision: %w", err)
+	}
+	return r, nil
+}
+
+// Close закрывает подготовленные запросы. Сам *sql.DB закрывает тот, кто его открыл.
+func (r *NoteRepo) Close() error {
+	for _, st := range []*sql.Stmt{r.insert, r.update, r.getByID, r.del, r.addTag, r.dropTags, r.tagCount,
+		r.addRev, r.dropRevs, r.listRevs, r.getRev} {
+		if st != nil {
+			st.Close()
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, st := range r.list {
+		st.Close()
+	}
+	return nil
+}
+
+// Ping проверяет соединение с БД.
+func (r *NoteRepo) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
+// Save — заметка и её теги меняются в одной транзакции, как пачка из одной заметки.
+func (r *NoteRepo) Save(ctx context.Context, n service.Note) error {
+	return r.SaveMany(ctx, []service.Note{n})
+}
+
+// SaveMany сохраняет пачку в одной транзакции: либо все, либо ни одной.
+func (r *NoteRepo) SaveMany(ctx context.Context, notes []service.Note) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback()
+	st := r.txStmts(ctx, tx)
+	for _, n := range notes {
+		if err := st.save(ctx, n); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
+
+// txStmts — подготовленные запросы, привязанные к транзакции записи.
+type txStmts struct {
+	insert, update, addTag, dropTags, addRev *sql.Stmt
+}
+
+func (r *NoteRepo) txStmts(ctx context.Context, tx *sql.Tx) txStmts {
+	return txStmts{
+		insert:   tx.StmtContext(ctx, r.insert),
+		update:   tx.StmtContext(ctx, r.update),
+		addTag:   tx.StmtContext(ctx, r.addTag),
+		dropTags: tx.StmtContext(ctx, r.dropTags),
+		addRev:   tx.StmtContext(ctx, r.addRev),
+	}
+}
+
+// save вставляет новую заметку (версия 1) или меняет предыдущую версию существующей
+// и переписывает её теги, а саму версию добавляет в историю. Удалённая или уже изменённая кем-то заметка — ErrVersionConflict.
+func (st txStmts) save(ctx context.Context, n service.Note) error {
+	var (
+		res sql.Result
+		err error
+	)
+	if n.Version == 1 {
+		res, err = st.insert.ExecContext(ctx, noteArgs(n)...)
+	} else {
+		res, err = st.update.ExecContext(ctx, n.Title, n.Content, n.UpdatedAt.UnixNano(), n.Version, deletedAt(n), attachmentsJSON(n), n.ID, n.Version-1)
+	}
+	if err != nil {
+		return fmt.Errorf("save note %s: %w", n.ID, err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("save note %s: %w", n.ID, err)
+	}
+	if affected == 0 {
+		return service.ErrVersionConflict
+	}
+	if n.Version != 1 {
+		if _, err := st.dropTags.ExecContext(ctx, n.ID); err != nil {
+			return fmt.Errorf("save tags of %s: %w", n.ID, err)
+		}
+	}
+	for _, t := range n.Tags {
+		if _, err := st.addTag.ExecContext(ctx, n.OwnerID, t, n.ID); err != nil {
+			return fmt.Errorf("save tags of %s: %w", n.ID, err)
+		}
+	}
+	if _, err := st.addRev.ExecContext(ctx, append(noteArgs(n), strings.Join(n.Tags, ","))...); err != nil {
+		return fmt.Errorf("save revision of %s: %w", n.ID, err)
+	}
+	return nil
+}
+
+func (r *NoteRepo) GetByID(ctx context.Context, id string) (service.Note, error) {
+	n, err := scanNote(r.getByID.QueryRowContext(ctx, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return service.Note{}, service.ErrRecordNotFound
+	}
+	if err != nil {
+		return service.Note{}, fmt.Errorf("get note: %w", err)
+	}
+	return n, nil
+}
+
+func (r *NoteRepo) Delete(ctx context.Context, id string, expectedVersion int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback()
+	if err := r.deleteTx(ctx, tx, id, expectedVersion); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
+
+// Replace — удаление и запись новой истории в одной транзакции.
+func (r *NoteRepo) Replace(ctx context.Context, id string, expectedVersion int64, states []service.Note) error {
+	if !service.CanSaveHistory(id, states) {
+		return service.ErrVersionConflict
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback()
+	if err := r.deleteTx(ctx, tx, id, expectedVersion); err != nil {
+		return err
+	}
+	st := r.txStmts(ctx, tx)
+	for _, n := range states {
+		if err := st.save(ctx, n); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
+
+// deleteTx удаляет заметку с тегами и историей в транзакции tx.
+// Не та версия — транзакция откатывается и возвращается ErrVersionConflict или ErrRecordNotFound.
+func (r *NoteRepo) deleteTx(ctx context.Context, tx *sql.Tx, id string, expectedVersion int64) error {
+	res, err := tx.StmtContext(ctx, r.del).ExecContext(ctx, id, expectedVersion, expectedVersion)
+	if err != nil {
+		return fmt.Errorf("delete note: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("delete note: %w", err)
+	}
+	if affected == 0 {
+		tx.Rollback()
+		// Удаление уже не состоялось; отдельный запрос лишь уточняет причину.
+		if _, err := r.GetByID(ctx, id); err != nil {
+			return err
+		}
+		return service.ErrVersionConflict
+	}
+	if _, err := tx.StmtContext(ctx, r.dropTags).ExecContext(ctx, id); err != nil {
+		return fmt.Errorf("delete tags: %w", err)
+	}
+	if _, err := tx.StmtContext(ctx, r.dropRevs).ExecContext(ctx, id); err != nil {
+		return fmt.Errorf("delete revisions: %w", err)
+	}
+	return nil
+}
+
+func (r *NoteRepo) ListRevisions(ctx context.Context, id string, before int64, limit int) ([]service.Note, error) {
+	if limit <= 0 {
+		limit = -1
+	}
+	rows, err := r.listRevs.QueryContext(ctx, id, before, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list revisions: %w", err)
+	}
+	defer rows.Close()
+	var out []service.Note
+	for rows.Next() {
+		n, err := scanNote(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan revision: %w", err)
+		}
+		out = append(out, n)
+	}
+	return out, rows.Err()
+}
+
+func (r *NoteRepo) GetRevision(ctx context.Context, id string, version int64) (service.Note, error) {
+	n, err := scanNote(r.getRev.QueryRowContext(ctx, id, version))
+	if errors.Is(err, sql.ErrNoRows) {
+		return service.Note{}, service.ErrRecordNotFound
+	}
+	if err != nil {
+		return service.Note{}, fmt.Errorf("get revision: %w", err)
+	}
+	return n, nil
+}
+
+func (r *NoteRepo) ListTags(ctx context.Context, owner string) ([]service.TagCount, error) {
+	rows, err := r.tagCount.QueryContext(ctx, owner)
+	if err != nil {
+		return nil, fmt.Errorf("list tags: %w", err)
+	}
+	defer rows.Close()
+	out := []service.TagCount{}
+	for rows.Next() {
+		var tc service.TagCount
+		if err := rows.Scan(&tc.Tag, &tc.Count); err != nil {
+			return nil, fmt.Errorf("scan tag: %w", err)
+		}
+		out = append(out, tc)
+	}
+	return out, rows.Err()
+}
+
+func (r *NoteRepo) List(ctx context.Context, q service.ListQuery) ([]service.Note, error) {
+	shape := listShape{order: q.Order, after: q.After != nil, prefix: q.TitlePrefix != "", anyOwner: q.AnyOwner, trash: q.Trash,
+		tags: len(q.Tags), allTags: len(q.Tags) > 1 && q.TagMatch == service.TagMatchAll}
+	st, err := r.listStmt(ctx, shape)
+	if err != nil {
+		return nil, err
+	}
+
+	var args []any
+	if !shape.anyOwner {
+		args = append(args, q.OwnerID)
+	}
+	if shape.prefix {
+		args = append(args, q.TitlePrefix)
+		upper, bounded := prefixUpperBound(q.TitlePrefix)
+		if !bounded {
+			// Префикс из одних байтов 0xFF невозможен в валидном UTF-8
+			// (а строки protobuf всегда UTF-8), так что это лишь страховка.
+			upper = q.TitlePrefix + "\xff"
+		}
+		args = append(args, upper)
+	}
+	if shape.tags > 0 {
+		if !shape.anyOwner {
+			args = append(args, q.OwnerID)
+		}
+		for _, t := range q.Tags {
+			args = append(args, t)
+		}
+		if shape.allTags {
+			args = append(args, len(q.Tags))
+		}
+	}
+	if shape.after {
+		switch q.Order.Field {
+		case service.SortByTitle:
+			args = append(args, q.After.Title)
+		default:
+			args = append(args, q.After.CreatedAt.UnixNano())
+		}
+		args = append(args, q.After.ID)
+	}
+	limit := q.Limit
+	if limit <= 0 {
+		limit = -1 // в SQLite LIMIT -1 — без ограничения
+	}
+	args = append(args, limit)
+
+	rows, err := st.QueryContext(ctx, args...)
+	if err != nil {
+		return nil, fmt.Errorf("list notes: %w", err)
+	}
+	defer rows.Close()
+	var out []service.Note
+	for rows.Next() {
+		n, err := scanNote(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan note: %w", err)
+		}
+		out = append(out, n)
+	}
+	return out, rows.Err()
+}
+
+// listShape — всё, от чего зависит текст SQL для List (но не значения параметров).
+type listShape struct {
+	order    service.ListOrder
+	after    bool
+	prefix   bool
+	anyOwner bool
+	trash    bool
+	tags     int  // сколько тегов в фильтре (от этого зависит число плейсхолдеров)
+	allTags  bool // нужны все теги, а не любой
+}
+
+func (r *NoteRepo) listStmt(ctx context.Context, shape listShape) (*sql.Stmt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if st, ok := r.list[shape]; ok {
+		return st, nil
+	}
+	st, err := r.db.PrepareContext(ctx, buildListSQL(shape))
+	if err != nil {
+		return nil, fmt.Errorf("prepare list: %w", err)
+	}
+	r.list[shape] = st
+	return st, nil
+}
+
+func buildListSQL(shape listShape) string {
+	key, dir, cmp := "created_at", "ASC", ">"
+	if shape.order.Field == service.SortByTitle {
+		key = "title"
+	}
+	if shape.order.Desc {
+		dir, cmp = "DESC", "<"
+	}
+
+	var where []string
+	if !shape.anyOwner {
+		// owner_id = ? первым: индексы (owner_id, <ключ>, id) отдают страницу одного владельца.
+		where = append(where, "owner_id = ?")
+	}
+	if shape.trash {
+		where = append(where, "deleted_at <> 0")
+	} else {
+		where = append(where, "deleted_at = 0")
+	}
+	if shape.prefix {
+		// Диапазон вместо LIKE: так фильтр тоже идёт по индексу (title, id).
+		where = append(where, "title >= ? AND title < ?")
+	}
+	if shape.tags > 0 {
+		// Заметки с тегами берём из note_tags по ключу (owner_id, tag); для "все теги"
+		// заметка должна встретиться столько раз, сколько тегов в фильтре.
+		sub := "SELECT note_id FROM note_tags WHERE "
+		if !shape.anyOwner {
+			sub += "owner_id = ? AND "
+		}
+		sub += "tag IN (?" + strings.Repeat(", ?", shape.tags-1) + ")"
+		if shape.allTags {
+			sub += " GROUP BY note_id HAVING COUNT(*) = ?"
+		}
+		where = append(where, "id IN ("+sub+")")
+	}
+	if shape.after {
+		where = append(where, fmt.Sprintf("(%s, id) %s (?, ?)", key, cmp))
+	}
+
+	var b strings.Builder
+	b.WriteString("SELECT " + selectColumns + " FROM notes")
+	if len(where) > 0 {
+		b.WriteString(" WHERE " + strings.Join(where, " AND "))
+	}
+	fmt.Fprintf(&b, " ORDER BY %s %s, id %s LIMIT ?", key, dir, dir)
+	return b.String()
+}
+
+// prefixUpperBound — наименьшая строка, которая больше всех строк с данным префиксом
+// (сравнение побайтовое, как у SQLite BINARY и strings.Compare).
+func prefixUpperBound(p string) (string, bool) {
+	b := []byte(p)
+	for i := len(b) - 1; i >= 0; i-- {
+		if b[i] < 0xff {
+			b[i]++
+			return string(b[:i+1]), true
+		}
+	}
+	return "", false
+}
+
+func noteArgs(n service.Note) []any {
+	return []any{n.ID, n.OwnerID, n.Title, n.Content, n.CreatedAt.UnixNano(), n.UpdatedAt.UnixNano(), n.Version, deletedAt(n), attachmentsJSON(n)}
+}
+
+// attachmentRecord — вложение в JSON колонки attachments.
+type attachmentRecord struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	SHA256      string `json:"sha256"`
+	CreatedAt   int64  `json:"created_at"` // UnixNano, как остальные времена в схеме
+}
+
+// attachmentsJSON — значение колонки attachments: JSON-массив или "" без вложений.
+func attachmentsJSON(n service.Note) string {
+	if len(n.Attachments) == 0 {
+		return ""
+	}
+	recs := make([]attachmentRecord, len(n.Attachments))
+	for i, a := range n.Attachments {
+		recs[i] = attachmentRecord{ID: a.ID, Name: a.Name, ContentType: a.ContentType, Size: a.Size, SHA256: a.SHA256, CreatedAt: a.CreatedAt.UnixNano()}
+	}
+	b, _ := json.Marshal(recs) // строки и числа сериализуются всегда
+	return string(b)
+}
+
+func parseAttachments(s string) ([]service.Attachment, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var recs []attachmentRecord
+	if err := json.Unmarshal([]byte(s), &recs); err != nil {
+		return nil, fmt.Errorf("decode attachments: %w", err)
+	}
+	out := make([]service.Attachment, len(recs))
+	for i, r := range recs {
+		out[i] = service.Attachment{ID: r.ID, Name: r.Name, ContentType: r.ContentType, Size: r.Size, SHA256: r.SHA256, CreatedAt: time.Unix(0, r.CreatedAt)}
+	}
+	return out, nil
+}
+
+// deletedAt — время удаления в колонке deleted_at: 0 — заметка не в корзине.
+func deletedAt(n service.Note) int64 {
+	if !n.Deleted() {
+		return 0
+	}
+	return n.DeletedAt.UnixNano()
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanNote(s scanner) (service.Note, error) {
+	var (
+		n                               service.Note
+		createdAt, updatedAt, deletedAt int64
+		attachments                     string
+		tags                            sql.NullString
+	)
+	if err := s.Scan(&n.ID, &n.OwnerID, &n.Title, &n.Content, &createdAt, &updatedAt, &n.Version, &deletedAt, &attachments, &tags); err != nil {
+		return service.Note{}, err
+	}
+	var err error
+	if n.Attachments, err = parseAttachments(attachments); err != nil {
+		return service.Note{}, err
+	}
+	if tags.Valid && tags.String != "" {
+		// group_concat не гарантирует порядок.
+		n.Tags = strings.Split(tags.String, ",")
+		slices.Sort(n.Tags)
+	}
+	n.CreatedAt = time.Unix(0, createdAt)
+	n.UpdatedAt = time.Unix(0, updatedAt)
+	if deletedAt != 0 {
+		n.DeletedAt = time.Unix(0, deletedAt)
+	}
+	return n, nil
+}
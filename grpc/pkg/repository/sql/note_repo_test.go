@@ -0,0 +1,289 @@
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/verazalayli/go_studying/grpc/pkg/service"
+)
+
+// openDB — пустая SQLite в памяти. Одно соединение, как у сервера:
+// у ":memory:" каждое соединение — своя база.
+func openDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func newRepo(t *testing.T) *NoteRepo {
+	t.Helper()
+	r, err := NewNoteRepo(context.Background(), openDB(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { r.Close() })
+	return r
+}
+
+var t0 = time.Date(2025, 1, 2, 3, 4, 5, 123456789, time.UTC)
+
+func note(id, owner, title string, created time.Time, tags ...string) service.Note {
+	return service.Note{ID: id, OwnerID: owner, Title: title, Content: "text of " + id,
+		CreatedAt: created, UpdatedAt: created, Version: 1, Tags: tags}
+}
+
+func ids(notes []service.Note) []string {
+	out := make([]string, len(notes))
+	for i, n := range notes {
+		out[i] = n.ID
+	}
+	return out
+}
+
+func TestMigrate(t *testing.T) {
+	ctx := context.Background()
+	db := openDB(t)
+	want, err := loadMigrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	applied := func() []int {
+		t.Helper()
+		rows, err := db.QueryContext(ctx, `SELECT version FROM schema_migrations ORDER BY version`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+		var vs []int
+		for rows.Next() {
+			var v int
+			if err := rows.Scan(&v); err != nil {
+				t.Fatal(err)
+			}
+			vs = append(vs, v)
+		}
+		return vs
+	}
+
+	if err := Migrate(ctx, db); err != nil {
+		t.Fatalf("fresh database: %v", err)
+	}
+	got := applied()
+	if len(got) != len(want) || got[len(got)-1] != want[len(want)-1].version {
+		t.Fatalf("applied versions %v, want all %d migrations", got, len(want))
+	}
+	for _, table := range []string{"notes", "note_tags", "note_revisions"} {
+		var n int
+		if err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, table).Scan(&n); err != nil || n != 1 {
+			t.Errorf("table %s: count %d, err %v", table, n, err)
+		}
+	}
+
+	// Повторный запуск (как при каждом старте сервера) ничего не применяет заново.
+	if err := Migrate(ctx, db); err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+	if again := applied(); !slices.Equal(again, got) {
+		t.Errorf("second run applied %v, want %v", again, got)
+	}
+	r, err := NewNoteRepo(ctx, db)
+	if err != nil {
+		t.Fatalf("NewNoteRepo on a migrated database: %v", err)
+	}
+	r.Close()
+}
+
+func TestNotFound(t *testing.T) {
+	ctx := context.Background()
+	r := newRepo(t)
+	if _, err := r.GetByID(ctx, "missing"); !errors.Is(err, service.ErrRecordNotFound) {
+		t.Errorf("GetByID: err = %v, want ErrRecordNotFound", err)
+	}
+	if _, err := r.GetRevision(ctx, "missing", 1); !errors.Is(err, service.ErrRecordNotFound) {
+		t.Errorf("GetRevision: err = %v, want ErrRecordNotFound", err)
+	}
+	if err := r.Delete(ctx, "missing", 0); !errors.Is(err, service.ErrRecordNotFound) {
+		t.Errorf("Delete: err = %v, want ErrRecordNotFound", err)
+	}
+}
+
+func TestVersionConflict(t *testing.T) {
+	ctx := context.Background()
+	r := newRepo(t)
+	n := note("a", "alice", "A", t0, "work")
+	if err := r.Save(ctx, n); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Save(ctx, n); !errors.Is(err, service.ErrVersionConflict) {
+		t.Errorf("second insert of version 1: err = %v, want ErrVersionConflict", err)
+	}
+	skip := n
+	skip.Version = 3
+	if err := r.Save(ctx, skip); !errors.Is(err, service.ErrVersionConflict) {
+		t.Errorf("save version 3 over 1: err = %v, want ErrVersionConflict", err)
+	}
+
+	n.Version, n.Title, n.Tags = 2, "A2", []string{"home"}
+	if err := r.Save(ctx, n); err != nil {
+		t.Fatal(err)
+	}
+	got, err := r.GetByID(ctx, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Version != 2 || got.Title != "A2" || !slices.Equal(got.Tags, []string{"home"}) || !got.CreatedAt.Equal(t0) {
+		t.Errorf("GetByID = %+v", got)
+	}
+	if revs, err := r.ListRevisions(ctx, "a", 0, 0); err != nil || len(revs) != 2 || revs[0].Version != 2 {
+		t.Errorf("ListRevisions = %v, %v; want versions 2, 1", revs, err)
+	}
+
+	// Пачка записывается целиком или никак: вторая заметка конфликтует — первой тоже нет.
+	if err := r.SaveMany(ctx, []service.Note{note("b", "alice", "B", t0), n}); !errors.Is(err, service.ErrVersionConflict) {
+		t.Errorf("SaveMany with a stale note: err = %v, want ErrVersionConflict", err)
+	}
+	if _, err := r.GetByID(ctx, "b"); !errors.Is(err, service.ErrRecordNotFound) {
+		t.Errorf("note from a failed batch was saved: err = %v", err)
+	}
+
+	if err := r.Delete(ctx, "a", 1); !errors.Is(err, service.ErrVersionConflict) {
+		t.Errorf("Delete at a stale version: err = %v, want ErrVersionConflict", err)
+	}
+	if err := r.Delete(ctx, "a", 2); err != nil {
+		t.Fatalf("Delete at the current version: %v", err)
+	}
+	if _, err := r.GetByID(ctx, "a"); !errors.Is(err, service.ErrRecordNotFound) {
+		t.Errorf("GetByID after Delete: err = %v", err)
+	}
+	if revs, err := r.ListRevisions(ctx, "a", 0, 0); err != nil || len(revs) != 0 {
+		t.Errorf("revisions after Delete = %v, %v; want none", revs, err)
+	}
+	if tags, err := r.ListTags(ctx, "alice"); err != nil || len(tags) != 0 {
+		t.Errorf("tags after Delete = %v, %v; want none", tags, err)
+	}
+}
+
+func TestListPagination(t *testing.T) {
+	ctx := context.Background()
+	r := newRepo(t)
+	// Пары с одинаковым временем создания и одинаковым заголовком: порядок
+	// между ними решает только id, и курсор не должен ни терять, ни повторять их.
+	var all []service.Note
+	for i := range 7 {
+		n := note(fmt.Sprintf("n%d", 6-i), "alice", fmt.Sprintf("title %d", i/2), t0.Add(time.Duration(i/2)*time.Second))
+		all = append(all, n)
+	}
+	other := note("x", "bob", "title 0", t0)
+	trashed := note("t", "alice", "title 0", t0)
+	trashed.DeletedAt = t0
+	if err := r.SaveMany(ctx, append(slices.Clone(all), other, trashed)); err != nil {
+		t.Fatal(err)
+	}
+
+	orders := []service.ListOrder{
+		{Field: service.SortByCreatedAt},
+		{Field: service.SortByCreatedAt, Desc: true},
+		{Field: service.SortByTitle},
+		{Field: service.SortByTitle, Desc: true},
+	}
+	for _, order := range orders {
+		t.Run(order.String(), func(t *testing.T) {
+			want := slices.Clone(all)
+			slices.SortFunc(want, order.Compare)
+
+			var got []service.Note
+			q := service.ListQuery{OwnerID: "alice", Order: order, Limit: 2}
+			for page := 0; ; page++ {
+				if page > len(all) {
+					t.Fatal("pagination does not end")
+				}
+				notes, err := r.List(ctx, q)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, notes...)
+				if len(notes) < q.Limit {
+					break
+				}
+				q.After = service.CursorOf(notes[len(notes)-1])
+			}
+			if !slices.Equal(ids(got), ids(want)) {
+				t.Errorf("pages = %v, want %v", ids(got), ids(want))
+			}
+		})
+	}
+
+	q := service.ListQuery{OwnerID: "alice", TitlePrefix: "title 1"}
+	if notes, err := r.List(ctx, q); err != nil || !slices.Equal(ids(notes), []string{"n3", "n4"}) {
+		t.Errorf("title prefix: %v, %v; want [n3 n4]", ids(notes), err)
+	}
+	q = service.ListQuery{OwnerID: "alice", Trash: true}
+	if notes, err := r.List(ctx, q); err != nil || !slices.Equal(ids(notes), []string{"t"}) {
+		t.Errorf("trash: %v, %v; want [t]", ids(notes), err)
+	}
+}
+
+func TestListTags(t *testing.T) {
+	ctx := context.Background()
+	r := newRepo(t)
+	trashed := note("d", "alice", "D", t0.Add(3*time.Second), "go")
+	trashed.DeletedAt = t0
+	err := r.SaveMany(ctx, []service.Note{
+		note("a", "alice", "A", t0, "go", "grpc"),
+		note("b", "alice", "B", t0.Add(time.Second), "go"),
+		note("c", "alice", "C", t0.Add(2*time.Second), "sql"),
+		trashed,
+		note("e", "bob", "E", t0, "go", "grpc"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		tags  []string
+		match service.TagMatch
+		want  []string
+	}{
+		{[]string{"go"}, service.TagMatchAny, []string{"a", "b"}},
+		{[]string{"grpc", "sql"}, service.TagMatchAny, []string{"a", "c"}},
+		{[]string{"go", "grpc"}, service.TagMatchAll, []string{"a"}},
+		{[]string{"go", "sql"}, service.TagMatchAll, nil},
+		{[]string{"missing"}, service.TagMatchAny, nil},
+	}
+	for _, tt := range tests {
+		notes, err := r.List(ctx, service.ListQuery{OwnerID: "alice", Tags: tt.tags, TagMatch: tt.match})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(ids(notes), tt.want) {
+			t.Errorf("tags %v (match %d) = %v, want %v", tt.tags, tt.match, ids(notes), tt.want)
+		}
+	}
+	// Служебная выборка без владельца (AnyOwner) фильтрует по тегам всех владельцев.
+	notes, err := r.List(ctx, service.ListQuery{AnyOwner: true, Tags: []string{"grpc"}})
+	if err != nil || !slices.Equal(ids(notes), []string{"a", "e"}) {
+		t.Errorf("any owner, tag grpc = %v, %v; want [a e]", ids(notes), err)
+	}
+
+	counts, err := r.ListTags(ctx, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []service.TagCount{{Tag: "go", Count: 2}, {Tag: "grpc", Count: 1}, {Tag: "sql", Count: 1}}
+	if !slices.Equal(counts, want) {
+		t.Errorf("ListTags = %v, want %v (trash is not counted)", counts, want)
+	}
+}
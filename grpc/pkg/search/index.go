@@ -0,0 +1,237 @@
+package search
+
+import (
+	"cmp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+/*
+	Пакет search — простой полнотекстовый поиск в памяти.
+
+	Index — инвертированный индекс: для каждого терма хранится, в каких документах
+	он встречается и сколько раз (term frequency). Документ — это ID и набор полей
+	текста (у заметки — заголовок и содержимое).
+
+	Запрос — набор термов через пробел, все должны встретиться в документе (AND).
+	Терм со звёздочкой на конце ("prog*") совпадает со всеми термами с таким префиксом.
+	Результаты ранжируются по сумме частот совпавших термов.
+*/
+
+// Hit — найденный документ.
+type Hit struct {
+	ID    string
+	Score float64
+}
+
+// Index — потокобезопасный инвертированный индекс.
+type Index struct {
+	mu       sync.RWMutex
+	postings map[string]map[string]int // терм -> ID документа -> частота
+	docs     map[string][]string       // ID документа -> его уникальные термы (для удаления)
+
+	// terms — отсортированный список всех термов для поиска по префиксу.
+	// Пересобирается лениво, когда словарь изменился.
+	terms      []string
+	termsDirty bool
+}
+
+func NewIndex() *Index {
+	return &Index{
+		postings: make(map[string]map[string]int),
+		docs:     make(map[string][]string),
+	}
+}
+
+// Put индексирует документ, заменяя его прошлую версию.
+func (ix *Index) Put(id string, fields ...string) {
+	freq := make(map[string]int)
+	for _, f := range fields {
+		for _, t := range tokenize(f) {
+			freq[t.term]++
+		}
+	}
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.removeLocked(id)
+	terms := make([]string, 0, len(freq))
+	for term, n := range freq {
+		p, ok := ix.postings[term]
+		if !ok {
+			p = make(map[string]int)
+			ix.postings[term] = p
+			ix.termsDirty = true
+		}
+		p[id] = n
+		terms = append(terms, term)
+	}
+	ix.docs[id] = terms
+}
+
+// Remove убирает документ из индекса.
+func (ix *Index) Remove(id string) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.removeLocked(id)
+}
+
+func (ix *Index) removeLocked(id string) {
+	for _, term := range ix.docs[id] {
+		p := ix.postings[term]
+		delete(p, id)
+		if len(p) == 0 {
+			delete(ix.postings, term)
+			ix.termsDirty = true
+		}
+	}
+	delete(ix.docs, id)
+}
+
+// Search ищет документы, содержащие все термы запроса.
+// limit <= 0 — вернуть все совпадения.
+func (ix *Index) Search(q Query, limit int) []Hit {
+	if len(q.Terms) == 0 {
+		return nil
+	}
+
+	ix.mu.Lock()
+	if ix.termsDirty {
+		ix.terms = ix.terms[:0]
+		for term := range ix.postings {
+			ix.terms = append(ix.terms, term)
+		}
+		sort.Strings(ix.terms)
+		ix.termsDirty = false
+	}
+	ix.mu.Unlock()
+
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	var scores map[string]float64
+	for _, qt := range q.Terms {
+		// Частоты всех словарных термов, подходящих под этот терм запроса.
+		matched := make(map[string]float64)
+		for _, term := range ix.expandLocked(qt) {
+			for id, n := range ix.postings[term] {
+				matched[id] += float64(n)
+			}
+		}
+		if scores == nil {
+			scores = matched
+		} else {
+			// AND: остаются только документы, где нашлись все термы.
+			for id := range scores {
+				if s, ok := matched[id]; ok {
+					scores[id] += s
+				} else {
+					delete(scores, id)
+				}
+			}
+		}
+		if len(scores) == 0 {
+			return nil
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, s := range scores {
+		hits = append(hits, Hit{ID: id, Score: s})
+	}
+	slices.SortFunc(hits, func(a, b Hit) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits
+}
+
+// expandLocked возвращает словарные термы, подходящие под терм запроса.
+func (ix *Index) expandLocked(qt QueryTerm) []string {
+	if !qt.Prefix {
+		if _, ok := ix.postings[qt.Text]; ok {
+			return []string{qt.Text}
+		}
+		return nil
+	}
+	i := sort.SearchStrings(ix.terms, qt.Text)
+	var out []string
+	for ; i < len(ix.terms) && strings.HasPrefix(ix.terms[i], qt.Text); i++ {
+		out = append(out, ix.terms[i])
+	}
+	return out
+}
+
+// QueryTerm — один терм запроса.
+type QueryTerm struct {
+	Text   string
+	Prefix bool
+}
+
+// Query — разобранный поисковый запрос.
+type Query struct {
+	Terms []QueryTerm
+}
+
+// ParseQuery разбирает строку запроса. Термы нормализуются так же, как текст документов.
+func ParseQuery(s string) Query {
+	var q Query
+	for _, word := range strings.Fields(s) {
+		prefix := strings.HasSuffix(word, "*")
+		for _, t := range tokenize(strings.TrimRight(word, "*")) {
+			q.Terms = append(q.Terms, QueryTerm{Text: t.term})
+		}
+		// Звёздочка относится к последнему терму слова: "e-mai*" -> "e" AND "mai*".
+		if prefix && len(q.Terms) > 0 {
+			q.Terms[len(q.Terms)-1].Prefix = true
+		}
+	}
+	return q
+}
+
+// Matches — совпадает ли нормализованный терм документа с термом запроса.
+func (qt QueryTerm) Matches(term string) bool {
+	if qt.Prefix {
+		return strings.HasPrefix(term, qt.Text)
+	}
+	return term == qt.Text
+}
+
+// token — терм и его позиция (в байтах) в исходном тексте.
+type token struct {
+	term       string
+	start, end int
+}
+
+// tokenize режет текст на слова (буквы и цифры) и приводит их к нижнему регистру
+// с учётом Unicode (case folding), чтобы "Go", "GO" и "go" были одним термом.
+func tokenize(s string) []token {
+	var out []token
+	start := -1
+	for i, r := range s {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case isWord && start < 0:
+			start = i
+		case !isWord && start >= 0:
+			out = append(out, token{term: fold(s[start:i]), start: start, end: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		out = append(out, token{term: fold(s[start:]), start: start, end: len(s)})
+	}
+	return out
+}
+
+func fold(s string) string {
+	return strings.ToLower(strings.ToUpper(s))
+}
@@ -0,0 +1,101 @@
+package search
+
+import (
+	"html"
+	"strings"
+)
+
+// Маркеры подсветки совпадений в сниппетах.
+const (
+	HighlightOpen  = "<mark>"
+	HighlightClose = "</mark>"
+)
+
+// snippetContext — сколько байт текста показывать вокруг совпадения (с подгонкой к границам слов).
+const snippetContext = 40
+
+// Snippets возвращает до limit фрагментов текста вокруг совпадений с запросом,
+// в которых совпавшие слова обёрнуты в HighlightOpen/HighlightClose.
+// Фрагмент — готовый HTML: сам текст экранирован, разметка в нём — только маркеры.
+// Соседние совпадения, попавшие в одно окно, объединяются в один фрагмент.
+func Snippets(text string, q Query, limit int) []string {
+	tokens := tokenize(text)
+	var hits []token
+	for _, t := range tokens {
+		for _, qt := range q.Terms {
+			if qt.Matches(t.term) {
+				hits = append(hits, t)
+				break
+			}
+		}
+	}
+	if len(hits) == 0 {
+		return nil
+	}
+
+	var out []string
+	for i := 0; i < len(hits) && (limit <= 0 || len(out) < limit); {
+		from := wordStart(tokens, hits[i].start-snippetContext)
+		to := wordEnd(text, tokens, hits[i].end+snippetContext)
+		// Забираем в окно все следующие совпадения, которые в него помещаются.
+		j := i
+		for j+1 < len(hits) && hits[j+1].start < to {
+			j++
+			if e := wordEnd(text, tokens, hits[j].end+snippetContext); e > to {
+				to = e
+			}
+		}
+		out = append(out, highlight(text, hits[i:j+1], from, to))
+		i = j + 1
+	}
+	return out
+}
+
+func highlight(text string, hits []token, from, to int) string {
+	var b strings.Builder
+	if from > 0 {
+		b.WriteString("…")
+	}
+	pos := from
+	for _, h := range hits {
+		b.WriteString(html.EscapeString(text[pos:h.start]))
+		b.WriteString(HighlightOpen)
+		b.WriteString(html.EscapeString(text[h.start:h.end]))
+		b.WriteString(HighlightClose)
+		pos = h.end
+	}
+	b.WriteString(html.EscapeString(text[pos:to]))
+	if to < len(text) {
+		b.WriteString("…")
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// wordStart сдвигает позицию к началу слова, в которое она попала (или следующего),
+// чтобы не резать слово пополам. Границы слов всегда на границах рун.
+func wordStart(tokens []token, pos int) int {
+	if pos <= 0 {
+		return 0
+	}
+	for _, t := range tokens {
+		if t.end > pos {
+			return t.start
+		}
+	}
+	return tokens[len(tokens)-1].start
+}
+
+// wordEnd сдвигает позицию к концу последнего слова, начавшегося до неё.
+func wordEnd(text string, tokens []token, pos int) int {
+	if pos >= len(text) {
+		return len(text)
+	}
+	end := 0
+	for _, t := range tokens {
+		if t.start >= pos {
+			break
+		}
+		end = t.end
+	}
+	return end
+}
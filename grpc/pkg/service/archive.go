@@ -0,0 +1,406 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+/*
+	Выгрузка и загрузка заметок (резервная копия, переезд между хранилищами).
+
+	Export отдаёт заметки вызывающего вместе с историей версий. Import записывает их
+	обратно через обычный порт NoteRepository: историю и последнюю версию одной
+	заметки — одним SaveMany, версия за версией, поэтому номера версий, история
+	и проверка версий в хранилище остаются такими же, как при обычной работе.
+	Если история неполная (заметку выгрузили без истории или она записана до её
+	появления), доступные версии записываются заново с номерами 1, 2, ...
+
+	Владелец импортированных заметок — вызывающий, а не тот, что записан в архиве:
+	импорт не может положить заметку в чужое пространство. Заметка с тем же ID,
+	но другого владельца, — конфликт в любом режиме.
+
+	Режимы (ImportMode):
+	  - ImportMerge — существующие заметки не трогаются. Та же заметка в той же
+	    версии — Unchanged, другое содержимое под тем же ID — конфликт ID_CONFLICT;
+	  - ImportReplace — заметка с тем же ID заменяется заметкой из архива вместе
+	    с историей (NoteRepository.Replace — одной операцией хранилища), а в конце
+	    окончательно удаляются заметки вызывающего, которых в архиве нет.
+
+	Вложения: в архиве только их метаданные, содержимого нет. Содержимое в BlobStore
+	общее для всех владельцев (адресация по SHA-256), поэтому ссылка на хеш — это
+	доступ к файлу. Импорт оставляет вложение, только если файл с этим хешем уже есть
+	в BlobStore и на него ссылается одна из текущих заметок вызывающего (importBlobs);
+	остальные вложения убираются из всех версий заметки с причиной ATTACHMENT_DROPPED.
+	Поэтому архив переносит вложения только в пределах того же сервера (того же BlobStore).
+
+	Импорт не атомарен: каждая заметка записывается отдельно, и оборванный поток
+	оставляет записанным то, что успело прийти. Целостность самого архива
+	(manifest с контрольной суммой) проверяет клиент до начала загрузки.
+*/
+
+// exportBatch — сколько заметок Export и очистка в ImportReplace читают из хранилища за раз.
+const exportBatch = 500
+
+// maxImportIDLen — предел длины ID импортируемой заметки.
+const maxImportIDLen = 128
+
+// ArchivedNote — заметка в архиве: последняя версия и предыдущие версии по возрастанию.
+type ArchivedNote struct {
+	Note      Note
+	Revisions []Note
+}
+
+// ExportOptions — что не выгружать; по умолчанию выгружается всё.
+type ExportOptions struct {
+	SkipTrash   bool
+	SkipHistory bool
+}
+
+// ImportMode — что делать с уже существующими заметками вызывающего.
+type ImportMode int
+
+const (
+	ImportMerge ImportMode = iota
+	ImportReplace
+)
+
+// Причины, по которым заметка архива не импортирована (ImportIssue.Reason).
+const (
+	ImportIDConflict      = "ID_CONFLICT"
+	ImportForeignOwner    = "OWNED_BY_ANOTHER_USER"
+	ImportDuplicateID     = "DUPLICATE_ID"
+	ImportInvalidNote     = "INVALID_NOTE"
+	ImportVersionConflict = "VERSION_CONFLICT"
+	// ImportAttachmentDropped — заметка импортирована, но без части вложений (см. importBlobs).
+	ImportAttachmentDropped = "ATTACHMENT_DROPPED"
+)
+
+// ImportIssue — заметка архива, которая не импортирована (или, для ATTACHMENT_DROPPED,
+// импортирована не целиком). Index — её номер в потоке.
+type ImportIssue struct {
+	Index   int
+	ID      string
+	Reason  string
+	Message string
+}
+
+// ImportResult — итог импорта.
+type ImportResult struct {
+	Received  int
+	Imported  int
+	Unchanged int
+	Deleted   int
+	Issues    []ImportIssue
+}
+
+func (s *noteService) Export(ctx context.Context, opts ExportOptions, fn func(ArchivedNote) error) error {
+	for _, trash := range []bool{false, true} {
+		if trash && opts.SkipTrash {
+			continue
+		}
+		err := s.eachOwned(ctx, trash, func(n Note) error {
+			a := ArchivedNote{Note: n}
+			if !opts.SkipHistory {
+				revs, err := s.repo.ListRevisions(ctx, n.ID, n.Version, 0)
+				if err != nil {
+					return err
+				}
+				slices.Reverse(revs)
+				a.Revisions = revs
+			}
+			return fn(a)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// eachOwned проходит заметки вызывающего (в корзине или вне её) пачками по exportBatch.
+func (s *noteService) eachOwned(ctx context.Context, trash bool, fn func(Note) error) error {
+	q := ListQuery{OwnerID: ownerFrom(ctx), Trash: trash, Limit: exportBatch}
+	for {
+		notes, err := s.repo.List(ctx, q)
+		if err != nil {
+			return err
+		}
+		for _, n := range notes {
+			if err := fn(n); err != nil {
+				return err
+			}
+		}
+		if len(notes) < exportBatch {
+			return nil
+		}
+		q.After = CursorOf(notes[len(notes)-1])
+	}
+}
+
+func (s *noteService) Import(ctx context.Context, mode ImportMode, next func() (ArchivedNote, error)) (ImportResult, error) {
+	var res ImportResult
+	// ID всех заметок архива, в том числе не импортированных: ImportReplace не должен
+	// удалять заметку только потому, что её копия в архиве не прошла проверку.
+	inArchive := make(map[string]bool)
+	blobs := &importBlobs{s: s, checked: make(map[string]bool)}
+	for i := 0; ; i++ {
+		a, err := next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return res, err
+		}
+		res.Received++
+		id := a.Note.ID
+		issue := func(reason, format string, args ...any) {
+			res.Issues = append(res.Issues, ImportIssue{Index: i, ID: id, Reason: reason, Message: fmt.Sprintf(format, args...)})
+		}
+
+		if inArchive[id] {
+			issue(ImportDuplicateID, "note %s appears in the archive more than once", id)
+			continue
+		}
+		inArchive[id] = true
+		version := a.Note.Version // до перенумерации: с ней сверяется то, что уже лежит в хранилище
+		if e := normalizeArchived(&a, ownerFrom(ctx)); e != nil {
+			issue(ImportInvalidNote, "%s", e.Error())
+			continue
+		}
+
+		cur, err := s.repo.GetByID(ctx, id)
+		exists := err == nil
+		switch {
+		case errors.Is(err, ErrRecordNotFound):
+		case err != nil:
+			return res, err
+		case cur.OwnerID != a.Note.OwnerID:
+			issue(ImportForeignOwner, "note %s belongs to another user", id)
+			continue
+		case sameVersion(cur, a.Note, version):
+			res.Unchanged++
+			continue
+		case mode == ImportMerge:
+			issue(ImportIDConflict, "note %s already exists at version %d (archive has version %d)", id, cur.Version, version)
+			continue
+		}
+
+		states := append(slices.Clip(a.Revisions), a.Note)
+		dropped, err := blobs.filter(ctx, states)
+		if err != nil {
+			return res, err
+		}
+		if exists {
+			// Старая заметка удаляется в той же операции хранилища, что пишется новая:
+			// сбой или конфликт не оставят ID ни без старой, ни без новой версии.
+			err = s.repo.Replace(ctx, id, cur.Version, states)
+		} else {
+			err = s.repo.SaveMany(ctx, states)
+		}
+		if errors.Is(err, ErrVersionConflict) || errors.Is(err, ErrRecordNotFound) {
+			issue(ImportVersionConflict, "note %s changed during import", id)
+			continue
+		}
+		if err != nil {
+			return res, err
+		}
+		n := states[len(states)-1]
+		typ := EventCreated
+		if n.Deleted() {
+			typ = EventDeleted // в корзине: подписчики видят её удалённой, поиск — не видит
+		}
+		if exists {
+			s.afterReplace(cur, typ, n)
+		} else {
+			s.afterWrite(typ, n)
+		}
+		res.Imported++
+		if len(dropped) > 0 {
+			issue(ImportAttachmentDropped, "note %s imported without attachments %s: their content is not on this server or not available to the caller",
+				id, strings.Join(dropped, ", "))
+		}
+	}
+
+	if mode == ImportReplace {
+		deleted, err := s.purgeAbsent(ctx, inArchive)
+		res.Deleted = deleted
+		if err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+// purgeAbsent окончательно удаляет заметки вызывающего, которых нет в keep.
+// Заметку, изменённую параллельно, пропускает (как PurgeTrash).
+func (s *noteService) purgeAbsent(ctx context.Context, keep map[string]bool) (int, error) {
+	deleted := 0
+	for _, trash := range []bool{false, true} {
+		err := s.eachOwned(ctx, trash, func(n Note) error {
+			if keep[n.ID] {
+				return nil
+			}
+			err := s.repo.Delete(ctx, n.ID, n.Version)
+			if errors.Is(err, ErrVersionConflict) || errors.Is(err, ErrRecordNotFound) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			deleted++
+			s.afterWrite(EventPurged, n)
+			return nil
+		})
+		if err != nil {
+			return deleted, err
+		}
+	}
+	return deleted, nil
+}
+
+// importBlobs решает, на какое содержимое могут ссылаться вложения импортируемых заметок:
+// на файлы, которые есть в BlobStore и на которые ссылаются текущие заметки вызывающего
+// (в том числе в корзине). Без этой проверки архив с подобранным чужим хешем дал бы
+// доступ к чужому файлу через DownloadAttachment.
+type importBlobs struct {
+	s       *noteService
+	owned   map[string]bool // хеши вложений заметок вызывающего; nil — ещё не собраны
+	checked map[string]bool // решения по уже встреченным хешам
+}
+
+func (b *importBlobs) allowed(ctx context.Context, sum string) (bool, error) {
+	if ok, seen := b.checked[sum]; seen {
+		return ok, nil
+	}
+	if b.s.blobs == nil {
+		return false, nil
+	}
+	if b.owned == nil {
+		// Собираем один раз и лениво: архив без вложений не читает заметки вызывающего.
+		b.owned = make(map[string]bool)
+		for _, trash := range []bool{false, true} {
+			err := b.s.eachOwned(ctx, trash, func(n Note) error {
+				for _, a := range n.Attachments {
+					b.owned[a.SHA256] = true
+				}
+				return nil
+			})
+			if err != nil {
+				return false, err
+			}
+		}
+	}
+	ok := b.owned[sum]
+	if ok {
+		rc, err := b.s.blobs.Open(ctx, sum)
+		switch {
+		case errors.Is(err, ErrRecordNotFound):
+			ok = false
+		case err != nil:
+			return false, err
+		default:
+			rc.Close()
+		}
+	}
+	b.checked[sum] = ok
+	return ok, nil
+}
+
+// filter убирает из всех версий заметки вложения, содержимое которых недоступно,
+// и возвращает их имена (без повторов по ID вложения).
+func (b *importBlobs) filter(ctx context.Context, states []Note) ([]string, error) {
+	var (
+		dropped []string
+		seen    = make(map[string]bool)
+	)
+	for i := range states {
+		kept := states[i].Attachments[:0:0]
+		for _, at := range states[i].Attachments {
+			ok, err := b.allowed(ctx, at.SHA256)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				kept = append(kept, at)
+				continue
+			}
+			if !seen[at.ID] {
+				seen[at.ID] = true
+				dropped = append(dropped, fmt.Sprintf("%q", at.Name))
+			}
+		}
+		if len(kept) == 0 {
+			kept = nil
+		}
+		states[i].Attachments = kept
+	}
+	return dropped, nil
+}
+
+// sameVersion — лежит ли в хранилище та же версия заметки, что в архиве
+// (version — её номер в архиве). Архив хранит время с наносекундами,
+// поэтому оно сравнивается точно.
+func sameVersion(cur, n Note, version int64) bool {
+	return cur.Version == version &&
+		cur.UpdatedAt.Equal(n.UpdatedAt) &&
+		cur.DeletedAt.Equal(n.DeletedAt) &&
+		cur.Title == n.Title &&
+		cur.Content == n.Content &&
+		slices.Equal(cur.Tags, n.Tags)
+}
+
+// normalizeArchived проверяет заметку архива и готовит её к записи: владелец — owner,
+// теги — в каноническом виде, версии — подряд с 1 (если история неполная, перенумеровываются).
+func normalizeArchived(a *ArchivedNote, owner string) *Error {
+	id := a.Note.ID
+	switch {
+	case id == "":
+		return InvalidArgument("id", "ID_REQUIRED", "id is required")
+	case len(id) > maxImportIDLen || strings.ContainsFunc(id, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '_' && r != '.'
+	}):
+		return InvalidArgument("id", "INVALID_ID", "id must be at most %d letters, digits, '-', '_' or '.'", maxImportIDLen)
+	}
+
+	states := append(slices.Clip(a.Revisions), a.Note)
+	consecutive := true
+	for i := range states {
+		n := &states[i]
+		if n.ID != id {
+			return InvalidArgument("revisions", "REVISION_ID_MISMATCH", "revision belongs to note %q", n.ID)
+		}
+		if n.Title == "" {
+			return errTitleRequired()
+		}
+		if i > 0 && n.Version <= states[i-1].Version {
+			return InvalidArgument("revisions", "INVALID_VERSION", "versions must increase, got %d after %d", n.Version, states[i-1].Version)
+		}
+		tags, err := NormalizeTags("tags", n.Tags)
+		if err != nil {
+			return err.(*Error)
+		}
+		if len(tags) > MaxTagsPerNote {
+			return errTooManyTags("tags")
+		}
+		for _, at := range n.Attachments {
+			if at.ID == "" || !isHexSHA256(at.SHA256) {
+				return InvalidArgument("attachments", "INVALID_ATTACHMENT", "attachment %q has no id or a malformed sha256", at.Name)
+			}
+		}
+		n.Tags = tags
+		n.OwnerID = owner
+		consecutive = consecutive && n.Version == int64(i+1)
+	}
+	if !consecutive {
+		for i := range states {
+			states[i].Version = int64(i + 1)
+		}
+	}
+	a.Revisions, a.Note = states[:len(states)-1], states[len(states)-1]
+	return nil
+}
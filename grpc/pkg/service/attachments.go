@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+/*
+	Вложения.
+
+	Метаданные вложения (Attachment) — часть заметки: добавление вложения — обычное
+	изменение с новой версией, оно попадает в историю и проверяется по expected_version.
+	Само содержимое хранится отдельно, в BlobStore, по SHA-256.
+
+	Загрузка: сервис проверяет заметку и метаданные до того, как читать данные, затем
+	BlobStore.Put читает поток, соблюдая лимит размера, и в конце сверяет SHA-256 с тем,
+	что заявил клиент. Только после этого вложение записывается в заметку.
+	Если запись заметки не удалась (например, конфликт версий), содержимое остаётся
+	в хранилище без ссылки на него — как и у вложений, которых больше нет в заметке.
+*/
+
+// Ограничения на вложения.
+const (
+	// DefaultMaxAttachmentSize — лимит размера одного вложения по умолчанию (см. WithMaxAttachmentSize).
+	DefaultMaxAttachmentSize = 25 << 20 // 25 MiB
+	MaxAttachmentsPerNote    = 100
+	MaxAttachmentNameLen     = 255
+
+	defaultContentType = "application/octet-stream"
+)
+
+// Attachment — файл, прикреплённый к заметке.
+type Attachment struct {
+	ID          string
+	Name        string
+	ContentType string
+	Size        int64
+	// SHA256 — хеш содержимого в hex, он же ключ в BlobStore.
+	SHA256    string
+	CreatedAt time.Time
+}
+
+// AttachmentInput — то, что клиент сообщает о загружаемом файле до самих данных.
+type AttachmentInput struct {
+	Name        string
+	ContentType string // пусто — application/octet-stream
+	// SHA256 — хеш всего содержимого в hex; сверяется после загрузки.
+	SHA256 string
+	// ExpectedVersion — как в NoteUpdate: не совпала — KindAborted, 0 — без проверки.
+	ExpectedVersion int64
+}
+
+// Порт хранилища содержимого вложений.
+type BlobStore interface {
+	// Put читает r до конца и сохраняет содержимое под ключом sum (SHA-256 в hex).
+	// Больше limit байт — ErrBlobTooLarge, хеш не совпал — ErrChecksumMismatch;
+	// в обоих случаях ничего не сохраняется. Возвращает размер содержимого.
+	Put(ctx context.Context, r io.Reader, limit int64, sum string) (int64, error)
+	// Open открывает содержимое по ключу; его нет — ErrRecordNotFound.
+	Open(ctx context.Context, sum string) (io.ReadCloser, error)
+}
+
+// Ошибки BlobStore.Put.
+var (
+	ErrBlobTooLarge     = errors.New("blob too large")
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+)
+
+// WithBlobStore включает вложения; без него UploadAttachment и OpenAttachment
+// возвращают KindFailedPrecondition.
+func WithBlobStore(b BlobStore) Option {
+	return func(s *noteService) { s.blobs = b }
+}
+
+// WithMaxAttachmentSize — лимит размера одного вложения в байтах (по умолчанию DefaultMaxAttachmentSize).
+func WithMaxAttachmentSize(n int64) Option {
+	return func(s *noteService) { s.maxAttachmentSize = n }
+}
+
+func (s *noteService) UploadAttachment(ctx context.Context, noteID string, in AttachmentInput, r io.Reader) (Note, Attachment, error) {
+	if s.blobs == nil {
+		return Note{}, Attachment{}, errAttachmentsDisabled()
+	}
+	if err := normalizeAttachmentInput(&in); err != nil {
+		return Note{}, Attachment{}, err
+	}
+	// Заметку проверяем до чтения данных, чтобы не принимать файл, который некуда прикрепить.
+	n, err := s.getOwned(ctx, noteID)
+	if err != nil {
+		return Note{}, Attachment{}, err
+	}
+	if in.ExpectedVersion != 0 && n.Version != in.ExpectedVersion {
+		return Note{}, Attachment{}, VersionMismatch(noteID, in.ExpectedVersion, n.Version)
+	}
+	if len(n.Attachments) >= MaxAttachmentsPerNote {
+		return Note{}, Attachment{}, errTooManyAttachments()
+	}
+
+	size, err := s.blobs.Put(ctx, r, s.maxAttachmentSize, in.SHA256)
+	switch {
+	case errors.Is(err, ErrBlobTooLarge):
+		return Note{}, Attachment{}, AttachmentTooLarge(s.maxAttachmentSize)
+	case errors.Is(err, ErrChecksumMismatch):
+		return Note{}, Attachment{}, ChecksumMismatch(in.SHA256)
+	case err != nil:
+		return Note{}, Attachment{}, err
+	}
+
+	a := Attachment{
+		ID:          uuid.NewString(),
+		Name:        in.Name,
+		ContentType: in.ContentType,
+		Size:        size,
+		SHA256:      in.SHA256,
+		CreatedAt:   time.Now(),
+	}
+	n, err = s.modify(ctx, noteID, in.ExpectedVersion, s.getOwned, EventUpdated, func(n *Note) error {
+		if len(n.Attachments) >= MaxAttachmentsPerNote {
+			return errTooManyAttachments()
+		}
+		n.Attachments = append(slices.Clip(n.Attachments), a)
+		return nil
+	})
+	if err != nil {
+		return Note{}, Attachment{}, err
+	}
+	return n, a, nil
+}
+
+// OpenAttachment возвращает вложение и его содержимое; закрыть reader — забота вызывающего.
+// Если содержимое на диске испорчено, чтение закончится ошибкой, а не io.EOF.
+func (s *noteService) OpenAttachment(ctx context.Context, noteID, attachmentID string) (Attachment, io.ReadCloser, error) {
+	if s.blobs == nil {
+		return Attachment{}, nil, errAttachmentsDisabled()
+	}
+	n, err := s.getOwned(ctx, noteID)
+	if err != nil {
+		return Attachment{}, nil, err
+	}
+	i := slices.IndexFunc(n.Attachments, func(a Attachment) bool { return a.ID == attachmentID })
+	if i < 0 {
+		return Attachment{}, nil, AttachmentNotFound(noteID, attachmentID)
+	}
+	a := n.Attachments[i]
+	rc, err := s.blobs.Open(ctx, a.SHA256)
+	if err != nil {
+		// Ссылка в заметке есть, а содержимого нет — это сбой хранилища, а не NotFound.
+		return Attachment{}, nil, err
+	}
+	return a, rc, nil
+}
+
+// normalizeAttachmentInput проверяет метаданные вложения и приводит их к каноническому виду:
+// хеш — в нижний регистр, тип содержимого — через mime (пустой — application/octet-stream).
+func normalizeAttachmentInput(in *AttachmentInput) error {
+	in.Name = strings.TrimSpace(in.Name)
+	switch {
+	case in.Name == "":
+		return InvalidArgument("name", "ATTACHMENT_NAME_REQUIRED", "name is required")
+	case utf8.RuneCountInString(in.Name) > MaxAttachmentNameLen || !utf8.ValidString(in.Name):
+		return InvalidArgument("name", "INVALID_ATTACHMENT_NAME", "name must be valid UTF-8 of at most %d characters", MaxAttachmentNameLen)
+	case strings.ContainsFunc(in.Name, func(r rune) bool { return r == '/' || r == '\\' || unicode.IsControl(r) }):
+		return InvalidArgument("name", "INVALID_ATTACHMENT_NAME", "name must be a file name without directories")
+	}
+
+	in.SHA256 = strings.ToLower(in.SHA256)
+	if !isHexSHA256(in.SHA256) {
+		return InvalidArgument("sha256", "INVALID_CHECKSUM", "sha256 must be 64 hex characters")
+	}
+
+	if in.ContentType == "" {
+		in.ContentType = defaultContentType
+		return nil
+	}
+	typ, params, err := mime.ParseMediaType(in.ContentType)
+	if err != nil {
+		return InvalidArgument("content_type", "INVALID_CONTENT_TYPE", "content_type is not a valid media type")
+	}
+	in.ContentType = mime.FormatMediaType(typ, params)
+	return nil
+}
+
+func isHexSHA256(s string) bool {
+	if len(s) != 64 {
+		return false
+	}
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func errTooManyAttachments() *Error {
+	return InvalidArgument("attachments", "TOO_MANY_ATTACHMENTS", "a note can have at most %d attachments", MaxAttachmentsPerNote)
+}
+
+func errAttachmentsDisabled() *Error {
+	return &Error{Kind: KindFailedPrecondition, Reason: "ATTACHMENTS_DISABLED", Message: "attachments are not configured on this server"}
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// ErrorKind — категория доменной ошибки. Транспорт по ней выбирает код ответа
+// (gRPC-код, HTTP-статус), сервис про транспорт ничего не знает.
+type ErrorKind int
+
+const (
+	KindInvalidArgument ErrorKind = iota + 1
+	KindNotFound
+	KindOutOfRange
+	KindPermissionDenied
+	KindFailedPrecondition
+	KindAborted
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case KindInvalidArgument:
+		return "invalid argument"
+	case KindNotFound:
+		return "not found"
+	case KindOutOfRange:
+		return "out of range"
+	case KindPermissionDenied:
+		return "permission denied"
+	case KindFailedPrecondition:
+		return "failed precondition"
+	case KindAborted:
+		return "aborted"
+	default:
+		return "unknown"
+	}
+}
+
+// Error — структурированная доменная ошибка.
+//
+//	Kind     — категория (см. ErrorKind);
+//	Field    — поле запроса, которое не прошло проверку (для KindInvalidArgument);
+//	Reason   — машиночитаемая причина в UPPER_SNAKE_CASE, например "TITLE_REQUIRED";
+//	Message  — описание для человека;
+//	Metadata — дополнительные подробности (например, id ненайденной заметки);
+//	Err      — исходная ошибка, если есть (доступна через errors.Unwrap/Is/As).
+type Error struct {
+	Kind     ErrorKind
+	Field    string
+	Reason   string
+	Message  string
+	Metadata map[string]string
+	Err      error
+}
+
+func (e *Error) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = e.Kind.String()
+	}
+	if e.Field != "" {
+		msg = e.Field + ": " + msg
+	}
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Is позволяет по-прежнему проверять ошибки через errors.Is(err, ErrNotFound)
+// и errors.Is(err, ErrBadRequest): совпадение — по категории.
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.Kind == KindNotFound
+	case ErrBadRequest:
+		return e.Kind == KindInvalidArgument
+	}
+	return false
+}
+
+// InvalidArgument — ошибка валидации конкретного поля запроса.
+func InvalidArgument(field, reason, format string, args ...any) *Error {
+	return &Error{Kind: KindInvalidArgument, Field: field, Reason: reason, Message: fmt.Sprintf(format, args...)}
+}
+
+// NoteNotFound — заметки с таким ID нет. cause — исходная ошибка хранилища.
+func NoteNotFound(id string, cause error) *Error {
+	return &Error{
+		Kind:     KindNotFound,
+		Reason:   "NOTE_NOT_FOUND",
+		Message:  "note not found",
+		Metadata: map[string]string{"id": id},
+		Err:      cause,
+	}
+}
+
+// RevisionNotFound — у заметки нет сохранённой версии с таким номером.
+func RevisionNotFound(id string, version int64) *Error {
+	return &Error{
+		Kind:     KindNotFound,
+		Reason:   "REVISION_NOT_FOUND",
+		Message:  fmt.Sprintf("note has no revision %d", version),
+		Metadata: map[string]string{"id": id, "version": strconv.FormatInt(version, 10)},
+	}
+}
+
+// AttachmentTooLarge — загружаемый файл больше лимита.
+func AttachmentTooLarge(limit int64) *Error {
+	return &Error{
+		Kind:     KindInvalidArgument,
+		Field:    "chunk",
+		Reason:   "ATTACHMENT_TOO_LARGE",
+		Message:  fmt.Sprintf("attachment is larger than %d bytes", limit),
+		Metadata: map[string]string{"limit": strconv.FormatInt(limit, 10)},
+	}
+}
+
+// ChecksumMismatch — SHA-256 принятых данных не совпал с заявленным: файл повреждён
+// в пути или загружен не целиком.
+func ChecksumMismatch(want string) *Error {
+	return &Error{
+		Kind:     KindInvalidArgument,
+		Field:    "sha256",
+		Reason:   "CHECKSUM_MISMATCH",
+		Message:  "uploaded data does not match sha256",
+		Metadata: map[string]string{"sha256": want},
+	}
+}
+
+// AttachmentNotFound — у заметки нет вложения с таким ID.
+func AttachmentNotFound(noteID, attachmentID string) *Error {
+	return &Error{
+		Kind:     KindNotFound,
+		Reason:   "ATTACHMENT_NOT_FOUND",
+		Message:  "attachment not found",
+		Metadata: map[string]string{"id": noteID, "attachment_id": attachmentID},
+	}
+}
+
+// NoteAccessDenied — заметка есть, но принадлежит другому пользователю.
+func NoteAccessDenied(id string) *Error {
+	return &Error{
+		Kind:     KindPermissionDenied,
+		Reason:   "NOTE_ACCESS_DENIED",
+		Message:  "note belongs to another user",
+		Metadata: map[string]string{"id": id},
+	}
+}
+
+// NoteNotInTrash — Restore или Purge для заметки, которая не в корзине.
+func NoteNotInTrash(id string) *Error {
+	return &Error{
+		Kind:     KindFailedPrecondition,
+		Reason:   "NOTE_NOT_IN_TRASH",
+		Message:  "note is not in trash",
+		Metadata: map[string]string{"id": id},
+	}
+}
+
+// VersionMismatch — заметку изменили: её версия уже не та, что ожидал клиент.
+// В Metadata — текущая версия, чтобы клиент мог перечитать заметку и повторить.
+func VersionMismatch(id string, expected, current int64) *Error {
+	return &Error{
+		Kind:    KindAborted,
+		Reason:  "VERSION_MISMATCH",
+		Message: fmt.Sprintf("note version is %d, expected %d", current, expected),
+		Metadata: map[string]string{
+			"id":               id,
+			"expected_version": strconv.FormatInt(expected, 10),
+			"current_version":  strconv.FormatInt(current, 10),
+		},
+	}
+}
+
+// IdempotencyKeyReused — ключ идемпотентности уже использован для запроса с другими данными.
+func IdempotencyKeyReused(key string) *Error {
+	return &Error{
+		Kind:     KindFailedPrecondition,
+		Reason:   "IDEMPOTENCY_KEY_REUSED",
+		Message:  "idempotency_key was already used with a different request",
+		Metadata: map[string]string{"idempotency_key": key},
+	}
+}
+
+// errTitleRequired — общая для Create/Update/BulkCreate проверка заголовка.
+func errTitleRequired() *Error {
+	return InvalidArgument("title", "TITLE_REQUIRED", "title is required")
+}
+
+// AsError достаёт *Error из цепочки ошибок.
+func AsError(err error) (*Error, bool) {
+	var e *Error
+	ok := errors.As(err, &e)
+	return e, ok
+}
@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// EventType — что произошло с заметкой.
+type EventType int
+
+const (
+	EventCreated EventType = iota + 1
+	EventUpdated
+	EventDeleted  // заметка ушла в корзину
+	EventRestored // заметку вернули из корзины
+	EventPurged   // заметку удалили окончательно
+)
+
+// NoteEvent — событие изменения заметки.
+// Seq монотонно растёт в пределах процесса: по нему подписчик может продолжить поток.
+// Для EventDeleted и EventPurged в Note лежит последнее известное состояние заметки.
+type NoteEvent struct {
+	Seq  uint64
+	Type EventType
+	Note Note
+	At   time.Time
+}
+
+// Ошибки подписки.
+var (
+	// ErrSlowConsumer — подписчик не успевал читать, его буфер переполнился и он отключён.
+	// Продолжить можно подпиской с последнего полученного Seq + 1.
+	ErrSlowConsumer = errors.New("subscriber too slow, events dropped")
+	// ErrSeqExpired — запрошенный Seq уже вытеснен из истории шины.
+	ErrSeqExpired = &Error{
+		Kind:    KindOutOfRange,
+		Field:   "from_seq",
+		Reason:  "SEQ_EXPIRED",
+		Message: "requested sequence is no longer retained",
+	}
+)
+
+// Значения шины по умолчанию.
+const (
+	DefaultEventHistory = 1024
+	DefaultEventBuffer  = 64
+)
+
+// EventBus — in-process шина событий о заметках.
+//
+// Политика для медленных подписчиков: Publish никогда не блокируется.
+// У каждого подписчика свой буфер; если он переполнен, подписчик отключается
+// с ErrSlowConsumer. Писатели (Create/Update/Delete) от читателей не зависят.
+//
+// Последние historySize событий хранятся в кольцевом буфере, чтобы подписчик
+// мог переподключиться и продолжить с нужного Seq.
+type EventBus struct {
+	mu          sync.Mutex
+	seq         uint64
+	history     []NoteEvent // кольцевой буфер
+	historySize int
+	bufferSize  int
+	subs        map[*Subscription]struct{}
+}
+
+// BusOption — функциональная опция EventBus.
+type BusOption func(*EventBus)
+
+// WithHistorySize задаёт, сколько последних событий хранить для возобновления подписки.
+func WithHistorySize(n int) BusOption {
+	return func(b *EventBus) { b.historySize = n }
+}
+
+// WithSubscriberBuffer задаёт размер буфера каждого подписчика.
+func WithSubscriberBuffer(n int) BusOption {
+	return func(b *EventBus) { b.bufferSize = n }
+}
+
+func NewEventBus(opts ...BusOption) *EventBus {
+	b := &EventBus{
+		historySize: DefaultEventHistory,
+		bufferSize:  DefaultEventBuffer,
+		subs:        make(map[*Subscription]struct{}),
+	}
+	for _, o := range opts {
+		o(b)
+	}
+	b.history = make([]NoteEvent, 0, b.historySize)
+	return b
+}
+
+// Publish присваивает событию следующий Seq и раздаёт его подписчикам.
+func (b *EventBus) Publish(typ EventType, n Note) NoteEvent {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.seq++
+	ev := NoteEvent{Seq: b.seq, Type: typ, Note: n, At: time.Now()}
+
+	if b.historySize > 0 {
+		if len(b.history) < b.historySize {
+			b.history = append(b.history, ev)
+		} else {
+			b.history[int((ev.Seq-1)%uint64(b.historySize))] = ev
+		}
+	}
+
+	for sub := range b.subs {
+		if !sub.wants(ev) {
+			continue
+		}
+		select {
+		case sub.ch <- ev:
+		default:
+			b.dropLocked(sub, ErrSlowConsumer)
+		}
+	}
+	return ev
+}
+
+// Subscribe создаёт подписку.
+// fromSeq == 0 — только новые события; иначе сначала придут сохранённые события
+// начиная с fromSeq, затем — новые. match отбирает события для подписчика
+// (nil — все); Seq пропущенных событий в потоке просто не будет.
+func (b *EventBus) Subscribe(fromSeq uint64, match func(NoteEvent) bool) (*Subscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var backlog []NoteEvent
+	if fromSeq != 0 {
+		if fromSeq > b.seq+1 {
+			return nil, InvalidArgument("from_seq", "SEQ_AHEAD", "from_seq %d is ahead of the last event %d", fromSeq, b.seq)
+		}
+		backlog = b.sinceLocked(fromSeq)
+		if backlog == nil {
+			return nil, ErrSeqExpired
+		}
+	}
+
+	sub := &Subscription{bus: b, match: match, ch: make(chan NoteEvent, b.bufferSize+len(backlog))}
+	for _, ev := range backlog {
+		if sub.wants(ev) {
+			sub.ch <- ev
+		}
+	}
+	b.subs[sub] = struct{}{}
+	return sub, nil
+}
+
+// sinceLocked возвращает события с Seq >= from в порядке возрастания
+// или nil, если часть из них уже вытеснена.
+func (b *EventBus) sinceLocked(from uint64) []NoteEvent {
+	out := []NoteEvent{}
+	if from == b.seq+1 {
+		return out
+	}
+	oldest := b.seq - uint64(len(b.history)) + 1
+	if from < oldest {
+		return nil
+	}
+	for s := from; s <= b.seq; s++ {
+		out = append(out, b.history[int((s-1)%uint64(b.historySize))])
+	}
+	return out
+}
+
+func (b *EventBus) dropLocked(sub *Subscription, err error) {
+	if _, ok := b.subs[sub]; !ok {
+		return
+	}
+	delete(b.subs, sub)
+	sub.err = err
+	close(sub.ch)
+}
+
+// Subscription — подписка на события шины.
+type Subscription struct {
+	bus   *EventBus
+	match func(NoteEvent) bool
+	ch    chan NoteEvent
+	err   error
+	stop  func() bool // снимает привязку к контексту, если она есть
+}
+
+func (s *Subscription) wants(ev NoteEvent) bool {
+	return s.match == nil || s.match(ev)
+}
+
+// Events — канал событий. Закрывается при Close или при отключении подписчика;
+// причину отключения вернёт Err.
+func (s *Subscription) Events() <-chan NoteEvent {
+	return s.ch
+}
+
+// Err возвращает причину закрытия канала (nil — закрыт через Close).
+// Читать имеет смысл только после закрытия Events.
+func (s *Subscription) Err() error {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	return s.err
+}
+
+// Close отписывается от шины. Повторный вызов безопасен.
+func (s *Subscription) Close() {
+	s.bus.mu.Lock()
+	stop := s.stop
+	s.bus.dropLocked(s, nil)
+	s.bus.mu.Unlock()
+	if stop != nil {
+		stop()
+	}
+}
+
+// closeOnDone закрывает подписку, когда ctx отменён.
+func (s *Subscription) closeOnDone(ctx context.Context) {
+	stop := context.AfterFunc(ctx, s.Close)
+	s.bus.mu.Lock()
+	s.stop = stop
+	s.bus.mu.Unlock()
+}
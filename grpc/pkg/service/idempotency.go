@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"sync"
+	"time"
+)
+
+/*
+	Идемпотентность CreateNote.
+
+	Сервер сам выдаёт ID (uuid), поэтому повтор CreateNote после таймаута создал бы дубликат:
+	клиент не знает, дошёл ли первый запрос. С ключом идемпотентности повтор узнаётся:
+
+	  ключ новый                          -> создаём заметку и запоминаем ключ -> заметка;
+	  ключ есть, данные те же             -> возвращаем ту же заметку, ничего не создавая;
+	  ключ есть, данные другие            -> KindFailedPrecondition (IDEMPOTENCY_KEY_REUSED);
+	  первый запрос с этим ключом ещё идёт -> ждём его и отдаём его результат.
+
+	Ключи живут window после создания заметки и разделены по владельцам: чужой ключ
+	не совпадёт с вашим. Если первая попытка завершилась ошибкой, ключ не запоминается —
+	повтор выполнится заново. Хранилище ключей — в памяти процесса: после перезапуска
+	или на другом экземпляре сервера повтор создаст новую заметку.
+*/
+
+// DefaultIdempotencyWindow — сколько по умолчанию помнить ключ.
+const DefaultIdempotencyWindow = time.Hour
+
+// maxIdempotencyKeyLen — предел длины ключа: UUID с запасом, но не произвольный текст.
+const maxIdempotencyKeyLen = 128
+
+type idempotencyStore struct {
+	window time.Duration
+
+	mu      sync.Mutex
+	entries map[idemKey]*idemEntry
+	// expiry — ключи в порядке истечения: окно одинаковое, поэтому это порядок завершения.
+	expiry []idemExpiry
+}
+
+type idemKey struct {
+	owner, key string
+}
+
+type idemEntry struct {
+	fingerprint [sha256.Size]byte
+	done        chan struct{} // закрывается, когда первый запрос завершился
+	note        Note          // результат; читать только после done
+	err         error
+}
+
+type idemExpiry struct {
+	key   idemKey
+	entry *idemEntry
+	at    time.Time
+}
+
+func newIdempotencyStore(window time.Duration) *idempotencyStore {
+	return &idempotencyStore{window: window, entries: make(map[idemKey]*idemEntry)}
+}
+
+// do выполняет create не больше одного раза на (owner, key) в пределах окна.
+func (s *idempotencyStore) do(ctx context.Context, owner, key string, in NoteInput,
+	create func(context.Context, NoteInput) (Note, error)) (Note, error) {
+	k := idemKey{owner: owner, key: key}
+	fp := fingerprint(in)
+	for {
+		e, first := s.reserve(k, fp)
+		if first {
+			n, err := create(ctx, in)
+			s.finish(k, e, n, err)
+			return n, err
+		}
+		if e.fingerprint != fp {
+			return Note{}, IdempotencyKeyReused(key)
+		}
+		select {
+		case <-e.done:
+		case <-ctx.Done():
+			return Note{}, ctx.Err()
+		}
+		if e.err == nil {
+			return e.note, nil
+		}
+		// Первая попытка не удалась и ключ освобождён — пробуем сами.
+	}
+}
+
+// reserve возвращает запись ключа; first == true — записи не было, её создали
+// и выполнять запрос должен вызывающий.
+func (s *idempotencyStore) reserve(k idemKey, fp [sha256.Size]byte) (e *idemEntry, first bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prune(time.Now())
+	if e, ok := s.entries[k]; ok {
+		return e, false
+	}
+	e = &idemEntry{fingerprint: fp, done: make(chan struct{})}
+	s.entries[k] = e
+	return e, true
+}
+
+// finish публикует результат первого запроса. Ошибка — ключ забывается сразу.
+func (s *idempotencyStore) finish(k idemKey, e *idemEntry, n Note, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e.note, e.err = n, err
+	if err != nil {
+		delete(s.entries, k)
+	} else {
+		s.expiry = append(s.expiry, idemExpiry{key: k, entry: e, at: time.Now().Add(s.window)})
+	}
+	close(e.done)
+}
+
+// prune удаляет истёкшие ключи. Вызывается под s.mu.
+func (s *idempotencyStore) prune(now time.Time) {
+	i := 0
+	for ; i < len(s.expiry) && !s.expiry[i].at.After(now); i++ {
+		x := s.expiry[i]
+		if s.entries[x.key] == x.entry {
+			delete(s.entries, x.key)
+		}
+	}
+	if i > 0 {
+		s.expiry = append(s.expiry[:0], s.expiry[i:]...)
+	}
+}
+
+// fingerprint — отпечаток данных запроса: по нему повтор отличается от другого запроса
+// с тем же ключом. Длины полей входят в хеш, чтобы ("ab", "c") != ("a", "bc").
+func fingerprint(in NoteInput) [sha256.Size]byte {
+	h := sha256.New()
+	// Каждое поле — с длиной впереди, поэтому границы между ними однозначны;
+	// теги к этому моменту уже нормализованы (порядок не важен клиенту, но фиксирован).
+	for _, f := range append([]string{in.Title, in.Content}, in.Tags...) {
+		var n [8]byte
+		binary.BigEndian.PutUint64(n[:], uint64(len(f)))
+		h.Write(n[:])
+		h.Write([]byte(f))
+	}
+	var out [sha256.Size]byte
+	h.Sum(out[:0])
+	return out
+}
@@ -0,0 +1,261 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Размеры страницы ListNotes.
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 1000
+)
+
+// SortField — поле, по которому сортируется список заметок.
+type SortField int
+
+const (
+	SortByCreatedAt SortField = iota
+	SortByTitle
+)
+
+// ListOrder — порядок сортировки. При равенстве ключа порядок добивается по ID,
+// поэтому он всегда стабильный и подходит для курсорной пагинации.
+type ListOrder struct {
+	Field SortField
+	Desc  bool
+}
+
+// ParseOrder разбирает order_by из запроса: "created_at", "created_at desc", "title", "title desc".
+// Пустая строка — сортировка по времени создания по возрастанию.
+func ParseOrder(s string) (ListOrder, error) {
+	parts := strings.Fields(strings.ToLower(s))
+	if len(parts) == 0 {
+		return ListOrder{}, nil
+	}
+	if len(parts) > 2 {
+		return ListOrder{}, InvalidArgument("order_by", "INVALID_ORDER_BY", "invalid order_by %q", s)
+	}
+	var o ListOrder
+	switch parts[0] {
+	case "created_at":
+		o.Field = SortByCreatedAt
+	case "title":
+		o.Field = SortByTitle
+	default:
+		return ListOrder{}, InvalidArgument("order_by", "INVALID_ORDER_BY", "unknown order_by field %q", parts[0])
+	}
+	if len(parts) == 2 {
+		switch parts[1] {
+		case "asc":
+		case "desc":
+			o.Desc = true
+		default:
+			return ListOrder{}, InvalidArgument("order_by", "INVALID_ORDER_BY", "invalid order_by direction %q", parts[1])
+		}
+	}
+	return o, nil
+}
+
+func (o ListOrder) String() string {
+	s := "created_at"
+	if o.Field == SortByTitle {
+		s = "title"
+	}
+	if o.Desc {
+		s += " desc"
+	}
+	return s
+}
+
+// Compare сравнивает две заметки в этом порядке: <0 — a идёт раньше b.
+func (o ListOrder) Compare(a, b Note) int {
+	var c int
+	switch o.Field {
+	case SortByTitle:
+		c = strings.Compare(a.Title, b.Title)
+	default:
+		c = a.CreatedAt.Compare(b.CreatedAt)
+	}
+	if c == 0 {
+		c = strings.Compare(a.ID, b.ID)
+	}
+	if o.Desc {
+		c = -c
+	}
+	return c
+}
+
+// Cursor — позиция последней отданной заметки. Следующая страница начинается
+// строго после неё.
+type Cursor struct {
+	ID        string
+	Title     string
+	CreatedAt time.Time
+}
+
+// CursorOf строит курсор по заметке.
+func CursorOf(n Note) *Cursor {
+	return &Cursor{ID: n.ID, Title: n.Title, CreatedAt: n.CreatedAt}
+}
+
+// ListQuery — запрос к хранилищу (порт NoteRepository.List).
+// Адаптер обязан вернуть не больше Limit заметок в порядке Order,
+// подходящих под фильтр и лежащих строго после After (если он задан).
+// Limit <= 0 означает "без ограничения".
+//
+// Заметки всегда отбираются по владельцу OwnerID (пустой OwnerID — заметки без
+// владельца, созданные без аутентификации). AnyOwner снимает этот фильтр — только
+// для служебных выборок сервиса, например наполнения поискового индекса.
+//
+// Trash выбирает между заметками вне корзины (false) и в корзине (true);
+// вместе они в одну выборку не попадают.
+//
+// Tags (нормализованные, см. NormalizeTags) оставляют заметки с любым из тегов
+// или со всеми сразу — в зависимости от TagMatch. Адаптер должен отбирать их
+// по индексу тегов, а не перебором всех заметок владельца.
+type ListQuery struct {
+	OwnerID     string
+	AnyOwner    bool
+	Trash       bool
+	Order       ListOrder
+	TitlePrefix string
+	Tags        []string
+	TagMatch    TagMatch
+	After       *Cursor
+	Limit       int
+}
+
+// Match — подходит ли заметка под фильтр и курсор запроса.
+// Удобно для адаптеров, которые фильтруют в памяти.
+func (q ListQuery) Match(n Note) bool {
+	if !q.AnyOwner && n.OwnerID != q.OwnerID {
+		return false
+	}
+	if n.Deleted() != q.Trash {
+		return false
+	}
+	if q.TitlePrefix != "" && !strings.HasPrefix(n.Title, q.TitlePrefix) {
+		return false
+	}
+	if !HasTags(n.Tags, q.Tags, q.TagMatch) {
+		return false
+	}
+	if q.After != nil {
+		after := Note{ID: q.After.ID, Title: q.After.Title, CreatedAt: q.After.CreatedAt}
+		if q.Order.Compare(n, after) <= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// ListOptions — параметры ListNotes, как их прислал клиент.
+type ListOptions struct {
+	PageSize    int
+	PageToken   string
+	OrderBy     string
+	TitlePrefix string
+	Tags        []string
+	TagMatch    TagMatch
+}
+
+// NotePage — одна страница списка.
+// NextPageToken пустой, если страниц больше нет.
+type NotePage struct {
+	Notes         []Note
+	NextPageToken string
+}
+
+// pageToken — содержимое непрозрачного page_token.
+// Порядок и фильтр зашиты в токен, чтобы нельзя было поменять их посреди обхода.
+type pageToken struct {
+	Order     string   `json:"o"`
+	Prefix    string   `json:"p,omitempty"`
+	Trash     bool     `json:"d,omitempty"`
+	Tags      []string `json:"g,omitempty"`
+	TagMatch  TagMatch `json:"m,omitempty"`
+	ID        string   `json:"id"`
+	Title     string   `json:"t,omitempty"`
+	CreatedAt int64    `json:"c"`
+}
+
+func encodePageToken(q ListQuery, c *Cursor) string {
+	b, _ := json.Marshal(pageToken{
+		Order:     q.Order.String(),
+		Prefix:    q.TitlePrefix,
+		Trash:     q.Trash,
+		Tags:      q.Tags,
+		TagMatch:  q.TagMatch,
+		ID:        c.ID,
+		Title:     c.Title,
+		CreatedAt: c.CreatedAt.UnixNano(),
+	})
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodePageToken(s string, q ListQuery) (*Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, InvalidArgument("page_token", "INVALID_PAGE_TOKEN", "malformed page_token")
+	}
+	var t pageToken
+	if err := json.Unmarshal(b, &t); err != nil {
+		return nil, InvalidArgument("page_token", "INVALID_PAGE_TOKEN", "malformed page_token")
+	}
+	if t.Order != q.Order.String() || t.Prefix != q.TitlePrefix {
+		return nil, InvalidArgument("page_token", "PAGE_TOKEN_MISMATCH", "page_token does not match order_by/title_prefix")
+	}
+	if !slices.Equal(t.Tags, q.Tags) || t.TagMatch != q.TagMatch {
+		return nil, InvalidArgument("page_token", "PAGE_TOKEN_MISMATCH", "page_token does not match tags/tag_match")
+	}
+	if t.Trash != q.Trash {
+		return nil, InvalidArgument("page_token", "PAGE_TOKEN_MISMATCH", "page_token belongs to another listing")
+	}
+	return &Cursor{ID: t.ID, Title: t.Title, CreatedAt: time.Unix(0, t.CreatedAt)}, nil
+}
+
+// pageSize применяет к page_size значение по умолчанию и предел.
+func pageSize(size int) (int, error) {
+	switch {
+	case size < 0:
+		return 0, InvalidArgument("page_size", "INVALID_PAGE_SIZE", "page_size must be >= 0")
+	case size == 0:
+		return DefaultPageSize, nil
+	case size > MaxPageSize:
+		return MaxPageSize, nil
+	}
+	return size, nil
+}
+
+// listQuery превращает параметры клиента в запрос к хранилищу (к корзине, если trash).
+// Limit на единицу больше страницы — так мы узнаём, есть ли следующая.
+func listQuery(opts ListOptions, trash bool) (ListQuery, int, error) {
+	order, err := ParseOrder(opts.OrderBy)
+	if err != nil {
+		return ListQuery{}, 0, err
+	}
+	size, err := pageSize(opts.PageSize)
+	if err != nil {
+		return ListQuery{}, 0, err
+	}
+	tags, err := NormalizeTags("tags", opts.Tags)
+	if err != nil {
+		return ListQuery{}, 0, err
+	}
+	if len(tags) > MaxTagsPerQuery {
+		return ListQuery{}, 0, InvalidArgument("tags", "TOO_MANY_TAGS", "at most %d tags can be used in a filter", MaxTagsPerQuery)
+	}
+	q := ListQuery{Order: order, TitlePrefix: opts.TitlePrefix, Tags: tags, TagMatch: opts.TagMatch, Trash: trash, Limit: size + 1}
+	if opts.PageToken != "" {
+		c, err := decodePageToken(opts.PageToken, q)
+		if err != nil {
+			return ListQuery{}, 0, err
+		}
+		q.After = c
+	}
+	return q, size, nil
+}
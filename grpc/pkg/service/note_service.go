@@ -3,71 +3,589 @@ package service
 import (
 	"context"
 	"errors"
+	"io"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/verazalayli/go_studying/grpc/pkg/auth"
+	"github.com/verazalayli/go_studying/grpc/pkg/markdown"
+	"github.com/verazalayli/go_studying/grpc/pkg/search"
 )
 
 // Доменная модель
 type Note struct {
-	ID        string
+	ID string
+	// OwnerID — кто создал заметку (auth.Principal.Subject). Пустой — заметка
+	// создана без аутентификации и доступна всем неаутентифицированным вызовам.
+	OwnerID   string
 	Title     string
 	Content   string
 	CreatedAt time.Time
+	UpdatedAt time.Time
+	// Version растёт на 1 при каждом изменении (новая заметка — 1).
+	// По нему запись отвергается, если заметку успели изменить (оптимистичная блокировка).
+	Version int64
+	// DeletedAt — когда заметку убрали в корзину; нулевое время — заметка не удалена.
+	DeletedAt time.Time
+	// Tags — метки заметки: в нижнем регистре, без повторов, по алфавиту (см. NormalizeTags).
+	Tags []string
+	// Attachments — прикреплённые файлы в порядке загрузки; содержимое — в BlobStore.
+	Attachments []Attachment
+}
+
+// Deleted — лежит ли заметка в корзине.
+func (n Note) Deleted() bool {
+	return !n.DeletedAt.IsZero()
+}
+
+// NoteUpdate — частичное изменение заметки.
+// nil означает "поле не трогаем".
+type NoteUpdate struct {
+	Title   *string
+	Content *string
+	// ExpectedVersion — версия, которую видел клиент; не совпала — KindAborted.
+	// 0 — без проверки (изменение применяется к текущей версии).
+	ExpectedVersion int64
+}
+
+// NoteInput — данные новой заметки, которые присылает клиент.
+type NoteInput struct {
+	Title   string
+	Content string
+	Tags    []string
+}
+
+// BulkFailure — элемент пакета, который не прошёл валидацию.
+// Index — позиция элемента во входном пакете.
+type BulkFailure struct {
+	Index  int
+	Field  string
+	Reason string
+}
+
+// BulkResult — итог пакетного создания.
+type BulkResult struct {
+	Created  []Note
+	Failures []BulkFailure
 }
 
-// Порт хранилища
+// Порт хранилища.
+// Все методы принимают контекст клиента: адаптер обязан прекратить работу
+// и вернуть ctx.Err() (можно обёрнутой), если запрос отменён или истёк дедлайн.
+//
+// Версии проверяет адаптер, атомарно с самой записью (под одной блокировкой,
+// в одной транзакции SQL, под WATCH в Redis):
+//   - Save пишет n, только если сохранённая версия равна n.Version-1, а новую заметку
+//     (n.Version == 1) — только если записи с таким ID ещё нет;
+//   - Delete удаляет, только если сохранённая версия равна expectedVersion (0 — любая).
+//
+// Иначе — ErrVersionConflict (можно обёрнутой), и ничего не меняется.
+//
+// Каждую записанную версию Save и SaveMany в той же операции кладут в историю
+// (ListRevisions/GetRevision), а Delete удаляет историю вместе с заметкой.
 type NoteRepository interface {
-	Save(n Note) error
-	GetByID(id string) (Note, error)
-	List() ([]Note, error)
+	Save(ctx context.Context, n Note) error
+	// SaveMany сохраняет пачку заметок за один вызов: все или ни одной.
+	SaveMany(ctx context.Context, notes []Note) error
+	GetByID(ctx context.Context, id string) (Note, error)
+	List(ctx context.Context, q ListQuery) ([]Note, error)
+	Delete(ctx context.Context, id string, expectedVersion int64) error
+	// Replace за одну операцию удаляет заметку id вместе с историей (версии expectedVersion,
+	// как в Delete) и записывает вместо неё states — её новую историю с версии 1 (CanSaveHistory).
+	// Не удалось — не меняется ничего.
+	Replace(ctx context.Context, id string, expectedVersion int64, states []Note) error
+	// ListTags — теги заметок владельца (без корзины) с числом заметок, по алфавиту.
+	// Как и выборка по тегам в List, должен идти по индексу тегов, а не по всем заметкам.
+	ListTags(ctx context.Context, owner string) ([]TagCount, error)
+	// ListRevisions — версии заметки id с номером меньше before (0 — все), от новых
+	// к старым, не больше limit (<= 0 — без ограничения). Нет истории — пустой список.
+	ListRevisions(ctx context.Context, id string, before int64, limit int) ([]Note, error)
+	// GetRevision — версия version заметки id; её нет — ErrRecordNotFound.
+	GetRevision(ctx context.Context, id string, version int64) (Note, error)
+}
+
+// Pinger — необязательная часть порта хранилища: проверка, что оно доступно
+// (соединение с БД живо, журнал открыт). По ней сервер выставляет статус health-check.
+// Хранилище без Pinger считается доступным всегда.
+type Pinger interface {
+	Ping(ctx context.Context) error
 }
 
-// Ошибки прикладного слоя
+// Ошибки прикладного слоя. Сервис возвращает *Error (см. errors.go),
+// который совпадает с ними через errors.Is по категории.
 var (
 	ErrNotFound   = errors.New("note not found")
 	ErrBadRequest = errors.New("bad request")
 )
 
+// ErrRecordNotFound — адаптеры NoteRepository возвращают её (возможно, обёрнутой),
+// когда записи с таким ID нет. Любая другая ошибка хранилища — сбой, а не "не найдено".
+var ErrRecordNotFound = errors.New("record not found")
+
+// ErrVersionConflict — адаптеры NoteRepository возвращают её, когда версия записи
+// не та, что ожидалась (заметку изменили или удалили параллельно).
+var ErrVersionConflict = errors.New("version conflict")
+
 // Входной порт прикладного слоя (то, что вызывает handler)
 type NoteService interface {
-	Create(ctx context.Context, title, content string) (Note, error)
+	// Create создаёт заметку. idempotencyKey != "" — повтор с тем же ключом (в пределах окна,
+	// см. WithIdempotencyWindow) вернёт уже созданную заметку; тот же ключ с другими
+	// данными — KindFailedPrecondition. Ключи у каждого владельца свои.
+	Create(ctx context.Context, in NoteInput, idempotencyKey string) (Note, error)
+	// BulkCreate создаёт валидные заметки пакета одним SaveMany,
+	// невалидные возвращает в BulkResult.Failures.
+	BulkCreate(ctx context.Context, items []NoteInput) (BulkResult, error)
 	Get(ctx context.Context, id string) (Note, error)
-	List(ctx context.Context) ([]Note, error)
+	List(ctx context.Context, opts ListOptions) (NotePage, error)
+	// Update и Delete с ожидаемой версией (upd.ExpectedVersion, expectedVersion != 0)
+	// возвращают KindAborted с текущей версией, если заметку уже изменили.
+	Update(ctx context.Context, id string, upd NoteUpdate) (Note, error)
+	// Delete убирает заметку в корзину: Get, List, Update и Search её больше не видят.
+	Delete(ctx context.Context, id string, expectedVersion int64) error
+	// ListTrash — страница корзины (параметры как у List, TitlePrefix тоже работает).
+	ListTrash(ctx context.Context, opts ListOptions) (NotePage, error)
+	// Restore возвращает заметку из корзины, Purge удаляет её из корзины окончательно.
+	// Заметка не в корзине — KindFailedPrecondition.
+	Restore(ctx context.Context, id string, expectedVersion int64) (Note, error)
+	Purge(ctx context.Context, id string, expectedVersion int64) error
+	// AddTags и RemoveTags меняют теги заметки (это обычное изменение с новой версией).
+	AddTags(ctx context.Context, id string, tags []string, expectedVersion int64) (Note, error)
+	RemoveTags(ctx context.Context, id string, tags []string, expectedVersion int64) (Note, error)
+	// ListTags — все теги вызывающего с числом заметок.
+	ListTags(ctx context.Context) ([]TagCount, error)
+	// ListRevisions и GetRevision читают историю версий заметки (см. revisions.go),
+	// DiffRevisions — построчный unified diff текста между двумя версиями,
+	// Revert записывает поверх текущей версии содержимое старой (как новую версию).
+	ListRevisions(ctx context.Context, id string, opts RevisionListOptions) (RevisionPage, error)
+	GetRevision(ctx context.Context, id string, version int64) (Note, error)
+	DiffRevisions(ctx context.Context, id string, from, to int64) (RevisionDiff, error)
+	Revert(ctx context.Context, id string, version, expectedVersion int64) (Note, error)
+	// UploadAttachment читает содержимое файла из r и прикрепляет его к заметке
+	// (новая версия заметки); OpenAttachment отдаёт вложение и его содержимое (см. attachments.go).
+	UploadAttachment(ctx context.Context, noteID string, in AttachmentInput, r io.Reader) (Note, Attachment, error)
+	OpenAttachment(ctx context.Context, noteID, attachmentID string) (Attachment, io.ReadCloser, error)
+	//
+	// Все методы выше, Watch и Search видят только заметки вызывающего
+	// (владелец — auth.Principal из ctx); чужая заметка — KindPermissionDenied.
+	//
+	// Watch подписывает на изменения заметок (см. EventBus).
+	// Подписка закрывается сама, когда ctx отменён.
+	Watch(ctx context.Context, fromSeq uint64) (*Subscription, error)
+	// Search — полнотекстовый поиск по заголовку и тексту.
+	// limit == 0 — значение по умолчанию (DefaultSearchLimit).
+	Search(ctx context.Context, query string, limit int) ([]SearchResult, error)
+	// Render отрисовывает текст заметки (Markdown) в очищенный HTML (см. render.go).
+	Render(ctx context.Context, id string, version int64) (RenderedNote, error)
+	// Export передаёт fn заметки вызывающего с историей, по одной; ошибка fn прерывает выгрузку.
+	// Import читает заметки из next до io.EOF и записывает их с историей (см. archive.go).
+	Export(ctx context.Context, opts ExportOptions, fn func(ArchivedNote) error) error
+	Import(ctx context.Context, mode ImportMode, next func() (ArchivedNote, error)) (ImportResult, error)
+
+	// PurgeTrash окончательно удаляет заметки всех владельцев, попавшие в корзину
+	// раньше before, и возвращает, сколько удалено. Служебный метод для фоновой
+	// очистки (pkg/janitor), обработчикам RPC он не нужен.
+	PurgeTrash(ctx context.Context, before time.Time) (int, error)
 }
 
 type noteService struct {
-	repo NoteRepository
+	repo   NoteRepository
+	events *EventBus
+	idem   *idempotencyStore
+
+	blobs             BlobStore
+	maxAttachmentSize int64
+
+	markdown *markdown.Renderer
+
+	// Поисковый индекс у каждого владельца свой: поиск не видит чужих заметок,
+	// а ранжирование и лимит считаются только по своим.
+	indexes   map[string]*search.Index
+	indexesMu sync.Mutex
+
+	indexMu    sync.Mutex // сериализует первичное наполнение индексов
+	indexReady bool
+
+	// Побочные эффекты записей (индекс и события) применяются по одному, и для каждой
+	// заметки помнится последняя применённая версия: запрос, который записал версию N,
+	// но дошёл до afterWrite позже записавшего N+1, не откатит индекс и не опубликует
+	// события не по порядку.
+	appliedMu sync.Mutex
+	applied   map[string]appliedVersion
+}
+
+// appliedVersion — последняя версия заметки, прошедшая через afterWrite.
+// purged — заметку удалили окончательно: её старые версии больше не применяются.
+type appliedVersion struct {
+	version int64
+	purged  bool
+}
+
+// Option — функциональная опция сервиса.
+type Option func(*noteService)
+
+// WithEventBus подставляет свою шину событий (например, с другим размером истории).
+func WithEventBus(b *EventBus) Option {
+	return func(s *noteService) { s.events = b }
 }
 
-func NewNoteService(repo NoteRepository) NoteService {
-	return &noteService{repo: repo}
+// WithIdempotencyWindow — сколько помнить ключи идемпотентности CreateNote
+// (по умолчанию DefaultIdempotencyWindow); 0 — ключи игнорируются.
+func WithIdempotencyWindow(d time.Duration) Option {
+	return func(s *noteService) { s.idem = newIdempotencyStore(d) }
 }
 
-func (s *noteService) Create(ctx context.Context, title, content string) (Note, error) {
-	if title == "" {
-		return Note{}, ErrBadRequest
+func NewNoteService(repo NoteRepository, opts ...Option) NoteService {
+	s := &noteService{
+		repo:     repo,
+		idem:     newIdempotencyStore(DefaultIdempotencyWindow),
+		indexes:  make(map[string]*search.Index),
+		applied:  make(map[string]appliedVersion),
+		markdown: markdown.NewRenderer(),
+
+		maxAttachmentSize: DefaultMaxAttachmentSize,
 	}
-	n := Note{
-		ID:        uuid.NewString(),
-		Title:     title,
-		Content:   content,
-		CreatedAt: time.Now(),
+	for _, o := range opts {
+		o(s)
 	}
-	if err := s.repo.Save(n); err != nil {
+	if s.events == nil {
+		s.events = NewEventBus()
+	}
+	return s
+}
+
+func (s *noteService) Create(ctx context.Context, in NoteInput, idempotencyKey string) (Note, error) {
+	if err := normalizeInput(&in); err != nil {
 		return Note{}, err
 	}
+	if idempotencyKey == "" || s.idem.window <= 0 {
+		return s.create(ctx, in)
+	}
+	if len(idempotencyKey) > maxIdempotencyKeyLen {
+		return Note{}, InvalidArgument("idempotency_key", "IDEMPOTENCY_KEY_TOO_LONG",
+			"idempotency_key must be at most %d bytes", maxIdempotencyKeyLen)
+	}
+	return s.idem.do(ctx, ownerFrom(ctx), idempotencyKey, in, s.create)
+}
+
+// normalizeInput проверяет данные новой заметки и приводит теги к каноническому виду.
+func normalizeInput(in *NoteInput) *Error {
+	if in.Title == "" {
+		return errTitleRequired()
+	}
+	tags, err := NormalizeTags("tags", in.Tags)
+	if err != nil {
+		return err.(*Error)
+	}
+	if len(tags) > MaxTagsPerNote {
+		return errTooManyTags("tags")
+	}
+	in.Tags = tags
+	return nil
+}
+
+func (s *noteService) create(ctx context.Context, in NoteInput) (Note, error) {
+	n := newNote(ownerFrom(ctx), in)
+	if err := s.repo.Save(ctx, n); err != nil {
+		return Note{}, err
+	}
+	s.afterWrite(EventCreated, n)
 	return n, nil
 }
 
-func (s *noteService) Get(ctx context.Context, id string) (Note, error) {
-	n, err := s.repo.GetByID(id)
+func (s *noteService) BulkCreate(ctx context.Context, items []NoteInput) (BulkResult, error) {
+	var res BulkResult
+	owner := ownerFrom(ctx)
+	notes := make([]Note, 0, len(items))
+	for i, in := range items {
+		if e := normalizeInput(&in); e != nil {
+			res.Failures = append(res.Failures, BulkFailure{Index: i, Field: e.Field, Reason: e.Message})
+			continue
+		}
+		notes = append(notes, newNote(owner, in))
+	}
+	if len(notes) > 0 {
+		if err := s.repo.SaveMany(ctx, notes); err != nil {
+			return BulkResult{}, err
+		}
+	}
+	for _, n := range notes {
+		s.afterWrite(EventCreated, n)
+	}
+	res.Created = notes
+	return res, nil
+}
+
+// afterWrite — всё, что делаем после успешной записи в хранилище:
+// обновляем поисковый индекс и публикуем событие подписчикам.
+// Версия старее уже применённой пропускается: её перекрыла более новая запись.
+func (s *noteService) afterWrite(typ EventType, n Note) {
+	s.appliedMu.Lock()
+	defer s.appliedMu.Unlock()
+	s.applyLocked(typ, n)
+}
+
+// afterReplace — afterWrite для заметки, которую импорт заменил вместе с историей:
+// удаление старой и появление новой публикуются подряд, а версии новой считаются
+// заново (они могут быть меньше версий старой).
+func (s *noteService) afterReplace(old Note, typ EventType, n Note) {
+	s.appliedMu.Lock()
+	defer s.appliedMu.Unlock()
+	s.applyLocked(EventPurged, old)
+	delete(s.applied, n.ID)
+	s.applyLocked(typ, n)
+}
+
+func (s *noteService) applyLocked(typ EventType, n Note) {
+	if !s.newerLocked(n) {
+		return
+	}
+	s.applied[n.ID] = appliedVersion{version: n.Version, purged: typ == EventPurged}
+	s.indexNote(typ, n)
+	s.events.Publish(typ, n)
+}
+
+// newerLocked — не перекрыта ли версия n уже применённой.
+func (s *noteService) newerLocked(n Note) bool {
+	last, ok := s.applied[n.ID]
+	return !ok || (!last.purged && n.Version >= last.version)
+}
+
+// repoErr переводит "записи нет" из хранилища в NoteNotFound,
+// остальные ошибки хранилища (сбой диска, сети, отмену запроса) пробрасывает как есть.
+func repoErr(id string, err error) error {
+	if errors.Is(err, ErrRecordNotFound) {
+		return NoteNotFound(id, err)
+	}
+	return err
+}
+
+// ownerFrom — владелец заметок вызывающего: sub из токена или "" без аутентификации.
+func ownerFrom(ctx context.Context) string {
+	p, _ := auth.FromContext(ctx)
+	return p.Subject
+}
+
+// getOwned читает заметку вне корзины и проверяет, что она принадлежит вызывающему.
+// Заметка в корзине для него всё равно что удалена — NoteNotFound.
+func (s *noteService) getOwned(ctx context.Context, id string) (Note, error) {
+	n, err := s.getOwnedAny(ctx, id)
 	if err != nil {
-		return Note{}, ErrNotFound
+		return Note{}, err
+	}
+	if n.Deleted() {
+		return Note{}, NoteNotFound(id, nil)
+	}
+	return n, nil
+}
+
+// getTrashed — то же для заметки в корзине (Restore, Purge).
+func (s *noteService) getTrashed(ctx context.Context, id string) (Note, error) {
+	n, err := s.getOwnedAny(ctx, id)
+	if err != nil {
+		return Note{}, err
+	}
+	if !n.Deleted() {
+		return Note{}, NoteNotInTrash(id)
+	}
+	return n, nil
+}
+
+func (s *noteService) getOwnedAny(ctx context.Context, id string) (Note, error) {
+	n, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return Note{}, repoErr(id, err)
+	}
+	if n.OwnerID != ownerFrom(ctx) {
+		return Note{}, NoteAccessDenied(id)
 	}
 	return n, nil
 }
 
-func (s *noteService) List(ctx context.Context) ([]Note, error) {
-	return s.repo.List()
+// newNote заполняет поля, которые клиент не присылает: ID, владельца и время.
+func newNote(owner string, in NoteInput) Note {
+	now := time.Now()
+	return Note{
+		ID:        uuid.NewString(),
+		OwnerID:   owner,
+		Title:     in.Title,
+		Content:   in.Content,
+		Tags:      in.Tags,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Version:   1,
+	}
+}
+
+// CanSave — правило версий для Save, общее для адаптеров, проверяющих его сами:
+// новую заметку (версия 1) можно записать, только если её нет, остальные — только
+// поверх предыдущей версии. cur, exists — то, что лежит в хранилище сейчас.
+func CanSave(cur Note, exists bool, n Note) bool {
+	if !exists {
+		return n.Version == 1
+	}
+	return cur.Version == n.Version-1
+}
+
+// CanSaveHistory — правило версий для Replace: states — версии одной заметки id подряд с 1.
+func CanSaveHistory(id string, states []Note) bool {
+	if len(states) == 0 {
+		return false
+	}
+	for i, n := range states {
+		if n.ID != id || n.Version != int64(i+1) {
+			return false
+		}
+	}
+	return true
+}
+
+// CanDelete — правило версий для Delete: expectedVersion == 0 — удаляем любую.
+func CanDelete(cur Note, expectedVersion int64) bool {
+	return expectedVersion == 0 || cur.Version == expectedVersion
+}
+
+// maxConflictRetries — сколько раз Update/Delete/Restore без ожидаемой версии перечитывают
+// заметку и пробуют снова, если её изменили между чтением и записью.
+const maxConflictRetries = 5
+
+func (s *noteService) Get(ctx context.Context, id string) (Note, error) {
+	return s.getOwned(ctx, id)
+}
+
+func (s *noteService) List(ctx context.Context, opts ListOptions) (NotePage, error) {
+	return s.list(ctx, opts, false)
+}
+
+func (s *noteService) ListTrash(ctx context.Context, opts ListOptions) (NotePage, error) {
+	return s.list(ctx, opts, true)
+}
+
+func (s *noteService) list(ctx context.Context, opts ListOptions, trash bool) (NotePage, error) {
+	q, size, err := listQuery(opts, trash)
+	if err != nil {
+		return NotePage{}, err
+	}
+	q.OwnerID = ownerFrom(ctx)
+	notes, err := s.repo.List(ctx, q)
+	if err != nil {
+		return NotePage{}, err
+	}
+	page := NotePage{Notes: notes}
+	if len(notes) > size {
+		page.Notes = notes[:size]
+		page.NextPageToken = encodePageToken(q, CursorOf(page.Notes[size-1]))
+	}
+	return page, nil
+}
+
+func (s *noteService) Update(ctx context.Context, id string, upd NoteUpdate) (Note, error) {
+	if upd.Title != nil && *upd.Title == "" {
+		return Note{}, errTitleRequired()
+	}
+	return s.modify(ctx, id, upd.ExpectedVersion, s.getOwned, EventUpdated, func(n *Note) error {
+		if upd.Title != nil {
+			n.Title = *upd.Title
+		}
+		if upd.Content != nil {
+			n.Content = *upd.Content
+		}
+		return nil
+	})
+}
+
+// Delete убирает заметку в корзину — это такое же изменение с новой версией.
+func (s *noteService) Delete(ctx context.Context, id string, expectedVersion int64) error {
+	_, err := s.modify(ctx, id, expectedVersion, s.getOwned, EventDeleted, func(n *Note) error {
+		n.DeletedAt = time.Now()
+		return nil
+	})
+	return err
+}
+
+func (s *noteService) Restore(ctx context.Context, id string, expectedVersion int64) (Note, error) {
+	return s.modify(ctx, id, expectedVersion, s.getTrashed, EventRestored, func(n *Note) error {
+		n.DeletedAt = time.Time{}
+		return nil
+	})
+}
+
+// modify — чтение (get), изменение (change) и запись с проверкой версии.
+// Ошибка change (например, валидации) возвращается как есть, без записи.
+// Если между чтением и записью заметку изменили: с expectedVersion — KindAborted
+// (клиент решает сам, что делать с чужими правками), без неё — перечитываем и применяем
+// изменение заново.
+func (s *noteService) modify(ctx context.Context, id string, expectedVersion int64,
+	get func(context.Context, string) (Note, error), typ EventType, change func(*Note) error) (Note, error) {
+	for attempt := 0; ; attempt++ {
+		n, err := get(ctx, id)
+		if err != nil {
+			return Note{}, err
+		}
+		if expectedVersion != 0 && n.Version != expectedVersion {
+			return Note{}, VersionMismatch(id, expectedVersion, n.Version)
+		}
+		if err := change(&n); err != nil {
+			return Note{}, err
+		}
+		// UpdatedAt — время именно этой версии (в том числе ухода в корзину и возврата),
+		// по нему история показывает, когда версия появилась.
+		n.UpdatedAt = time.Now()
+		n.Version++
+		err = s.repo.Save(ctx, n)
+		if errors.Is(err, ErrVersionConflict) && attempt < maxConflictRetries {
+			continue // следующая итерация увидит новую версию
+		}
+		if err != nil {
+			return Note{}, s.conflictErr(ctx, id, n.Version-1, err)
+		}
+		s.afterWrite(typ, n)
+		return n, nil
+	}
+}
+
+// Purge удаляет из хранилища ровно ту версию, которую проверил (владельца в том числе).
+func (s *noteService) Purge(ctx context.Context, id string, expectedVersion int64) error {
+	for attempt := 0; ; attempt++ {
+		n, err := s.getTrashed(ctx, id)
+		if err != nil {
+			return err
+		}
+		if expectedVersion != 0 && n.Version != expectedVersion {
+			return VersionMismatch(id, expectedVersion, n.Version)
+		}
+		err = s.repo.Delete(ctx, id, n.Version)
+		if errors.Is(err, ErrVersionConflict) && attempt < maxConflictRetries {
+			continue
+		}
+		if err != nil {
+			return s.conflictErr(ctx, id, n.Version, repoErr(id, err))
+		}
+		s.afterWrite(EventPurged, n)
+		return nil
+	}
+}
+
+// conflictErr превращает ErrVersionConflict, на котором кончились повторы,
+// в KindAborted с текущей версией заметки; остальные ошибки — как есть.
+func (s *noteService) conflictErr(ctx context.Context, id string, expected int64, err error) error {
+	if !errors.Is(err, ErrVersionConflict) {
+		return err
+	}
+	cur, gerr := s.repo.GetByID(ctx, id)
+	if gerr != nil {
+		return repoErr(id, gerr)
+	}
+	return VersionMismatch(id, expected, cur.Version)
+}
+
+func (s *noteService) Watch(ctx context.Context, fromSeq uint64) (*Subscription, error) {
+	owner := ownerFrom(ctx)
+	sub, err := s.events.Subscribe(fromSeq, func(ev NoteEvent) bool { return ev.Note.OwnerID == owner })
+	if err != nil {
+		return nil, err
+	}
+	sub.closeOnDone(ctx)
+	return sub, nil
 }
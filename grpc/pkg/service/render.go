@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+
+	"github.com/verazalayli/go_studying/grpc/pkg/markdown"
+)
+
+// RenderedNote — заметка в отрисованной версии и её текст в HTML (см. пакет markdown).
+type RenderedNote struct {
+	Note Note
+	markdown.Document
+}
+
+// Render отрисовывает текст заметки (Markdown) в очищенный HTML с оглавлением,
+// ссылками и числом слов. version == 0 — текущая версия, иначе — версия из истории.
+// Результат не кешируется: отрисовка линейна по длине текста — обычная заметка занимает
+// около миллисекунды, — а кешу понадобились бы предел памяти и вытеснение. Размер текста
+// сервис не ограничивает (только лимит сообщения gRPC, 4 MiB по умолчанию), и такая
+// заметка отрисовывается порядка секунд; если крупные заметки станут обычными, кешировать
+// стоит по (id, version) — версия заметки не меняется.
+func (s *noteService) Render(ctx context.Context, id string, version int64) (RenderedNote, error) {
+	if version < 0 {
+		return RenderedNote{}, InvalidArgument("version", "INVALID_VERSION", "version must be positive")
+	}
+	var (
+		n   Note
+		err error
+	)
+	if version == 0 {
+		n, err = s.getOwned(ctx, id)
+	} else {
+		n, err = s.GetRevision(ctx, id, version)
+	}
+	if err != nil {
+		return RenderedNote{}, err
+	}
+	doc, err := s.markdown.Render(n.Content)
+	if err != nil {
+		return RenderedNote{}, err
+	}
+	return RenderedNote{Note: n, Document: doc}, nil
+}
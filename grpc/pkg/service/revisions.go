@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/verazalayli/go_studying/grpc/pkg/diff"
+)
+
+/*
+	История версий.
+
+	Каждая записанная версия заметки (создание, правка, теги, уход в корзину и возврат)
+	сохраняется хранилищем атомарно с самой записью (см. NoteRepository), так что
+	история не расходится с заметкой. Ревизия — это просто Note в своей версии:
+	UpdatedAt — время её появления.
+
+	Заметки, записанные до появления истории, в ней отсутствуют; их текущая версия
+	всё равно видна в ListRevisions/GetRevision — она берётся из самой заметки.
+	Окончательное удаление (Purge) стирает историю вместе с заметкой.
+*/
+
+// RevisionListOptions — параметры ListNoteRevisions.
+type RevisionListOptions struct {
+	PageSize  int
+	PageToken string
+}
+
+// RevisionPage — страница истории, от новых версий к старым.
+type RevisionPage struct {
+	Revisions     []Note
+	NextPageToken string
+}
+
+// RevisionDiff — результат DiffRevisions: From и To — фактически сравниваемые версии.
+type RevisionDiff struct {
+	From, To int64
+	Unified  string
+}
+
+// revisionToken — содержимое page_token истории: заметка и версия, до которой читать.
+type revisionToken struct {
+	ID     string `json:"id"`
+	Before int64  `json:"b"`
+}
+
+func (s *noteService) ListRevisions(ctx context.Context, id string, opts RevisionListOptions) (RevisionPage, error) {
+	size, err := pageSize(opts.PageSize)
+	if err != nil {
+		return RevisionPage{}, err
+	}
+	n, err := s.getOwned(ctx, id)
+	if err != nil {
+		return RevisionPage{}, err
+	}
+	before := n.Version + 1
+	if opts.PageToken != "" {
+		if before, err = decodeRevisionToken(opts.PageToken, id); err != nil {
+			return RevisionPage{}, err
+		}
+	}
+	revs, err := s.repo.ListRevisions(ctx, id, before, size+1)
+	if err != nil {
+		return RevisionPage{}, err
+	}
+	// Текущей версии нет в истории только у заметок, записанных до неё.
+	if before > n.Version && (len(revs) == 0 || revs[0].Version != n.Version) {
+		revs = append([]Note{n}, revs...)
+	}
+	page := RevisionPage{Revisions: revs}
+	if len(revs) > size {
+		page.Revisions = revs[:size]
+		page.NextPageToken = encodeRevisionToken(id, revs[size-1].Version)
+	}
+	return page, nil
+}
+
+func (s *noteService) GetRevision(ctx context.Context, id string, version int64) (Note, error) {
+	n, err := s.getOwned(ctx, id)
+	if err != nil {
+		return Note{}, err
+	}
+	switch {
+	case version == n.Version:
+		return n, nil
+	case version < 1 || version > n.Version:
+		return Note{}, RevisionNotFound(id, version)
+	}
+	rev, err := s.repo.GetRevision(ctx, id, version)
+	if errors.Is(err, ErrRecordNotFound) {
+		return Note{}, RevisionNotFound(id, version)
+	}
+	return rev, err
+}
+
+// DiffRevisions сравнивает текст двух версий. to == 0 — текущая версия,
+// from == 0 — версия перед to.
+func (s *noteService) DiffRevisions(ctx context.Context, id string, from, to int64) (RevisionDiff, error) {
+	if from < 0 || to < 0 {
+		return RevisionDiff{}, InvalidArgument("version", "INVALID_VERSION", "versions must be positive")
+	}
+	n, err := s.getOwned(ctx, id)
+	if err != nil {
+		return RevisionDiff{}, err
+	}
+	if to == 0 {
+		to = n.Version
+	}
+	if from == 0 {
+		from = max(to-1, 1)
+	}
+	a, err := s.GetRevision(ctx, id, from)
+	if err != nil {
+		return RevisionDiff{}, err
+	}
+	b, err := s.GetRevision(ctx, id, to)
+	if err != nil {
+		return RevisionDiff{}, err
+	}
+	return RevisionDiff{
+		From:    from,
+		To:      to,
+		Unified: diff.Unified(fmt.Sprintf("%s@%d", id, from), fmt.Sprintf("%s@%d", id, to), a.Content, b.Content, diff.DefaultContext),
+	}, nil
+}
+
+// Revert делает заголовок, текст и теги заметки такими, как в версии version.
+// Это обычное изменение: история не переписывается, появляется новая версия.
+func (s *noteService) Revert(ctx context.Context, id string, version, expectedVersion int64) (Note, error) {
+	rev, err := s.GetRevision(ctx, id, version)
+	if err != nil {
+		return Note{}, err
+	}
+	return s.modify(ctx, id, expectedVersion, s.getOwned, EventUpdated, func(n *Note) error {
+		n.Title, n.Content, n.Tags = rev.Title, rev.Content, rev.Tags
+		return nil
+	})
+}
+
+func encodeRevisionToken(id string, before int64) string {
+	b, _ := json.Marshal(revisionToken{ID: id, Before: before})
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeRevisionToken(s, id string) (int64, error) {
+	var t revisionToken
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err == nil {
+		err = json.Unmarshal(b, &t)
+	}
+	if err != nil || t.Before < 1 {
+		return 0, InvalidArgument("page_token", "INVALID_PAGE_TOKEN", "malformed page_token")
+	}
+	if t.ID != id {
+		return 0, InvalidArgument("page_token", "PAGE_TOKEN_MISMATCH", "page_token belongs to another note")
+	}
+	return t.Before, nil
+}
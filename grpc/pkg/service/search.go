@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/verazalayli/go_studying/grpc/pkg/search"
+)
+
+// Лимиты выдачи поиска.
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+	maxSnippets        = 3
+)
+
+// SearchResult — найденная заметка, её вес и фрагменты текста с подсвеченными совпадениями.
+type SearchResult struct {
+	Note     Note
+	Score    float64
+	Snippets []string
+}
+
+// Search ищет заметки по словам в заголовке и тексте (см. пакет search).
+func (s *noteService) Search(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+	q := search.ParseQuery(query)
+	if len(q.Terms) == 0 {
+		return nil, InvalidArgument("query", "QUERY_EMPTY", "query is empty")
+	}
+	switch {
+	case limit < 0:
+		return nil, InvalidArgument("limit", "INVALID_LIMIT", "limit must be >= 0")
+	case limit == 0:
+		limit = DefaultSearchLimit
+	case limit > MaxSearchLimit:
+		limit = MaxSearchLimit
+	}
+	if err := s.ensureIndex(ctx); err != nil {
+		return nil, err
+	}
+
+	hits := s.ownerIndex(ownerFrom(ctx)).Search(q, limit)
+	out := make([]SearchResult, 0, len(hits))
+	for _, h := range hits {
+		n, err := s.repo.GetByID(ctx, h.ID)
+		if err != nil {
+			// Индекс мог отстать от хранилища (заметку удалили между поиском и чтением).
+			if errors.Is(err, ErrRecordNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		if n.Deleted() {
+			continue // то же, но заметка ушла в корзину
+		}
+		snippets := search.Snippets(n.Content, q, maxSnippets)
+		if len(snippets) == 0 {
+			snippets = search.Snippets(n.Title, q, 1)
+		}
+		out = append(out, SearchResult{Note: n, Score: h.Score, Snippets: snippets})
+	}
+	return out, nil
+}
+
+// ensureIndex один раз наполняет индекс тем, что уже лежит в хранилище
+// (например, после рестарта с файловым или SQL-хранилищем).
+// Записи, прошедшие через сервис до этого, уже в индексе; заметку, которую
+// afterWrite успел применить в версии новее прочитанной (или удалить), выборка не трогает.
+func (s *noteService) ensureIndex(ctx context.Context) error {
+	s.indexMu.Lock()
+	defer s.indexMu.Unlock()
+	if s.indexReady {
+		return nil
+	}
+	notes, err := s.repo.List(ctx, ListQuery{AnyOwner: true})
+	if err != nil {
+		return fmt.Errorf("build search index: %w", err)
+	}
+	s.appliedMu.Lock()
+	defer s.appliedMu.Unlock()
+	for _, n := range notes {
+		if !s.newerLocked(n) {
+			continue
+		}
+		s.applied[n.ID] = appliedVersion{version: n.Version}
+		s.ownerIndex(n.OwnerID).Put(n.ID, n.Title, n.Content)
+	}
+	s.indexReady = true
+	return nil
+}
+
+// indexNote обновляет поисковый индекс после записи.
+func (s *noteService) indexNote(typ EventType, n Note) {
+	ix := s.ownerIndex(n.OwnerID)
+	if typ == EventDeleted || typ == EventPurged {
+		ix.Remove(n.ID)
+		return
+	}
+	ix.Put(n.ID, n.Title, n.Content)
+}
+
+// ownerIndex возвращает поисковый индекс владельца, создавая его при первом обращении.
+func (s *noteService) ownerIndex(owner string) *search.Index {
+	s.indexesMu.Lock()
+	defer s.indexesMu.Unlock()
+	ix, ok := s.indexes[owner]
+	if !ok {
+		ix = search.NewIndex()
+		s.indexes[owner] = ix
+	}
+	return ix
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Ограничения на теги.
+const (
+	MaxTagsPerNote = 20
+	MaxTagLen      = 64
+	// MaxTagsPerQuery — сколько тегов можно перечислить в фильтре ListNotes.
+	MaxTagsPerQuery = 16
+)
+
+// TagMatch — как фильтр ListNotes сочетает несколько тегов.
+type TagMatch int
+
+const (
+	TagMatchAny TagMatch = iota // у заметки есть хотя бы один из тегов
+	TagMatchAll                 // у заметки есть все теги
+)
+
+// TagCount — тег и число заметок владельца с ним (без корзины).
+type TagCount struct {
+	Tag   string
+	Count int
+}
+
+// NormalizeTags приводит теги к каноническому виду: обрезает пробелы по краям,
+// переводит в нижний регистр, убирает повторы и сортирует. Пустой тег, тег длиннее
+// MaxTagLen или с пробелами/запятыми внутри — ошибка поля field.
+func NormalizeTags(field string, tags []string) ([]string, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+	out := make([]string, 0, len(tags))
+	for _, t := range tags {
+		t = strings.ToLower(strings.TrimSpace(t))
+		switch {
+		case t == "":
+			return nil, InvalidArgument(field, "INVALID_TAG", "tag must not be empty")
+		case utf8.RuneCountInString(t) > MaxTagLen:
+			return nil, InvalidArgument(field, "INVALID_TAG", "tag %q is longer than %d characters", t, MaxTagLen)
+		case strings.ContainsFunc(t, func(r rune) bool { return r == ',' || unicode.IsSpace(r) || unicode.IsControl(r) }):
+			return nil, InvalidArgument(field, "INVALID_TAG", "tag %q must not contain spaces or commas", t)
+		}
+		out = append(out, t)
+	}
+	slices.Sort(out)
+	return slices.Compact(out), nil
+}
+
+// HasTags — подходит ли набор тегов заметки под фильтр (tags уже нормализованы).
+func HasTags(noteTags, tags []string, match TagMatch) bool {
+	if len(tags) == 0 {
+		return true
+	}
+	for _, t := range tags {
+		_, found := slices.BinarySearch(noteTags, t)
+		if found && match == TagMatchAny {
+			return true
+		}
+		if !found && match == TagMatchAll {
+			return false
+		}
+	}
+	return match == TagMatchAll
+}
+
+func errTooManyTags(field string) *Error {
+	return InvalidArgument(field, "TOO_MANY_TAGS", "a note can have at most %d tags", MaxTagsPerNote)
+}
+
+func (s *noteService) AddTags(ctx context.Context, id string, tags []string, expectedVersion int64) (Note, error) {
+	add, err := NormalizeTags("tags", tags)
+	if err != nil {
+		return Note{}, err
+	}
+	if len(add) == 0 {
+		return Note{}, InvalidArgument("tags", "TAGS_REQUIRED", "at least one tag is required")
+	}
+	return s.modify(ctx, id, expectedVersion, s.getOwned, EventUpdated, func(n *Note) error {
+		merged := append(slices.Clone(n.Tags), add...)
+		slices.Sort(merged)
+		merged = slices.Compact(merged)
+		if len(merged) > MaxTagsPerNote {
+			return errTooManyTags("tags")
+		}
+		n.Tags = merged
+		return nil
+	})
+}
+
+// RemoveTags убирает теги; тегов, которых у заметки нет, просто не трогает.
+func (s *noteService) RemoveTags(ctx context.Context, id string, tags []string, expectedVersion int64) (Note, error) {
+	remove, err := NormalizeTags("tags", tags)
+	if err != nil {
+		return Note{}, err
+	}
+	if len(remove) == 0 {
+		return Note{}, InvalidArgument("tags", "TAGS_REQUIRED", "at least one tag is required")
+	}
+	return s.modify(ctx, id, expectedVersion, s.getOwned, EventUpdated, func(n *Note) error {
+		n.Tags = slices.DeleteFunc(slices.Clone(n.Tags), func(t string) bool {
+			_, found := slices.BinarySearch(remove, t)
+			return found
+		})
+		if len(n.Tags) == 0 {
+			n.Tags = nil
+		}
+		return nil
+	})
+}
+
+func (s *noteService) ListTags(ctx context.Context) ([]TagCount, error) {
+	return s.repo.ListTags(ctx, ownerFrom(ctx))
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// purgeBatch — сколько заметок корзины PurgeTrash читает за раз.
+const purgeBatch = 500
+
+// PurgeTrash идёт по корзине всех владельцев пачками и удаляет заметки, убранные
+// в корзину раньше before. Удаление — с проверкой версии: заметку, которую успели
+// вернуть или удалить параллельно, пропускаем.
+func (s *noteService) PurgeTrash(ctx context.Context, before time.Time) (int, error) {
+	q := ListQuery{AnyOwner: true, Trash: true, Limit: purgeBatch}
+	purged := 0
+	for {
+		notes, err := s.repo.List(ctx, q)
+		if err != nil {
+			return purged, err
+		}
+		for _, n := range notes {
+			if !n.DeletedAt.Before(before) {
+				continue
+			}
+			err := s.repo.Delete(ctx, n.ID, n.Version)
+			if errors.Is(err, ErrVersionConflict) || errors.Is(err, ErrRecordNotFound) {
+				continue
+			}
+			if err != nil {
+				return purged, err
+			}
+			purged++
+			s.afterWrite(EventPurged, n)
+		}
+		if len(notes) < purgeBatch {
+			return purged, nil
+		}
+		q.After = CursorOf(notes[len(notes)-1])
+	}
+}
@@ -0,0 +1,237 @@
+package tlsutil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+/*
+	Пакет tlsutil — TLS для сервера и клиента заметок.
+
+	Reloader держит серверный сертификат (и, для mTLS, пул CA клиентов) и
+	раз в интервал проверяет файлы на диске. Если файлы поменялись (например,
+	cert-manager или certbot выпустил новый сертификат), они перечитываются,
+	и новые соединения получают уже новый сертификат — без рестарта сервера.
+	Уже открытые соединения живут со старым.
+
+	Если новые файлы не читаются (ключ не подходит к сертификату, файл записан
+	наполовину), остаётся прежний сертификат, а ошибка уходит в OnError;
+	попытка повторится на следующем тике.
+*/
+
+// DefaultReloadInterval — как часто проверять файлы сертификатов.
+const DefaultReloadInterval = 10 * time.Second
+
+type Reloader struct {
+	certFile, keyFile string
+	clientCAFile      string
+	interval          time.Duration
+	onError           func(error)
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	clientCA *x509.CertPool
+	stamps   map[string]fileStamp
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// fileStamp — по нему понимаем, что файл поменялся.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// Option — функциональная опция Reloader.
+type Option func(*Reloader)
+
+// WithClientCA включает mTLS: клиент обязан предъявить сертификат,
+// подписанный одним из CA из этого PEM-файла.
+func WithClientCA(file string) Option {
+	return func(r *Reloader) { r.clientCAFile = file }
+}
+
+// WithReloadInterval задаёт период проверки файлов; 0 — не перечитывать.
+func WithReloadInterval(d time.Duration) Option {
+	return func(r *Reloader) { r.interval = d }
+}
+
+// WithOnError задаёт обработчик ошибок фоновой перезагрузки (по умолчанию — игнорировать).
+func WithOnError(fn func(error)) Option {
+	return func(r *Reloader) { r.onError = fn }
+}
+
+// NewReloader читает сертификат и ключ и, если задан интервал, запускает фоновую проверку.
+// Остановить её — Close.
+func NewReloader(certFile, keyFile string, opts ...Option) (*Reloader, error) {
+	r := &Reloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		interval: DefaultReloadInterval,
+		onError:  func(error) {},
+		done:     make(chan struct{}),
+	}
+	for _, o := range opts {
+		o(r)
+	}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	if r.interval > 0 {
+		r.wg.Add(1)
+		go r.watch()
+	}
+	return r, nil
+}
+
+// Reload перечитывает файлы сейчас. При ошибке прежнее состояние не меняется.
+func (r *Reloader) Reload() error {
+	stamps, err := r.statFiles()
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return fmt.Errorf("load key pair: %w", err)
+	}
+	var pool *x509.CertPool
+	if r.clientCAFile != "" {
+		if pool, err = LoadCertPool(r.clientCAFile); err != nil {
+			return err
+		}
+	}
+
+	r.mu.Lock()
+	r.cert, r.clientCA, r.stamps = &cert, pool, stamps
+	r.mu.Unlock()
+	return nil
+}
+
+// ServerConfig — tls.Config для grpc/credentials.NewTLS. Сертификат и пул CA клиентов
+// берутся на каждое рукопожатие, поэтому перезагрузка подхватывается сразу.
+func (r *Reloader) ServerConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+			cfg := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*r.cert},
+			}
+			if r.clientCA != nil {
+				cfg.ClientCAs = r.clientCA
+				cfg.ClientAuth = tls.RequireAndVerifyClientCert
+			}
+			return cfg, nil
+		},
+	}
+}
+
+// Close останавливает фоновую проверку файлов.
+func (r *Reloader) Close() error {
+	select {
+	case <-r.done:
+	default:
+		close(r.done)
+	}
+	r.wg.Wait()
+	return nil
+}
+
+func (r *Reloader) watch() {
+	defer r.wg.Done()
+	t := time.NewTicker(r.interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-t.C:
+			if !r.changed() {
+				continue
+			}
+			if err := r.Reload(); err != nil {
+				r.onError(fmt.Errorf("tls reload: %w", err))
+			}
+		}
+	}
+}
+
+func (r *Reloader) changed() bool {
+	stamps, err := r.statFiles()
+	if err != nil {
+		// Файл могли на мгновение удалить при замене — проверим на следующем тике.
+		return false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for name, s := range stamps {
+		if r.stamps[name] != s {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Reloader) statFiles() (map[string]fileStamp, error) {
+	stamps := make(map[string]fileStamp, 3)
+	for _, name := range []string{r.certFile, r.keyFile, r.clientCAFile} {
+		if name == "" {
+			continue
+		}
+		fi, err := os.Stat(name)
+		if err != nil {
+			return nil, err
+		}
+		stamps[name] = fileStamp{modTime: fi.ModTime(), size: fi.Size()}
+	}
+	return stamps, nil
+}
+
+// LoadCertPool читает PEM-файл с одним или несколькими сертификатами CA.
+func LoadCertPool(file string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("%s: no PEM certificates found", file)
+	}
+	return pool, nil
+}
+
+// ClientConfig — tls.Config клиента.
+//
+//	caFile     — CA, которым проверяем сервер (пусто — системные корневые);
+//	certFile,
+//	keyFile    — сертификат клиента для mTLS (оба пустые — без него);
+//	serverName — имя для проверки сертификата сервера (пусто — из адреса).
+func ClientConfig(caFile, certFile, keyFile, serverName string) (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12, ServerName: serverName}
+	if caFile != "" {
+		pool, err := LoadCertPool(caFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.RootCAs = pool
+	}
+	if (certFile == "") != (keyFile == "") {
+		return nil, errors.New("client certificate and key must be set together")
+	}
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client key pair: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
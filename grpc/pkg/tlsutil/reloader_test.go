@@ -0,0 +1,256 @@
+package tlsutil
+
+import (
+	"bufio"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// setup выпускает тестовым CA полный набор файлов (см. WriteFiles) во временный каталог.
+func setup(t *testing.T) (*TestCA, string) {
+	t.Helper()
+	ca, err := NewTestCA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := ca.WriteFiles(dir); err != nil {
+		t.Fatal(err)
+	}
+	return ca, dir
+}
+
+func newReloader(t *testing.T, dir string, opts ...Option) *Reloader {
+	t.Helper()
+	r, err := NewReloader(filepath.Join(dir, "server.pem"), filepath.Join(dir, "server-key.pem"), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { r.Close() })
+	return r
+}
+
+// serve поднимает TLS-эхо: строка от клиента возвращается ему же.
+func serve(t *testing.T, cfg *tls.Config) string {
+	t.Helper()
+	lis, err := tls.Listen("tcp", "127.0.0.1:0", cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	t.Cleanup(func() {
+		lis.Close()
+		wg.Wait()
+	})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer conn.Close()
+				conn.SetDeadline(time.Now().Add(5 * time.Second))
+				line, err := bufio.NewReader(conn).ReadString('\n')
+				if err != nil {
+					return // рукопожатие не удалось — клиент увидит ошибку сам
+				}
+				conn.Write([]byte(line))
+			}()
+		}
+	}()
+	return lis.Addr().String()
+}
+
+// roundTrip отправляет строку и ждёт её обратно; возвращает сертификат сервера.
+// В TLS 1.3 сервер проверяет сертификат клиента уже после рукопожатия клиента,
+// поэтому отказ в mTLS виден только на чтении — его тоже ловим здесь.
+func roundTrip(addr string, cfg *tls.Config) (*x509.Certificate, error) {
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: 5 * time.Second}, "tcp", addr, cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	if _, err := conn.Write([]byte("ping\n")); err != nil {
+		return nil, err
+	}
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if line != "ping\n" {
+		return nil, errors.New("echo mismatch: " + line)
+	}
+	return conn.ConnectionState().PeerCertificates[0], nil
+}
+
+func clientConfig(t *testing.T, dir string, withCert bool) *tls.Config {
+	t.Helper()
+	var cert, key string
+	if withCert {
+		cert, key = filepath.Join(dir, "client.pem"), filepath.Join(dir, "client-key.pem")
+	}
+	cfg, err := ClientConfig(filepath.Join(dir, "ca.pem"), cert, key, "localhost")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cfg
+}
+
+func TestTLS(t *testing.T) {
+	_, dir := setup(t)
+	addr := serve(t, newReloader(t, dir, WithReloadInterval(0)).ServerConfig())
+
+	peer, err := roundTrip(addr, clientConfig(t, dir, false))
+	if err != nil {
+		t.Fatalf("round trip: %v", err)
+	}
+	if peer.Subject.CommonName != "localhost" {
+		t.Errorf("server certificate CN = %q, want localhost", peer.Subject.CommonName)
+	}
+
+	// Клиент, который не доверяет CA сервера, не соединяется.
+	other, err := NewTestCA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherCA := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(otherCA, other.CertPEM(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := ClientConfig(otherCA, "", "", "localhost")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := roundTrip(addr, cfg); err == nil {
+		t.Error("client with a foreign CA connected")
+	}
+}
+
+func TestMutualTLS(t *testing.T) {
+	_, dir := setup(t)
+	addr := serve(t, newReloader(t, dir, WithReloadInterval(0), WithClientCA(filepath.Join(dir, "ca.pem"))).ServerConfig())
+
+	if _, err := roundTrip(addr, clientConfig(t, dir, true)); err != nil {
+		t.Fatalf("client with a certificate: %v", err)
+	}
+	if _, err := roundTrip(addr, clientConfig(t, dir, false)); err == nil {
+		t.Error("client without a certificate was accepted")
+	}
+
+	// Сертификат клиента от чужого CA тоже не проходит.
+	other, err := NewTestCA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	foreign := t.TempDir()
+	if err := other.WriteFiles(foreign); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := ClientConfig(filepath.Join(dir, "ca.pem"),
+		filepath.Join(foreign, "client.pem"), filepath.Join(foreign, "client-key.pem"), "localhost")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := roundTrip(addr, cfg); err == nil {
+		t.Error("client certificate from a foreign CA was accepted")
+	}
+}
+
+func TestReload(t *testing.T) {
+	ca, dir := setup(t)
+	// Неудачная перезагрузка повторяется на каждом тике: лишние ошибки отбрасываем,
+	// чтобы watcher не встал на полном канале.
+	errs := make(chan error, 16)
+	r := newReloader(t, dir, WithReloadInterval(10*time.Millisecond), WithOnError(func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	}))
+	addr := serve(t, r.ServerConfig())
+	client := clientConfig(t, dir, false)
+
+	before, err := roundTrip(addr, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// rewrite заменяет файлы сервера и сдвигает mtime: на быстрой ФС новая запись
+	// может попасть в ту же отметку времени, что и старая.
+	step := 0
+	rewrite := func(cert, key []byte) {
+		t.Helper()
+		step++
+		for name, data := range map[string][]byte{"server.pem": cert, "server-key.pem": key} {
+			path := filepath.Join(dir, name)
+			if err := os.WriteFile(path, data, 0o600); err != nil {
+				t.Fatal(err)
+			}
+			mtime := time.Now().Add(time.Duration(step) * time.Minute)
+			if err := os.Chtimes(path, mtime, mtime); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	// waitFor ждёт, пока сервер начнёт отдавать сертификат, для которого done — true.
+	waitFor := func(done func(*x509.Certificate) bool) *x509.Certificate {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			peer, err := roundTrip(addr, client)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if done(peer) {
+				return peer
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("server still presents certificate %s", peer.SerialNumber)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	cert, key, err := ca.IssueServer("localhost", "notes.internal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rewrite(cert, key)
+	after := waitFor(func(c *x509.Certificate) bool { return c.SerialNumber.Cmp(before.SerialNumber) != 0 })
+	if len(after.DNSNames) != 2 || after.DNSNames[1] != "notes.internal" {
+		t.Errorf("reloaded certificate DNS names = %v, want [localhost notes.internal]", after.DNSNames)
+	}
+
+	// Ключ не от того сертификата: остаётся прежний сертификат, ошибка — в OnError.
+	// Ошибки выше не в счёт: watcher мог застать новый сертификат со старым ключом.
+	for len(errs) > 0 {
+		<-errs
+	}
+	_, wrongKey, err := ca.IssueServer("localhost")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rewrite(cert, wrongKey)
+	select {
+	case err := <-errs:
+		t.Logf("expected reload error: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("broken key pair did not reach OnError")
+	}
+	if peer, err := roundTrip(addr, client); err != nil || peer.SerialNumber.Cmp(after.SerialNumber) != 0 {
+		t.Errorf("after a failed reload: err %v; server must keep the previous certificate", err)
+	}
+}
@@ -0,0 +1,154 @@
+package tlsutil
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+/*
+	TestCA — одноразовый удостоверяющий центр для локальной разработки и проверок:
+	выпускает сертификаты сервера и клиентов, которыми можно поднять TLS/mTLS
+	без openssl и настоящего CA. Ключи живут только в памяти процесса,
+	пока их не записать на диск через WriteFiles. Не для продакшена.
+*/
+
+// testCertTTL — срок жизни выпущенных сертификатов.
+const testCertTTL = 24 * time.Hour
+
+type TestCA struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+}
+
+// NewTestCA создаёт самоподписанный CA.
+func NewTestCA() (*TestCA, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          randomSerial(),
+		Subject:               pkix.Name{CommonName: "notes test CA"},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(testCertTTL),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	return &TestCA{cert: cert, key: key, certPEM: encodePEM("CERTIFICATE", der)}, nil
+}
+
+// CertPEM — сертификат CA в PEM (его доверяют клиент и, для mTLS, сервер).
+func (ca *TestCA) CertPEM() []byte { return ca.certPEM }
+
+// IssueServer выпускает серверный сертификат для хостов (DNS-имён или IP).
+func (ca *TestCA) IssueServer(hosts ...string) (certPEM, keyPEM []byte, err error) {
+	if len(hosts) == 0 {
+		return nil, nil, errors.New("at least one host is required")
+	}
+	return ca.issue(hosts[0], x509.ExtKeyUsageServerAuth, hosts)
+}
+
+// IssueClient выпускает клиентский сертификат с CommonName = name.
+func (ca *TestCA) IssueClient(name string) (certPEM, keyPEM []byte, err error) {
+	return ca.issue(name, x509.ExtKeyUsageClientAuth, nil)
+}
+
+func (ca *TestCA) issue(cn string, usage x509.ExtKeyUsage, hosts []string) ([]byte, []byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: randomSerial(),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(testCertTTL),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			tmpl.IPAddresses = append(tmpl.IPAddresses, ip)
+		} else {
+			tmpl.DNSNames = append(tmpl.DNSNames, h)
+		}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return encodePEM("CERTIFICATE", der), encodePEM("EC PRIVATE KEY", keyDER), nil
+}
+
+// WriteFiles записывает в dir полный набор для локального mTLS:
+//
+//	ca.pem                     — сертификат CA;
+//	server.pem, server-key.pem — сертификат сервера для localhost и 127.0.0.1;
+//	client.pem, client-key.pem — сертификат клиента "notes-client".
+func (ca *TestCA) WriteFiles(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	srvCert, srvKey, err := ca.IssueServer("localhost", "127.0.0.1", "::1")
+	if err != nil {
+		return err
+	}
+	cliCert, cliKey, err := ca.IssueClient("notes-client")
+	if err != nil {
+		return err
+	}
+	files := []struct {
+		name string
+		data []byte
+		perm os.FileMode
+	}{
+		{"ca.pem", ca.certPEM, 0o644},
+		{"server.pem", srvCert, 0o644},
+		{"server-key.pem", srvKey, 0o600},
+		{"client.pem", cliCert, 0o644},
+		{"client-key.pem", cliKey, 0o600},
+	}
+	for _, f := range files {
+		if err := os.WriteFile(filepath.Join(dir, f.name), f.data, f.perm); err != nil {
+			return fmt.Errorf("write %s: %w", f.name, err)
+		}
+	}
+	return nil
+}
+
+func encodePEM(typ string, der []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der})
+}
+
+func randomSerial() *big.Int {
+	n, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		panic(err) // crypto/rand не отказывает на поддерживаемых ОС
+	}
+	return n
+}
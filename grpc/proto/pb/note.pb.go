@@ -1,14 +1,24 @@
+// Указываем версию синтаксиса Protobuf.
+// proto3 — современный вариант с дефолтными значениями, упрощённой моделью optional и т.д.
+
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
 // 	protoc-gen-go v1.36.7
 // 	protoc        v5.29.3
 // source: note.proto
 
+// Имя proto-пакета (НЕ Go-пакета).
+// Оно будет использоваться в полном имени RPC и типов, например:
+//   /note.v1.NoteService/CreateNote
+// Обычно пишут как <имя_сервиса>.<версия>.
+
 package pb
 
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	fieldmaskpb "google.golang.org/protobuf/types/known/fieldmaskpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -21,12 +31,186 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// Как фильтр ListNotes сочетает несколько тегов.
+type TagMatch int32
+
+const (
+	TagMatch_TAG_MATCH_UNSPECIFIED TagMatch = 0 // То же, что ANY.
+	TagMatch_TAG_MATCH_ANY         TagMatch = 1 // У заметки есть хотя бы один из тегов.
+	TagMatch_TAG_MATCH_ALL         TagMatch = 2 // У заметки есть все теги.
+)
+
+// Enum value maps for TagMatch.
+var (
+	TagMatch_name = map[int32]string{
+		0: "TAG_MATCH_UNSPECIFIED",
+		1: "TAG_MATCH_ANY",
+		2: "TAG_MATCH_ALL",
+	}
+	TagMatch_value = map[string]int32{
+		"TAG_MATCH_UNSPECIFIED": 0,
+		"TAG_MATCH_ANY":         1,
+		"TAG_MATCH_ALL":         2,
+	}
+)
+
+func (x TagMatch) Enum() *TagMatch {
+	p := new(TagMatch)
+	*p = x
+	return p
+}
+
+func (x TagMatch) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TagMatch) Descriptor() protoreflect.EnumDescriptor {
+	return file_note_proto_enumTypes[0].Descriptor()
+}
+
+func (TagMatch) Type() protoreflect.EnumType {
+	return &file_note_proto_enumTypes[0]
+}
+
+func (x TagMatch) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TagMatch.Descriptor instead.
+func (TagMatch) EnumDescriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{0}
+}
+
+// Что делать с заметками вызывающего, которые уже есть в хранилище.
+type ImportMode int32
+
+const (
+	ImportMode_IMPORT_MODE_UNSPECIFIED ImportMode = 0 // То же, что MERGE.
+	// Существующие заметки не трогаются; заметка архива с тем же id, но другим содержимым — конфликт.
+	ImportMode_IMPORT_MODE_MERGE ImportMode = 1
+	// Хранилище приводится к архиву: заметки с тем же id перезаписываются,
+	// заметки вызывающего, которых нет в архиве, удаляются окончательно.
+	ImportMode_IMPORT_MODE_REPLACE ImportMode = 2
+)
+
+// Enum value maps for ImportMode.
+var (
+	ImportMode_name = map[int32]string{
+		0: "IMPORT_MODE_UNSPECIFIED",
+		1: "IMPORT_MODE_MERGE",
+		2: "IMPORT_MODE_REPLACE",
+	}
+	ImportMode_value = map[string]int32{
+		"IMPORT_MODE_UNSPECIFIED": 0,
+		"IMPORT_MODE_MERGE":       1,
+		"IMPORT_MODE_REPLACE":     2,
+	}
+)
+
+func (x ImportMode) Enum() *ImportMode {
+	p := new(ImportMode)
+	*p = x
+	return p
+}
+
+func (x ImportMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ImportMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_note_proto_enumTypes[1].Descriptor()
+}
+
+func (ImportMode) Type() protoreflect.EnumType {
+	return &file_note_proto_enumTypes[1]
+}
+
+func (x ImportMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ImportMode.Descriptor instead.
+func (ImportMode) EnumDescriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{1}
+}
+
+// Тип события об изменении заметки.
+// В proto3 у enum обязано быть нулевое значение — это "не задано".
+type NoteEventType int32
+
+const (
+	NoteEventType_NOTE_EVENT_TYPE_UNSPECIFIED NoteEventType = 0
+	NoteEventType_NOTE_EVENT_TYPE_CREATED     NoteEventType = 1
+	NoteEventType_NOTE_EVENT_TYPE_UPDATED     NoteEventType = 2
+	NoteEventType_NOTE_EVENT_TYPE_DELETED     NoteEventType = 3 // Заметка ушла в корзину.
+	NoteEventType_NOTE_EVENT_TYPE_RESTORED    NoteEventType = 4 // Заметку вернули из корзины.
+	NoteEventType_NOTE_EVENT_TYPE_PURGED      NoteEventType = 5 // Заметку удалили окончательно (вручную или по сроку хранения).
+)
+
+// Enum value maps for NoteEventType.
+var (
+	NoteEventType_name = map[int32]string{
+		0: "NOTE_EVENT_TYPE_UNSPECIFIED",
+		1: "NOTE_EVENT_TYPE_CREATED",
+		2: "NOTE_EVENT_TYPE_UPDATED",
+		3: "NOTE_EVENT_TYPE_DELETED",
+		4: "NOTE_EVENT_TYPE_RESTORED",
+		5: "NOTE_EVENT_TYPE_PURGED",
+	}
+	NoteEventType_value = map[string]int32{
+		"NOTE_EVENT_TYPE_UNSPECIFIED": 0,
+		"NOTE_EVENT_TYPE_CREATED":     1,
+		"NOTE_EVENT_TYPE_UPDATED":     2,
+		"NOTE_EVENT_TYPE_DELETED":     3,
+		"NOTE_EVENT_TYPE_RESTORED":    4,
+		"NOTE_EVENT_TYPE_PURGED":      5,
+	}
+)
+
+func (x NoteEventType) Enum() *NoteEventType {
+	p := new(NoteEventType)
+	*p = x
+	return p
+}
+
+func (x NoteEventType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (NoteEventType) Descriptor() protoreflect.EnumDescriptor {
+	return file_note_proto_enumTypes[2].Descriptor()
+}
+
+func (NoteEventType) Type() protoreflect.EnumType {
+	return &file_note_proto_enumTypes[2]
+}
+
+func (x NoteEventType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use NoteEventType.Descriptor instead.
+func (NoteEventType) EnumDescriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{2}
+}
+
+// Доменная сущность "Заметка" в формате protobuf.
+// Это не Go-структура, но protoc сгенерирует под неё Go-структуру pb.Note.
 type Note struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`                                 // Уникальный идентификатор (UUID в нашем примере)
-	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`                           // Заголовок
-	Content       string                 `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"`                       // Текст
-	CreatedAt     int64                  `protobuf:"varint,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"` // Unix seconds (для простоты)
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Id        string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`                                 // Поле №1: уникальный ID заметки (в нашем примере UUID).
+	Title     string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`                           // Поле №2: заголовок заметки.
+	Content   string                 `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"`                       // Поле №3: содержимое заметки.
+	CreatedAt int64                  `protobuf:"varint,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"` // Поле №4: время создания в Unix секундах.
+	// В Go будет int64, потом мы можем конвертить в time.Time.
+	UpdatedAt int64  `protobuf:"varint,5,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"` // Поле №5: время последнего изменения в Unix секундах.
+	OwnerId   string `protobuf:"bytes,6,opt,name=owner_id,json=ownerId,proto3" json:"owner_id,omitempty"`        // Поле №6: владелец (sub из токена); выставляет сервер, клиент не задаёт.
+	Version   int64  `protobuf:"varint,7,opt,name=version,proto3" json:"version,omitempty"`                      // Поле №7: номер версии; новая заметка — 1, каждое изменение +1.
+	DeletedAt int64  `protobuf:"varint,8,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"` // Поле №8: когда заметку убрали в корзину (Unix секунды); 0 — не удалена.
+	// Поле №9: теги. Сервер хранит их в нижнем регистре, без повторов, по алфавиту.
+	Tags []string `protobuf:"bytes,9,rep,name=tags,proto3" json:"tags,omitempty"`
+	// Поле №10: вложения в порядке загрузки (только метаданные; данные — DownloadAttachment).
+	Attachments   []*Attachment `protobuf:"bytes,10,rep,name=attachments,proto3" json:"attachments,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -89,17 +273,153 @@ func (x *Note) GetCreatedAt() int64 {
 	return 0
 }
 
-type CreateNoteRequest struct {
+func (x *Note) GetUpdatedAt() int64 {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return 0
+}
+
+func (x *Note) GetOwnerId() string {
+	if x != nil {
+		return x.OwnerId
+	}
+	return ""
+}
+
+func (x *Note) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Note) GetDeletedAt() int64 {
+	if x != nil {
+		return x.DeletedAt
+	}
+	return 0
+}
+
+func (x *Note) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Note) GetAttachments() []*Attachment {
+	if x != nil {
+		return x.Attachments
+	}
+	return nil
+}
+
+// Файл, прикреплённый к заметке.
+type Attachment struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Title         string                 `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
-	Content       string                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`                                  // Имя файла без каталогов.
+	ContentType   string                 `protobuf:"bytes,3,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"` // MIME-тип; по умолчанию application/octet-stream.
+	Size          int64                  `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`                                 // Размер в байтах.
+	Sha256        string                 `protobuf:"bytes,5,opt,name=sha256,proto3" json:"sha256,omitempty"`                              // SHA-256 содержимого в hex.
+	CreatedAt     int64                  `protobuf:"varint,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`      // Когда загружен (Unix секунды).
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Attachment) Reset() {
+	*x = Attachment{}
+	mi := &file_note_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Attachment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Attachment) ProtoMessage() {}
+
+func (x *Attachment) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Attachment.ProtoReflect.Descriptor instead.
+func (*Attachment) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Attachment) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Attachment) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Attachment) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *Attachment) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *Attachment) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+func (x *Attachment) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+// Запрос на создание заметки.
+// Содержит только то, что клиент должен прислать (title и content).
+type CreateNoteRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Title   string                 `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`     // Заголовок новой заметки.
+	Content string                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"` // Текст новой заметки.
+	// Необязательный ключ идемпотентности (например, UUID, сгенерированный клиентом).
+	// Повтор с тем же ключом в течение окна сервера вернёт ту же заметку, а не создаст новую;
+	// тот же ключ с другими title/content/tags — ошибка FAILED_PRECONDITION.
+	// В BulkCreateNotes не используется.
+	IdempotencyKey string `protobuf:"bytes,3,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	// Теги новой заметки: до 20 штук, каждый до 64 символов, без пробелов и запятых.
+	Tags          []string `protobuf:"bytes,4,rep,name=tags,proto3" json:"tags,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *CreateNoteRequest) Reset() {
 	*x = CreateNoteRequest{}
-	mi := &file_note_proto_msgTypes[1]
+	mi := &file_note_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -111,7 +431,7 @@ func (x *CreateNoteRequest) String() string {
 func (*CreateNoteRequest) ProtoMessage() {}
 
 func (x *CreateNoteRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_note_proto_msgTypes[1]
+	mi := &file_note_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -124,7 +444,7 @@ func (x *CreateNoteRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateNoteRequest.ProtoReflect.Descriptor instead.
 func (*CreateNoteRequest) Descriptor() ([]byte, []int) {
-	return file_note_proto_rawDescGZIP(), []int{1}
+	return file_note_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *CreateNoteRequest) GetTitle() string {
@@ -141,16 +461,32 @@ func (x *CreateNoteRequest) GetContent() string {
 	return ""
 }
 
+func (x *CreateNoteRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *CreateNoteRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+// Ответ на создание заметки.
+// Возвращаем целиком созданный объект Note (с ID и временем создания).
 type CreateNoteResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Note          *Note                  `protobuf:"bytes,1,opt,name=note,proto3" json:"note,omitempty"`
+	Note          *Note                  `protobuf:"bytes,1,opt,name=note,proto3" json:"note,omitempty"` // Поле №1: сама заметка.
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *CreateNoteResponse) Reset() {
 	*x = CreateNoteResponse{}
-	mi := &file_note_proto_msgTypes[2]
+	mi := &file_note_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -162,7 +498,7 @@ func (x *CreateNoteResponse) String() string {
 func (*CreateNoteResponse) ProtoMessage() {}
 
 func (x *CreateNoteResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_note_proto_msgTypes[2]
+	mi := &file_note_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -175,7 +511,7 @@ func (x *CreateNoteResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CreateNoteResponse.ProtoReflect.Descriptor instead.
 func (*CreateNoteResponse) Descriptor() ([]byte, []int) {
-	return file_note_proto_rawDescGZIP(), []int{2}
+	return file_note_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *CreateNoteResponse) GetNote() *Note {
@@ -185,16 +521,17 @@ func (x *CreateNoteResponse) GetNote() *Note {
 	return nil
 }
 
+// Запрос на получение заметки по ID.
 type GetNoteRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"` // ID заметки, которую хотим получить.
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *GetNoteRequest) Reset() {
 	*x = GetNoteRequest{}
-	mi := &file_note_proto_msgTypes[3]
+	mi := &file_note_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -206,7 +543,7 @@ func (x *GetNoteRequest) String() string {
 func (*GetNoteRequest) ProtoMessage() {}
 
 func (x *GetNoteRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_note_proto_msgTypes[3]
+	mi := &file_note_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -219,7 +556,7 @@ func (x *GetNoteRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetNoteRequest.ProtoReflect.Descriptor instead.
 func (*GetNoteRequest) Descriptor() ([]byte, []int) {
-	return file_note_proto_rawDescGZIP(), []int{3}
+	return file_note_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *GetNoteRequest) GetId() string {
@@ -229,16 +566,17 @@ func (x *GetNoteRequest) GetId() string {
 	return ""
 }
 
+// Ответ на получение заметки по ID.
 type GetNoteResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Note          *Note                  `protobuf:"bytes,1,opt,name=note,proto3" json:"note,omitempty"`
+	Note          *Note                  `protobuf:"bytes,1,opt,name=note,proto3" json:"note,omitempty"` // Найденная заметка.
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *GetNoteResponse) Reset() {
 	*x = GetNoteResponse{}
-	mi := &file_note_proto_msgTypes[4]
+	mi := &file_note_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -250,7 +588,7 @@ func (x *GetNoteResponse) String() string {
 func (*GetNoteResponse) ProtoMessage() {}
 
 func (x *GetNoteResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_note_proto_msgTypes[4]
+	mi := &file_note_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -263,7 +601,7 @@ func (x *GetNoteResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetNoteResponse.ProtoReflect.Descriptor instead.
 func (*GetNoteResponse) Descriptor() ([]byte, []int) {
-	return file_note_proto_rawDescGZIP(), []int{4}
+	return file_note_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *GetNoteResponse) GetNote() *Note {
@@ -273,27 +611,37 @@ func (x *GetNoteResponse) GetNote() *Note {
 	return nil
 }
 
-type ListNotesRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+// Запрос на изменение заметки.
+// update_mask перечисляет поля, которые нужно поменять ("title", "content").
+// Пустая маска означает "обновить все изменяемые поля".
+type UpdateNoteRequest struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Id         string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`                                   // ID изменяемой заметки.
+	Title      string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`                             // Новый заголовок.
+	Content    string                 `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"`                         // Новый текст.
+	UpdateMask *fieldmaskpb.FieldMask `protobuf:"bytes,4,opt,name=update_mask,json=updateMask,proto3" json:"update_mask,omitempty"` // Какие поля трогаем.
+	// Версия, которую видел клиент. Заметку успели изменить — ABORTED
+	// (в ErrorInfo — current_version). 0 — без проверки.
+	ExpectedVersion int64 `protobuf:"varint,5,opt,name=expected_version,json=expectedVersion,proto3" json:"expected_version,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
-func (x *ListNotesRequest) Reset() {
-	*x = ListNotesRequest{}
-	mi := &file_note_proto_msgTypes[5]
+func (x *UpdateNoteRequest) Reset() {
+	*x = UpdateNoteRequest{}
+	mi := &file_note_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListNotesRequest) String() string {
+func (x *UpdateNoteRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListNotesRequest) ProtoMessage() {}
+func (*UpdateNoteRequest) ProtoMessage() {}
 
-func (x *ListNotesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_note_proto_msgTypes[5]
+func (x *UpdateNoteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -304,33 +652,69 @@ func (x *ListNotesRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListNotesRequest.ProtoReflect.Descriptor instead.
-func (*ListNotesRequest) Descriptor() ([]byte, []int) {
-	return file_note_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use UpdateNoteRequest.ProtoReflect.Descriptor instead.
+func (*UpdateNoteRequest) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{6}
 }
 
-type ListNotesResponse struct {
+func (x *UpdateNoteRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UpdateNoteRequest) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *UpdateNoteRequest) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *UpdateNoteRequest) GetUpdateMask() *fieldmaskpb.FieldMask {
+	if x != nil {
+		return x.UpdateMask
+	}
+	return nil
+}
+
+func (x *UpdateNoteRequest) GetExpectedVersion() int64 {
+	if x != nil {
+		return x.ExpectedVersion
+	}
+	return 0
+}
+
+// Ответ на изменение заметки — заметка после изменения.
+type UpdateNoteResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Notes         []*Note                `protobuf:"bytes,1,rep,name=notes,proto3" json:"notes,omitempty"`
+	Note          *Note                  `protobuf:"bytes,1,opt,name=note,proto3" json:"note,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListNotesResponse) Reset() {
-	*x = ListNotesResponse{}
-	mi := &file_note_proto_msgTypes[6]
+func (x *UpdateNoteResponse) Reset() {
+	*x = UpdateNoteResponse{}
+	mi := &file_note_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListNotesResponse) String() string {
+func (x *UpdateNoteResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListNotesResponse) ProtoMessage() {}
+func (*UpdateNoteResponse) ProtoMessage() {}
 
-func (x *ListNotesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_note_proto_msgTypes[6]
+func (x *UpdateNoteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -341,47 +725,3531 @@ func (x *ListNotesResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListNotesResponse.ProtoReflect.Descriptor instead.
-func (*ListNotesResponse) Descriptor() ([]byte, []int) {
-	return file_note_proto_rawDescGZIP(), []int{6}
+// Deprecated: Use UpdateNoteResponse.ProtoReflect.Descriptor instead.
+func (*UpdateNoteResponse) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *UpdateNoteResponse) GetNote() *Note {
+	if x != nil {
+		return x.Note
+	}
+	return nil
+}
+
+// Запрос на удаление заметки по ID. Удаление мягкое: заметка уходит в корзину
+// (ListTrash), откуда её можно вернуть (RestoreNote) до окончательного удаления.
+type DeleteNoteRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ExpectedVersion int64                  `protobuf:"varint,2,opt,name=expected_version,json=expectedVersion,proto3" json:"expected_version,omitempty"` // Как в UpdateNoteRequest: не совпала — ABORTED, 0 — без проверки.
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *DeleteNoteRequest) Reset() {
+	*x = DeleteNoteRequest{}
+	mi := &file_note_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteNoteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteNoteRequest) ProtoMessage() {}
+
+func (x *DeleteNoteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteNoteRequest.ProtoReflect.Descriptor instead.
+func (*DeleteNoteRequest) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *DeleteNoteRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *DeleteNoteRequest) GetExpectedVersion() int64 {
+	if x != nil {
+		return x.ExpectedVersion
+	}
+	return 0
+}
+
+// Ответ на удаление. Полей нет: успех = отсутствие ошибки.
+type DeleteNoteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteNoteResponse) Reset() {
+	*x = DeleteNoteResponse{}
+	mi := &file_note_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteNoteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteNoteResponse) ProtoMessage() {}
+
+func (x *DeleteNoteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteNoteResponse.ProtoReflect.Descriptor instead.
+func (*DeleteNoteResponse) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{9}
+}
+
+// Запрос на страницу корзины. Поля и пагинация — как в ListNotesRequest.
+type ListTrashRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PageSize      int32                  `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken     string                 `protobuf:"bytes,2,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	OrderBy       string                 `protobuf:"bytes,3,opt,name=order_by,json=orderBy,proto3" json:"order_by,omitempty"`
+	TitlePrefix   string                 `protobuf:"bytes,4,opt,name=title_prefix,json=titlePrefix,proto3" json:"title_prefix,omitempty"` // Фильтр по началу заголовка; зашит в page_token, как в ListNotes.
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTrashRequest) Reset() {
+	*x = ListTrashRequest{}
+	mi := &file_note_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTrashRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTrashRequest) ProtoMessage() {}
+
+func (x *ListTrashRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTrashRequest.ProtoReflect.Descriptor instead.
+func (*ListTrashRequest) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ListTrashRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListTrashRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *ListTrashRequest) GetOrderBy() string {
+	if x != nil {
+		return x.OrderBy
+	}
+	return ""
+}
+
+func (x *ListTrashRequest) GetTitlePrefix() string {
+	if x != nil {
+		return x.TitlePrefix
+	}
+	return ""
+}
+
+// Страница корзины: удалённые заметки с заполненным deleted_at.
+type ListTrashResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Notes         []*Note                `protobuf:"bytes,1,rep,name=notes,proto3" json:"notes,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTrashResponse) Reset() {
+	*x = ListTrashResponse{}
+	mi := &file_note_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTrashResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTrashResponse) ProtoMessage() {}
+
+func (x *ListTrashResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTrashResponse.ProtoReflect.Descriptor instead.
+func (*ListTrashResponse) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ListTrashResponse) GetNotes() []*Note {
+	if x != nil {
+		return x.Notes
+	}
+	return nil
+}
+
+func (x *ListTrashResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+// Запрос на возврат заметки из корзины.
+type RestoreNoteRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ExpectedVersion int64                  `protobuf:"varint,2,opt,name=expected_version,json=expectedVersion,proto3" json:"expected_version,omitempty"` // Как в UpdateNoteRequest: не совпала — ABORTED, 0 — без проверки.
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *RestoreNoteRequest) Reset() {
+	*x = RestoreNoteRequest{}
+	mi := &file_note_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreNoteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreNoteRequest) ProtoMessage() {}
+
+func (x *RestoreNoteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreNoteRequest.ProtoReflect.Descriptor instead.
+func (*RestoreNoteRequest) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *RestoreNoteRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *RestoreNoteRequest) GetExpectedVersion() int64 {
+	if x != nil {
+		return x.ExpectedVersion
+	}
+	return 0
+}
+
+// Ответ на возврат — заметка после возврата.
+type RestoreNoteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Note          *Note                  `protobuf:"bytes,1,opt,name=note,proto3" json:"note,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreNoteResponse) Reset() {
+	*x = RestoreNoteResponse{}
+	mi := &file_note_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreNoteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreNoteResponse) ProtoMessage() {}
+
+func (x *RestoreNoteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreNoteResponse.ProtoReflect.Descriptor instead.
+func (*RestoreNoteResponse) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *RestoreNoteResponse) GetNote() *Note {
+	if x != nil {
+		return x.Note
+	}
+	return nil
+}
+
+// Запрос на окончательное удаление заметки из корзины.
+type PurgeNoteRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ExpectedVersion int64                  `protobuf:"varint,2,opt,name=expected_version,json=expectedVersion,proto3" json:"expected_version,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *PurgeNoteRequest) Reset() {
+	*x = PurgeNoteRequest{}
+	mi := &file_note_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PurgeNoteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PurgeNoteRequest) ProtoMessage() {}
+
+func (x *PurgeNoteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PurgeNoteRequest.ProtoReflect.Descriptor instead.
+func (*PurgeNoteRequest) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *PurgeNoteRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *PurgeNoteRequest) GetExpectedVersion() int64 {
+	if x != nil {
+		return x.ExpectedVersion
+	}
+	return 0
+}
+
+// Ответ на окончательное удаление. Полей нет.
+type PurgeNoteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PurgeNoteResponse) Reset() {
+	*x = PurgeNoteResponse{}
+	mi := &file_note_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PurgeNoteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PurgeNoteResponse) ProtoMessage() {}
+
+func (x *PurgeNoteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PurgeNoteResponse.ProtoReflect.Descriptor instead.
+func (*PurgeNoteResponse) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{15}
+}
+
+// Запрос на список заметок (одна страница).
+// Пагинация курсорная: клиент передаёт next_page_token из прошлого ответа в page_token.
+type ListNotesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	PageSize      int32                  `protobuf:"varint,1,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`                       // Размер страницы. 0 — по умолчанию (50), максимум 1000.
+	PageToken     string                 `protobuf:"bytes,2,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`                     // Непрозрачный курсор из ListNotesResponse.next_page_token.
+	OrderBy       string                 `protobuf:"bytes,3,opt,name=order_by,json=orderBy,proto3" json:"order_by,omitempty"`                           // "created_at" (по умолчанию), "created_at desc", "title", "title desc".
+	TitlePrefix   string                 `protobuf:"bytes,4,opt,name=title_prefix,json=titlePrefix,proto3" json:"title_prefix,omitempty"`               // Фильтр: только заметки, чей заголовок начинается с этой строки.
+	Tags          []string               `protobuf:"bytes,5,rep,name=tags,proto3" json:"tags,omitempty"`                                                // Фильтр по тегам (до 16); пусто — без фильтра.
+	TagMatch      TagMatch               `protobuf:"varint,6,opt,name=tag_match,json=tagMatch,proto3,enum=note.v1.TagMatch" json:"tag_match,omitempty"` // Любой из tags или все сразу.
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListNotesRequest) Reset() {
+	*x = ListNotesRequest{}
+	mi := &file_note_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListNotesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListNotesRequest) ProtoMessage() {}
+
+func (x *ListNotesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListNotesRequest.ProtoReflect.Descriptor instead.
+func (*ListNotesRequest) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ListNotesRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListNotesRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *ListNotesRequest) GetOrderBy() string {
+	if x != nil {
+		return x.OrderBy
+	}
+	return ""
+}
+
+func (x *ListNotesRequest) GetTitlePrefix() string {
+	if x != nil {
+		return x.TitlePrefix
+	}
+	return ""
+}
+
+func (x *ListNotesRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *ListNotesRequest) GetTagMatch() TagMatch {
+	if x != nil {
+		return x.TagMatch
+	}
+	return TagMatch_TAG_MATCH_UNSPECIFIED
+}
+
+// Ответ на список заметок.
+// repeated означает "повторяющееся поле" (массив/слайс).
+// В Go это будет []*pb.Note.
+type ListNotesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Notes         []*Note                `protobuf:"bytes,1,rep,name=notes,proto3" json:"notes,omitempty"`                                        // Заметки текущей страницы.
+	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"` // Курсор следующей страницы; пусто — страниц больше нет.
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListNotesResponse) Reset() {
+	*x = ListNotesResponse{}
+	mi := &file_note_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListNotesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListNotesResponse) ProtoMessage() {}
+
+func (x *ListNotesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListNotesResponse.ProtoReflect.Descriptor instead.
+func (*ListNotesResponse) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *ListNotesResponse) GetNotes() []*Note {
 	if x != nil {
-		return x.Notes
+		return x.Notes
+	}
+	return nil
+}
+
+func (x *ListNotesResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+// Запрос на добавление тегов. Уже имеющиеся теги не дублируются.
+type AddTagsRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Tags            []string               `protobuf:"bytes,2,rep,name=tags,proto3" json:"tags,omitempty"`
+	ExpectedVersion int64                  `protobuf:"varint,3,opt,name=expected_version,json=expectedVersion,proto3" json:"expected_version,omitempty"` // Как в UpdateNoteRequest: не совпала — ABORTED, 0 — без проверки.
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *AddTagsRequest) Reset() {
+	*x = AddTagsRequest{}
+	mi := &file_note_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddTagsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddTagsRequest) ProtoMessage() {}
+
+func (x *AddTagsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddTagsRequest.ProtoReflect.Descriptor instead.
+func (*AddTagsRequest) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *AddTagsRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *AddTagsRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *AddTagsRequest) GetExpectedVersion() int64 {
+	if x != nil {
+		return x.ExpectedVersion
+	}
+	return 0
+}
+
+type AddTagsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Note          *Note                  `protobuf:"bytes,1,opt,name=note,proto3" json:"note,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddTagsResponse) Reset() {
+	*x = AddTagsResponse{}
+	mi := &file_note_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddTagsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddTagsResponse) ProtoMessage() {}
+
+func (x *AddTagsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddTagsResponse.ProtoReflect.Descriptor instead.
+func (*AddTagsResponse) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *AddTagsResponse) GetNote() *Note {
+	if x != nil {
+		return x.Note
+	}
+	return nil
+}
+
+// Запрос на снятие тегов. Теги, которых у заметки нет, игнорируются.
+type RemoveTagsRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Tags            []string               `protobuf:"bytes,2,rep,name=tags,proto3" json:"tags,omitempty"`
+	ExpectedVersion int64                  `protobuf:"varint,3,opt,name=expected_version,json=expectedVersion,proto3" json:"expected_version,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *RemoveTagsRequest) Reset() {
+	*x = RemoveTagsRequest{}
+	mi := &file_note_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveTagsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveTagsRequest) ProtoMessage() {}
+
+func (x *RemoveTagsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveTagsRequest.ProtoReflect.Descriptor instead.
+func (*RemoveTagsRequest) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *RemoveTagsRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *RemoveTagsRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *RemoveTagsRequest) GetExpectedVersion() int64 {
+	if x != nil {
+		return x.ExpectedVersion
+	}
+	return 0
+}
+
+type RemoveTagsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Note          *Note                  `protobuf:"bytes,1,opt,name=note,proto3" json:"note,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveTagsResponse) Reset() {
+	*x = RemoveTagsResponse{}
+	mi := &file_note_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveTagsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveTagsResponse) ProtoMessage() {}
+
+func (x *RemoveTagsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveTagsResponse.ProtoReflect.Descriptor instead.
+func (*RemoveTagsResponse) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *RemoveTagsResponse) GetNote() *Note {
+	if x != nil {
+		return x.Note
+	}
+	return nil
+}
+
+// Запрос списка тегов вызывающего. Полей нет.
+type ListTagsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTagsRequest) Reset() {
+	*x = ListTagsRequest{}
+	mi := &file_note_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTagsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTagsRequest) ProtoMessage() {}
+
+func (x *ListTagsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTagsRequest.ProtoReflect.Descriptor instead.
+func (*ListTagsRequest) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{22}
+}
+
+// Тег и число заметок с ним (заметки в корзине не считаются).
+type TagCount struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tag           string                 `protobuf:"bytes,1,opt,name=tag,proto3" json:"tag,omitempty"`
+	Count         int32                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TagCount) Reset() {
+	*x = TagCount{}
+	mi := &file_note_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TagCount) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TagCount) ProtoMessage() {}
+
+func (x *TagCount) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TagCount.ProtoReflect.Descriptor instead.
+func (*TagCount) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *TagCount) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+func (x *TagCount) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+// Все теги вызывающего по алфавиту.
+type ListTagsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tags          []*TagCount            `protobuf:"bytes,1,rep,name=tags,proto3" json:"tags,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTagsResponse) Reset() {
+	*x = ListTagsResponse{}
+	mi := &file_note_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTagsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTagsResponse) ProtoMessage() {}
+
+func (x *ListTagsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTagsResponse.ProtoReflect.Descriptor instead.
+func (*ListTagsResponse) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ListTagsResponse) GetTags() []*TagCount {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+// Первое сообщение UploadAttachment: что за файл и к какой заметке.
+type AttachmentUpload struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	NoteId          string                 `protobuf:"bytes,1,opt,name=note_id,json=noteId,proto3" json:"note_id,omitempty"`
+	Name            string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	ContentType     string                 `protobuf:"bytes,3,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`              // Пусто — application/octet-stream.
+	Sha256          string                 `protobuf:"bytes,4,opt,name=sha256,proto3" json:"sha256,omitempty"`                                           // SHA-256 всего файла в hex; сверяется после загрузки.
+	ExpectedVersion int64                  `protobuf:"varint,5,opt,name=expected_version,json=expectedVersion,proto3" json:"expected_version,omitempty"` // Как в UpdateNoteRequest: не совпала — ABORTED, 0 — без проверки.
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *AttachmentUpload) Reset() {
+	*x = AttachmentUpload{}
+	mi := &file_note_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AttachmentUpload) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttachmentUpload) ProtoMessage() {}
+
+func (x *AttachmentUpload) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttachmentUpload.ProtoReflect.Descriptor instead.
+func (*AttachmentUpload) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *AttachmentUpload) GetNoteId() string {
+	if x != nil {
+		return x.NoteId
+	}
+	return ""
+}
+
+func (x *AttachmentUpload) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *AttachmentUpload) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *AttachmentUpload) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+func (x *AttachmentUpload) GetExpectedVersion() int64 {
+	if x != nil {
+		return x.ExpectedVersion
+	}
+	return 0
+}
+
+// Сообщение потока UploadAttachment: сначала metadata, потом данные кусками.
+type UploadAttachmentRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Data:
+	//
+	//	*UploadAttachmentRequest_Metadata
+	//	*UploadAttachmentRequest_Chunk
+	Data          isUploadAttachmentRequest_Data `protobuf_oneof:"data"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadAttachmentRequest) Reset() {
+	*x = UploadAttachmentRequest{}
+	mi := &file_note_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadAttachmentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadAttachmentRequest) ProtoMessage() {}
+
+func (x *UploadAttachmentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadAttachmentRequest.ProtoReflect.Descriptor instead.
+func (*UploadAttachmentRequest) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *UploadAttachmentRequest) GetData() isUploadAttachmentRequest_Data {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *UploadAttachmentRequest) GetMetadata() *AttachmentUpload {
+	if x != nil {
+		if x, ok := x.Data.(*UploadAttachmentRequest_Metadata); ok {
+			return x.Metadata
+		}
+	}
+	return nil
+}
+
+func (x *UploadAttachmentRequest) GetChunk() []byte {
+	if x != nil {
+		if x, ok := x.Data.(*UploadAttachmentRequest_Chunk); ok {
+			return x.Chunk
+		}
+	}
+	return nil
+}
+
+type isUploadAttachmentRequest_Data interface {
+	isUploadAttachmentRequest_Data()
+}
+
+type UploadAttachmentRequest_Metadata struct {
+	Metadata *AttachmentUpload `protobuf:"bytes,1,opt,name=metadata,proto3,oneof"`
+}
+
+type UploadAttachmentRequest_Chunk struct {
+	Chunk []byte `protobuf:"bytes,2,opt,name=chunk,proto3,oneof"`
+}
+
+func (*UploadAttachmentRequest_Metadata) isUploadAttachmentRequest_Data() {}
+
+func (*UploadAttachmentRequest_Chunk) isUploadAttachmentRequest_Data() {}
+
+type UploadAttachmentResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Note          *Note                  `protobuf:"bytes,1,opt,name=note,proto3" json:"note,omitempty"`             // Заметка в новой версии.
+	Attachment    *Attachment            `protobuf:"bytes,2,opt,name=attachment,proto3" json:"attachment,omitempty"` // Загруженное вложение.
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadAttachmentResponse) Reset() {
+	*x = UploadAttachmentResponse{}
+	mi := &file_note_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadAttachmentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadAttachmentResponse) ProtoMessage() {}
+
+func (x *UploadAttachmentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadAttachmentResponse.ProtoReflect.Descriptor instead.
+func (*UploadAttachmentResponse) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *UploadAttachmentResponse) GetNote() *Note {
+	if x != nil {
+		return x.Note
+	}
+	return nil
+}
+
+func (x *UploadAttachmentResponse) GetAttachment() *Attachment {
+	if x != nil {
+		return x.Attachment
+	}
+	return nil
+}
+
+type DownloadAttachmentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	NoteId        string                 `protobuf:"bytes,1,opt,name=note_id,json=noteId,proto3" json:"note_id,omitempty"`
+	AttachmentId  string                 `protobuf:"bytes,2,opt,name=attachment_id,json=attachmentId,proto3" json:"attachment_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DownloadAttachmentRequest) Reset() {
+	*x = DownloadAttachmentRequest{}
+	mi := &file_note_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DownloadAttachmentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownloadAttachmentRequest) ProtoMessage() {}
+
+func (x *DownloadAttachmentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DownloadAttachmentRequest.ProtoReflect.Descriptor instead.
+func (*DownloadAttachmentRequest) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *DownloadAttachmentRequest) GetNoteId() string {
+	if x != nil {
+		return x.NoteId
+	}
+	return ""
+}
+
+func (x *DownloadAttachmentRequest) GetAttachmentId() string {
+	if x != nil {
+		return x.AttachmentId
+	}
+	return ""
+}
+
+// Сообщение потока DownloadAttachment: сначала attachment, потом данные кусками.
+type DownloadAttachmentResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Data:
+	//
+	//	*DownloadAttachmentResponse_Attachment
+	//	*DownloadAttachmentResponse_Chunk
+	Data          isDownloadAttachmentResponse_Data `protobuf_oneof:"data"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DownloadAttachmentResponse) Reset() {
+	*x = DownloadAttachmentResponse{}
+	mi := &file_note_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DownloadAttachmentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DownloadAttachmentResponse) ProtoMessage() {}
+
+func (x *DownloadAttachmentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DownloadAttachmentResponse.ProtoReflect.Descriptor instead.
+func (*DownloadAttachmentResponse) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *DownloadAttachmentResponse) GetData() isDownloadAttachmentResponse_Data {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *DownloadAttachmentResponse) GetAttachment() *Attachment {
+	if x != nil {
+		if x, ok := x.Data.(*DownloadAttachmentResponse_Attachment); ok {
+			return x.Attachment
+		}
+	}
+	return nil
+}
+
+func (x *DownloadAttachmentResponse) GetChunk() []byte {
+	if x != nil {
+		if x, ok := x.Data.(*DownloadAttachmentResponse_Chunk); ok {
+			return x.Chunk
+		}
+	}
+	return nil
+}
+
+type isDownloadAttachmentResponse_Data interface {
+	isDownloadAttachmentResponse_Data()
+}
+
+type DownloadAttachmentResponse_Attachment struct {
+	Attachment *Attachment `protobuf:"bytes,1,opt,name=attachment,proto3,oneof"`
+}
+
+type DownloadAttachmentResponse_Chunk struct {
+	Chunk []byte `protobuf:"bytes,2,opt,name=chunk,proto3,oneof"`
+}
+
+func (*DownloadAttachmentResponse_Attachment) isDownloadAttachmentResponse_Data() {}
+
+func (*DownloadAttachmentResponse_Chunk) isDownloadAttachmentResponse_Data() {}
+
+// Запрос истории заметки. Ревизии идут от новых к старым, первой — текущая версия.
+type ListNoteRevisionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	PageSize      int32                  `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`   // 0 — по умолчанию (50), максимум 1000.
+	PageToken     string                 `protobuf:"bytes,3,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"` // Курсор из ListNoteRevisionsResponse.next_page_token.
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListNoteRevisionsRequest) Reset() {
+	*x = ListNoteRevisionsRequest{}
+	mi := &file_note_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListNoteRevisionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListNoteRevisionsRequest) ProtoMessage() {}
+
+func (x *ListNoteRevisionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListNoteRevisionsRequest.ProtoReflect.Descriptor instead.
+func (*ListNoteRevisionsRequest) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *ListNoteRevisionsRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ListNoteRevisionsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListNoteRevisionsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type ListNoteRevisionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Revisions     []*Note                `protobuf:"bytes,1,rep,name=revisions,proto3" json:"revisions,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"` // Пусто — история закончилась.
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListNoteRevisionsResponse) Reset() {
+	*x = ListNoteRevisionsResponse{}
+	mi := &file_note_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListNoteRevisionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListNoteRevisionsResponse) ProtoMessage() {}
+
+func (x *ListNoteRevisionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListNoteRevisionsResponse.ProtoReflect.Descriptor instead.
+func (*ListNoteRevisionsResponse) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *ListNoteRevisionsResponse) GetRevisions() []*Note {
+	if x != nil {
+		return x.Revisions
+	}
+	return nil
+}
+
+func (x *ListNoteRevisionsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+// Запрос одной ревизии. version — номер версии заметки (Note.version).
+type GetNoteRevisionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Version       int64                  `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetNoteRevisionRequest) Reset() {
+	*x = GetNoteRevisionRequest{}
+	mi := &file_note_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetNoteRevisionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetNoteRevisionRequest) ProtoMessage() {}
+
+func (x *GetNoteRevisionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetNoteRevisionRequest.ProtoReflect.Descriptor instead.
+func (*GetNoteRevisionRequest) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *GetNoteRevisionRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *GetNoteRevisionRequest) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type GetNoteRevisionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Revision      *Note                  `protobuf:"bytes,1,opt,name=revision,proto3" json:"revision,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetNoteRevisionResponse) Reset() {
+	*x = GetNoteRevisionResponse{}
+	mi := &file_note_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetNoteRevisionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetNoteRevisionResponse) ProtoMessage() {}
+
+func (x *GetNoteRevisionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetNoteRevisionResponse.ProtoReflect.Descriptor instead.
+func (*GetNoteRevisionResponse) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *GetNoteRevisionResponse) GetRevision() *Note {
+	if x != nil {
+		return x.Revision
+	}
+	return nil
+}
+
+// Запрос diff содержимого между двумя ревизиями.
+type DiffNoteRevisionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	FromVersion   int64                  `protobuf:"varint,2,opt,name=from_version,json=fromVersion,proto3" json:"from_version,omitempty"` // 0 — версия, предшествующая to_version.
+	ToVersion     int64                  `protobuf:"varint,3,opt,name=to_version,json=toVersion,proto3" json:"to_version,omitempty"`       // 0 — текущая версия.
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DiffNoteRevisionsRequest) Reset() {
+	*x = DiffNoteRevisionsRequest{}
+	mi := &file_note_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiffNoteRevisionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiffNoteRevisionsRequest) ProtoMessage() {}
+
+func (x *DiffNoteRevisionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiffNoteRevisionsRequest.ProtoReflect.Descriptor instead.
+func (*DiffNoteRevisionsRequest) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *DiffNoteRevisionsRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *DiffNoteRevisionsRequest) GetFromVersion() int64 {
+	if x != nil {
+		return x.FromVersion
+	}
+	return 0
+}
+
+func (x *DiffNoteRevisionsRequest) GetToVersion() int64 {
+	if x != nil {
+		return x.ToVersion
+	}
+	return 0
+}
+
+type DiffNoteRevisionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Diff          string                 `protobuf:"bytes,1,opt,name=diff,proto3" json:"diff,omitempty"`                                   // Построчный unified diff; пусто — содержимое не менялось.
+	FromVersion   int64                  `protobuf:"varint,2,opt,name=from_version,json=fromVersion,proto3" json:"from_version,omitempty"` // Фактически сравнённые версии (после подстановки значений по умолчанию).
+	ToVersion     int64                  `protobuf:"varint,3,opt,name=to_version,json=toVersion,proto3" json:"to_version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DiffNoteRevisionsResponse) Reset() {
+	*x = DiffNoteRevisionsResponse{}
+	mi := &file_note_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DiffNoteRevisionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DiffNoteRevisionsResponse) ProtoMessage() {}
+
+func (x *DiffNoteRevisionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DiffNoteRevisionsResponse.ProtoReflect.Descriptor instead.
+func (*DiffNoteRevisionsResponse) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *DiffNoteRevisionsResponse) GetDiff() string {
+	if x != nil {
+		return x.Diff
+	}
+	return ""
+}
+
+func (x *DiffNoteRevisionsResponse) GetFromVersion() int64 {
+	if x != nil {
+		return x.FromVersion
+	}
+	return 0
+}
+
+func (x *DiffNoteRevisionsResponse) GetToVersion() int64 {
+	if x != nil {
+		return x.ToVersion
+	}
+	return 0
+}
+
+// Запрос на откат: заголовок, содержимое и теги ревизии version становятся новой версией.
+type RevertNoteRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Version         int64                  `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	ExpectedVersion int64                  `protobuf:"varint,3,opt,name=expected_version,json=expectedVersion,proto3" json:"expected_version,omitempty"` // Как в UpdateNoteRequest: не совпала — ABORTED, 0 — без проверки.
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *RevertNoteRequest) Reset() {
+	*x = RevertNoteRequest{}
+	mi := &file_note_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevertNoteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevertNoteRequest) ProtoMessage() {}
+
+func (x *RevertNoteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevertNoteRequest.ProtoReflect.Descriptor instead.
+func (*RevertNoteRequest) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *RevertNoteRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *RevertNoteRequest) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *RevertNoteRequest) GetExpectedVersion() int64 {
+	if x != nil {
+		return x.ExpectedVersion
+	}
+	return 0
+}
+
+type RevertNoteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Note          *Note                  `protobuf:"bytes,1,opt,name=note,proto3" json:"note,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevertNoteResponse) Reset() {
+	*x = RevertNoteResponse{}
+	mi := &file_note_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevertNoteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevertNoteResponse) ProtoMessage() {}
+
+func (x *RevertNoteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevertNoteResponse.ProtoReflect.Descriptor instead.
+func (*RevertNoteResponse) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *RevertNoteResponse) GetNote() *Note {
+	if x != nil {
+		return x.Note
+	}
+	return nil
+}
+
+// Ошибка валидации одного элемента BulkCreateNotes.
+type BulkCreateFailure struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Index         int32                  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`  // Порядковый номер сообщения в потоке клиента (с нуля).
+	Field         string                 `protobuf:"bytes,2,opt,name=field,proto3" json:"field,omitempty"`   // Какое поле не прошло проверку, например "title".
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"` // Человекочитаемая причина.
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BulkCreateFailure) Reset() {
+	*x = BulkCreateFailure{}
+	mi := &file_note_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BulkCreateFailure) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BulkCreateFailure) ProtoMessage() {}
+
+func (x *BulkCreateFailure) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BulkCreateFailure.ProtoReflect.Descriptor instead.
+func (*BulkCreateFailure) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *BulkCreateFailure) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *BulkCreateFailure) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *BulkCreateFailure) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+// Итог импорта BulkCreateNotes.
+type BulkCreateNotesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Received      int32                  `protobuf:"varint,1,opt,name=received,proto3" json:"received,omitempty"`                      // Сколько сообщений прислал клиент.
+	CreatedIds    []string               `protobuf:"bytes,2,rep,name=created_ids,json=createdIds,proto3" json:"created_ids,omitempty"` // ID созданных заметок в порядке потока.
+	Failures      []*BulkCreateFailure   `protobuf:"bytes,3,rep,name=failures,proto3" json:"failures,omitempty"`                       // Элементы, которые не создались.
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BulkCreateNotesResponse) Reset() {
+	*x = BulkCreateNotesResponse{}
+	mi := &file_note_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BulkCreateNotesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BulkCreateNotesResponse) ProtoMessage() {}
+
+func (x *BulkCreateNotesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BulkCreateNotesResponse.ProtoReflect.Descriptor instead.
+func (*BulkCreateNotesResponse) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *BulkCreateNotesResponse) GetReceived() int32 {
+	if x != nil {
+		return x.Received
+	}
+	return 0
+}
+
+func (x *BulkCreateNotesResponse) GetCreatedIds() []string {
+	if x != nil {
+		return x.CreatedIds
+	}
+	return nil
+}
+
+func (x *BulkCreateNotesResponse) GetFailures() []*BulkCreateFailure {
+	if x != nil {
+		return x.Failures
+	}
+	return nil
+}
+
+// Запрос полнотекстового поиска.
+// query — слова через пробел, все должны встретиться в заметке (AND);
+// слово со звёздочкой на конце ищется по префиксу: "prog*" найдёт "programming".
+type SearchNotesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Query         string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"` // Сколько результатов вернуть. 0 — 20, максимум 100.
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchNotesRequest) Reset() {
+	*x = SearchNotesRequest{}
+	mi := &file_note_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchNotesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchNotesRequest) ProtoMessage() {}
+
+func (x *SearchNotesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchNotesRequest.ProtoReflect.Descriptor instead.
+func (*SearchNotesRequest) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *SearchNotesRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchNotesRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+// Одна найденная заметка.
+type SearchHit struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Note          *Note                  `protobuf:"bytes,1,opt,name=note,proto3" json:"note,omitempty"`
+	Score         float64                `protobuf:"fixed64,2,opt,name=score,proto3" json:"score,omitempty"`     // Вес: сумма частот совпавших слов. Выше — релевантнее.
+	Snippets      []string               `protobuf:"bytes,3,rep,name=snippets,proto3" json:"snippets,omitempty"` // Фрагменты текста в HTML: текст экранирован, совпадения обёрнуты в <mark>...</mark>.
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchHit) Reset() {
+	*x = SearchHit{}
+	mi := &file_note_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchHit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchHit) ProtoMessage() {}
+
+func (x *SearchHit) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchHit.ProtoReflect.Descriptor instead.
+func (*SearchHit) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *SearchHit) GetNote() *Note {
+	if x != nil {
+		return x.Note
+	}
+	return nil
+}
+
+func (x *SearchHit) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *SearchHit) GetSnippets() []string {
+	if x != nil {
+		return x.Snippets
+	}
+	return nil
+}
+
+// Результаты поиска, отсортированные по убыванию score.
+type SearchNotesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hits          []*SearchHit           `protobuf:"bytes,1,rep,name=hits,proto3" json:"hits,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchNotesResponse) Reset() {
+	*x = SearchNotesResponse{}
+	mi := &file_note_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchNotesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchNotesResponse) ProtoMessage() {}
+
+func (x *SearchNotesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchNotesResponse.ProtoReflect.Descriptor instead.
+func (*SearchNotesResponse) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *SearchNotesResponse) GetHits() []*SearchHit {
+	if x != nil {
+		return x.Hits
+	}
+	return nil
+}
+
+// Запрос на отрисовку текста заметки (Markdown) в HTML.
+type RenderNoteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Version       int64                  `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"` // Версия из истории; 0 — текущая.
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenderNoteRequest) Reset() {
+	*x = RenderNoteRequest{}
+	mi := &file_note_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenderNoteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenderNoteRequest) ProtoMessage() {}
+
+func (x *RenderNoteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenderNoteRequest.ProtoReflect.Descriptor instead.
+func (*RenderNoteRequest) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *RenderNoteRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *RenderNoteRequest) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+// Заголовок отрисованной заметки — пункт оглавления.
+type NoteHeading struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Level         int32                  `protobuf:"varint,1,opt,name=level,proto3" json:"level,omitempty"` // 1–6, как у <h1>–<h6>.
+	Text          string                 `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`    // Текст без разметки.
+	Id            string                 `protobuf:"bytes,3,opt,name=id,proto3" json:"id,omitempty"`        // Якорь: атрибут id заголовка в html, ссылка — "#" + id.
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NoteHeading) Reset() {
+	*x = NoteHeading{}
+	mi := &file_note_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NoteHeading) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NoteHeading) ProtoMessage() {}
+
+func (x *NoteHeading) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NoteHeading.ProtoReflect.Descriptor instead.
+func (*NoteHeading) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *NoteHeading) GetLevel() int32 {
+	if x != nil {
+		return x.Level
+	}
+	return 0
+}
+
+func (x *NoteHeading) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *NoteHeading) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+// Ссылка из текста заметки.
+type NoteLink struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Text          string                 `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NoteLink) Reset() {
+	*x = NoteLink{}
+	mi := &file_note_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NoteLink) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NoteLink) ProtoMessage() {}
+
+func (x *NoteLink) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NoteLink.ProtoReflect.Descriptor instead.
+func (*NoteLink) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *NoteLink) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *NoteLink) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+type RenderNoteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Html          string                 `protobuf:"bytes,1,opt,name=html,proto3" json:"html,omitempty"`                             // Очищенный фрагмент HTML: без скриптов, обработчиков on* и стилей.
+	Toc           []*NoteHeading         `protobuf:"bytes,2,rep,name=toc,proto3" json:"toc,omitempty"`                               // Заголовки в порядке появления.
+	TocHtml       string                 `protobuf:"bytes,3,opt,name=toc_html,json=tocHtml,proto3" json:"toc_html,omitempty"`        // Оглавление вложенными <ul> со ссылками на якоря; нет заголовков — пусто.
+	Links         []*NoteLink            `protobuf:"bytes,4,rep,name=links,proto3" json:"links,omitempty"`                           // Ссылки без повторов (кроме тех, что вырезала очистка).
+	WordCount     int32                  `protobuf:"varint,5,opt,name=word_count,json=wordCount,proto3" json:"word_count,omitempty"` // Слов в тексте, без блоков кода.
+	Version       int64                  `protobuf:"varint,6,opt,name=version,proto3" json:"version,omitempty"`                      // Какая версия отрисована.
+	Title         string                 `protobuf:"bytes,7,opt,name=title,proto3" json:"title,omitempty"`                           // Заголовок этой версии (как есть, без экранирования).
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RenderNoteResponse) Reset() {
+	*x = RenderNoteResponse{}
+	mi := &file_note_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RenderNoteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RenderNoteResponse) ProtoMessage() {}
+
+func (x *RenderNoteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RenderNoteResponse.ProtoReflect.Descriptor instead.
+func (*RenderNoteResponse) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *RenderNoteResponse) GetHtml() string {
+	if x != nil {
+		return x.Html
+	}
+	return ""
+}
+
+func (x *RenderNoteResponse) GetToc() []*NoteHeading {
+	if x != nil {
+		return x.Toc
+	}
+	return nil
+}
+
+func (x *RenderNoteResponse) GetTocHtml() string {
+	if x != nil {
+		return x.TocHtml
+	}
+	return ""
+}
+
+func (x *RenderNoteResponse) GetLinks() []*NoteLink {
+	if x != nil {
+		return x.Links
+	}
+	return nil
+}
+
+func (x *RenderNoteResponse) GetWordCount() int32 {
+	if x != nil {
+		return x.WordCount
+	}
+	return 0
+}
+
+func (x *RenderNoteResponse) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *RenderNoteResponse) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+// Версия заметки в архиве. Поля — как у Note, но время — Timestamp с наносекундами:
+// выгрузка и загрузка обратно не должны его округлять, иначе импорт в тот же сервер
+// не узнает неизменённые заметки.
+type ArchivedNoteVersion struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Content       string                 `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	OwnerId       string                 `protobuf:"bytes,6,opt,name=owner_id,json=ownerId,proto3" json:"owner_id,omitempty"`
+	Version       int64                  `protobuf:"varint,7,opt,name=version,proto3" json:"version,omitempty"`
+	DeletedAt     *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"` // Не задано — не удалена.
+	Tags          []string               `protobuf:"bytes,9,rep,name=tags,proto3" json:"tags,omitempty"`
+	Attachments   []*ArchivedAttachment  `protobuf:"bytes,10,rep,name=attachments,proto3" json:"attachments,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ArchivedNoteVersion) Reset() {
+	*x = ArchivedNoteVersion{}
+	mi := &file_note_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ArchivedNoteVersion) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ArchivedNoteVersion) ProtoMessage() {}
+
+func (x *ArchivedNoteVersion) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ArchivedNoteVersion.ProtoReflect.Descriptor instead.
+func (*ArchivedNoteVersion) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *ArchivedNoteVersion) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ArchivedNoteVersion) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *ArchivedNoteVersion) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *ArchivedNoteVersion) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *ArchivedNoteVersion) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *ArchivedNoteVersion) GetOwnerId() string {
+	if x != nil {
+		return x.OwnerId
+	}
+	return ""
+}
+
+func (x *ArchivedNoteVersion) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *ArchivedNoteVersion) GetDeletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DeletedAt
+	}
+	return nil
+}
+
+func (x *ArchivedNoteVersion) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *ArchivedNoteVersion) GetAttachments() []*ArchivedAttachment {
+	if x != nil {
+		return x.Attachments
+	}
+	return nil
+}
+
+// Вложение в архиве: как Attachment, но время — Timestamp.
+type ArchivedAttachment struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	ContentType   string                 `protobuf:"bytes,3,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	Size          int64                  `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`
+	Sha256        string                 `protobuf:"bytes,5,opt,name=sha256,proto3" json:"sha256,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ArchivedAttachment) Reset() {
+	*x = ArchivedAttachment{}
+	mi := &file_note_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ArchivedAttachment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ArchivedAttachment) ProtoMessage() {}
+
+func (x *ArchivedAttachment) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ArchivedAttachment.ProtoReflect.Descriptor instead.
+func (*ArchivedAttachment) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *ArchivedAttachment) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ArchivedAttachment) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ArchivedAttachment) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *ArchivedAttachment) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *ArchivedAttachment) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+func (x *ArchivedAttachment) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+// Заметка в архиве: последняя версия и история (старые версии по возрастанию, без последней).
+type ExportedNote struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Note          *ArchivedNoteVersion   `protobuf:"bytes,1,opt,name=note,proto3" json:"note,omitempty"`
+	Revisions     []*ArchivedNoteVersion `protobuf:"bytes,2,rep,name=revisions,proto3" json:"revisions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportedNote) Reset() {
+	*x = ExportedNote{}
+	mi := &file_note_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportedNote) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportedNote) ProtoMessage() {}
+
+func (x *ExportedNote) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportedNote.ProtoReflect.Descriptor instead.
+func (*ExportedNote) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *ExportedNote) GetNote() *ArchivedNoteVersion {
+	if x != nil {
+		return x.Note
+	}
+	return nil
+}
+
+func (x *ExportedNote) GetRevisions() []*ArchivedNoteVersion {
+	if x != nil {
+		return x.Revisions
+	}
+	return nil
+}
+
+// Итог архива: сколько в нём заметок и SHA-256 их содержимого (см. pkg/archive).
+// Хеш считается по каноническому protobuf-представлению ExportedNote и не зависит
+// от формата файла (JSON Lines или protobuf) и от сжатия.
+type ArchiveManifest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	NoteCount     int64                  `protobuf:"varint,1,opt,name=note_count,json=noteCount,proto3" json:"note_count,omitempty"`
+	Sha256        string                 `protobuf:"bytes,2,opt,name=sha256,proto3" json:"sha256,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ArchiveManifest) Reset() {
+	*x = ArchiveManifest{}
+	mi := &file_note_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ArchiveManifest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ArchiveManifest) ProtoMessage() {}
+
+func (x *ArchiveManifest) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ArchiveManifest.ProtoReflect.Descriptor instead.
+func (*ArchiveManifest) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *ArchiveManifest) GetNoteCount() int64 {
+	if x != nil {
+		return x.NoteCount
+	}
+	return 0
+}
+
+func (x *ArchiveManifest) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+// Первая запись файла архива: версия формата и время выгрузки (Unix секунды).
+type ArchiveHeader struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FormatVersion int32                  `protobuf:"varint,1,opt,name=format_version,json=formatVersion,proto3" json:"format_version,omitempty"`
+	CreatedAt     int64                  `protobuf:"varint,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ArchiveHeader) Reset() {
+	*x = ArchiveHeader{}
+	mi := &file_note_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ArchiveHeader) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ArchiveHeader) ProtoMessage() {}
+
+func (x *ArchiveHeader) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ArchiveHeader.ProtoReflect.Descriptor instead.
+func (*ArchiveHeader) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *ArchiveHeader) GetFormatVersion() int32 {
+	if x != nil {
+		return x.FormatVersion
+	}
+	return 0
+}
+
+func (x *ArchiveHeader) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+// Запись файла архива: header, затем note на каждую заметку, последней — manifest.
+type ArchiveRecord struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Record:
+	//
+	//	*ArchiveRecord_Header
+	//	*ArchiveRecord_Note
+	//	*ArchiveRecord_Manifest
+	Record        isArchiveRecord_Record `protobuf_oneof:"record"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ArchiveRecord) Reset() {
+	*x = ArchiveRecord{}
+	mi := &file_note_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ArchiveRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ArchiveRecord) ProtoMessage() {}
+
+func (x *ArchiveRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ArchiveRecord.ProtoReflect.Descriptor instead.
+func (*ArchiveRecord) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *ArchiveRecord) GetRecord() isArchiveRecord_Record {
+	if x != nil {
+		return x.Record
+	}
+	return nil
+}
+
+func (x *ArchiveRecord) GetHeader() *ArchiveHeader {
+	if x != nil {
+		if x, ok := x.Record.(*ArchiveRecord_Header); ok {
+			return x.Header
+		}
+	}
+	return nil
+}
+
+func (x *ArchiveRecord) GetNote() *ExportedNote {
+	if x != nil {
+		if x, ok := x.Record.(*ArchiveRecord_Note); ok {
+			return x.Note
+		}
+	}
+	return nil
+}
+
+func (x *ArchiveRecord) GetManifest() *ArchiveManifest {
+	if x != nil {
+		if x, ok := x.Record.(*ArchiveRecord_Manifest); ok {
+			return x.Manifest
+		}
+	}
+	return nil
+}
+
+type isArchiveRecord_Record interface {
+	isArchiveRecord_Record()
+}
+
+type ArchiveRecord_Header struct {
+	Header *ArchiveHeader `protobuf:"bytes,1,opt,name=header,proto3,oneof"`
+}
+
+type ArchiveRecord_Note struct {
+	Note *ExportedNote `protobuf:"bytes,2,opt,name=note,proto3,oneof"`
+}
+
+type ArchiveRecord_Manifest struct {
+	Manifest *ArchiveManifest `protobuf:"bytes,3,opt,name=manifest,proto3,oneof"`
+}
+
+func (*ArchiveRecord_Header) isArchiveRecord_Record() {}
+
+func (*ArchiveRecord_Note) isArchiveRecord_Record() {}
+
+func (*ArchiveRecord_Manifest) isArchiveRecord_Record() {}
+
+// Запрос выгрузки заметок вызывающего. По умолчанию выгружается всё: и корзина, и история.
+type ExportNotesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SkipTrash     bool                   `protobuf:"varint,1,opt,name=skip_trash,json=skipTrash,proto3" json:"skip_trash,omitempty"`       // Не выгружать заметки из корзины.
+	SkipHistory   bool                   `protobuf:"varint,2,opt,name=skip_history,json=skipHistory,proto3" json:"skip_history,omitempty"` // Не выгружать историю версий (только последние версии).
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportNotesRequest) Reset() {
+	*x = ExportNotesRequest{}
+	mi := &file_note_proto_msgTypes[53]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportNotesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportNotesRequest) ProtoMessage() {}
+
+func (x *ExportNotesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[53]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportNotesRequest.ProtoReflect.Descriptor instead.
+func (*ExportNotesRequest) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{53}
+}
+
+func (x *ExportNotesRequest) GetSkipTrash() bool {
+	if x != nil {
+		return x.SkipTrash
+	}
+	return false
+}
+
+func (x *ExportNotesRequest) GetSkipHistory() bool {
+	if x != nil {
+		return x.SkipHistory
+	}
+	return false
+}
+
+// Сообщение потока ExportNotes: заметки, последним — manifest.
+// Поток без manifest в конце оборван и неполон.
+type ExportNotesResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Data:
+	//
+	//	*ExportNotesResponse_Note
+	//	*ExportNotesResponse_Manifest
+	Data          isExportNotesResponse_Data `protobuf_oneof:"data"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportNotesResponse) Reset() {
+	*x = ExportNotesResponse{}
+	mi := &file_note_proto_msgTypes[54]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportNotesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportNotesResponse) ProtoMessage() {}
+
+func (x *ExportNotesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[54]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportNotesResponse.ProtoReflect.Descriptor instead.
+func (*ExportNotesResponse) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{54}
+}
+
+func (x *ExportNotesResponse) GetData() isExportNotesResponse_Data {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *ExportNotesResponse) GetNote() *ExportedNote {
+	if x != nil {
+		if x, ok := x.Data.(*ExportNotesResponse_Note); ok {
+			return x.Note
+		}
+	}
+	return nil
+}
+
+func (x *ExportNotesResponse) GetManifest() *ArchiveManifest {
+	if x != nil {
+		if x, ok := x.Data.(*ExportNotesResponse_Manifest); ok {
+			return x.Manifest
+		}
+	}
+	return nil
+}
+
+type isExportNotesResponse_Data interface {
+	isExportNotesResponse_Data()
+}
+
+type ExportNotesResponse_Note struct {
+	Note *ExportedNote `protobuf:"bytes,1,opt,name=note,proto3,oneof"`
+}
+
+type ExportNotesResponse_Manifest struct {
+	Manifest *ArchiveManifest `protobuf:"bytes,2,opt,name=manifest,proto3,oneof"`
+}
+
+func (*ExportNotesResponse_Note) isExportNotesResponse_Data() {}
+
+func (*ExportNotesResponse_Manifest) isExportNotesResponse_Data() {}
+
+type ImportOptions struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Mode          ImportMode             `protobuf:"varint,1,opt,name=mode,proto3,enum=note.v1.ImportMode" json:"mode,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportOptions) Reset() {
+	*x = ImportOptions{}
+	mi := &file_note_proto_msgTypes[55]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportOptions) ProtoMessage() {}
+
+func (x *ImportOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[55]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportOptions.ProtoReflect.Descriptor instead.
+func (*ImportOptions) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{55}
+}
+
+func (x *ImportOptions) GetMode() ImportMode {
+	if x != nil {
+		return x.Mode
+	}
+	return ImportMode_IMPORT_MODE_UNSPECIFIED
+}
+
+// Сообщение потока ImportNotes: options (необязательно, только первым), затем заметки.
+type ImportNotesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Data:
+	//
+	//	*ImportNotesRequest_Options
+	//	*ImportNotesRequest_Note
+	Data          isImportNotesRequest_Data `protobuf_oneof:"data"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportNotesRequest) Reset() {
+	*x = ImportNotesRequest{}
+	mi := &file_note_proto_msgTypes[56]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportNotesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportNotesRequest) ProtoMessage() {}
+
+func (x *ImportNotesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[56]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportNotesRequest.ProtoReflect.Descriptor instead.
+func (*ImportNotesRequest) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{56}
+}
+
+func (x *ImportNotesRequest) GetData() isImportNotesRequest_Data {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *ImportNotesRequest) GetOptions() *ImportOptions {
+	if x != nil {
+		if x, ok := x.Data.(*ImportNotesRequest_Options); ok {
+			return x.Options
+		}
+	}
+	return nil
+}
+
+func (x *ImportNotesRequest) GetNote() *ExportedNote {
+	if x != nil {
+		if x, ok := x.Data.(*ImportNotesRequest_Note); ok {
+			return x.Note
+		}
+	}
+	return nil
+}
+
+type isImportNotesRequest_Data interface {
+	isImportNotesRequest_Data()
+}
+
+type ImportNotesRequest_Options struct {
+	Options *ImportOptions `protobuf:"bytes,1,opt,name=options,proto3,oneof"`
+}
+
+type ImportNotesRequest_Note struct {
+	Note *ExportedNote `protobuf:"bytes,2,opt,name=note,proto3,oneof"`
+}
+
+func (*ImportNotesRequest_Options) isImportNotesRequest_Data() {}
+
+func (*ImportNotesRequest_Note) isImportNotesRequest_Data() {}
+
+// Заметка архива, которая не импортирована (ATTACHMENT_DROPPED — импортирована без части вложений).
+type ImportIssue struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Index         int32                  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"` // Порядковый номер заметки в потоке (с нуля).
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`   // ID_CONFLICT, OWNED_BY_ANOTHER_USER, DUPLICATE_ID, INVALID_NOTE, VERSION_CONFLICT, ATTACHMENT_DROPPED.
+	Message       string                 `protobuf:"bytes,4,opt,name=message,proto3" json:"message,omitempty"` // Человекочитаемое описание.
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportIssue) Reset() {
+	*x = ImportIssue{}
+	mi := &file_note_proto_msgTypes[57]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportIssue) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportIssue) ProtoMessage() {}
+
+func (x *ImportIssue) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[57]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportIssue.ProtoReflect.Descriptor instead.
+func (*ImportIssue) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{57}
+}
+
+func (x *ImportIssue) GetIndex() int32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *ImportIssue) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ImportIssue) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *ImportIssue) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// Итог импорта.
+type ImportNotesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Received      int32                  `protobuf:"varint,1,opt,name=received,proto3" json:"received,omitempty"`   // Сколько заметок прислал клиент.
+	Imported      int32                  `protobuf:"varint,2,opt,name=imported,proto3" json:"imported,omitempty"`   // Сколько записано (новых и перезаписанных).
+	Unchanged     int32                  `protobuf:"varint,3,opt,name=unchanged,proto3" json:"unchanged,omitempty"` // Уже лежали в хранилище в той же версии.
+	Deleted       int32                  `protobuf:"varint,4,opt,name=deleted,proto3" json:"deleted,omitempty"`     // REPLACE: удалено заметок, которых нет в архиве.
+	Issues        []*ImportIssue         `protobuf:"bytes,5,rep,name=issues,proto3" json:"issues,omitempty"`        // Не импортированные (или импортированные не целиком) заметки и причины.
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportNotesResponse) Reset() {
+	*x = ImportNotesResponse{}
+	mi := &file_note_proto_msgTypes[58]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportNotesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportNotesResponse) ProtoMessage() {}
+
+func (x *ImportNotesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[58]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportNotesResponse.ProtoReflect.Descriptor instead.
+func (*ImportNotesResponse) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{58}
+}
+
+func (x *ImportNotesResponse) GetReceived() int32 {
+	if x != nil {
+		return x.Received
+	}
+	return 0
+}
+
+func (x *ImportNotesResponse) GetImported() int32 {
+	if x != nil {
+		return x.Imported
+	}
+	return 0
+}
+
+func (x *ImportNotesResponse) GetUnchanged() int32 {
+	if x != nil {
+		return x.Unchanged
+	}
+	return 0
+}
+
+func (x *ImportNotesResponse) GetDeleted() int32 {
+	if x != nil {
+		return x.Deleted
+	}
+	return 0
+}
+
+func (x *ImportNotesResponse) GetIssues() []*ImportIssue {
+	if x != nil {
+		return x.Issues
+	}
+	return nil
+}
+
+// Запрос на подписку на изменения.
+type WatchNotesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// 0 — только новые события.
+	// N > 0 — сначала повторить сохранённые сервером события начиная с seq = N
+	// (так клиент продолжает поток после переподключения: last_seq + 1).
+	FromSeq       uint64 `protobuf:"varint,1,opt,name=from_seq,json=fromSeq,proto3" json:"from_seq,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchNotesRequest) Reset() {
+	*x = WatchNotesRequest{}
+	mi := &file_note_proto_msgTypes[59]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchNotesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchNotesRequest) ProtoMessage() {}
+
+func (x *WatchNotesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[59]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchNotesRequest.ProtoReflect.Descriptor instead.
+func (*WatchNotesRequest) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{59}
+}
+
+func (x *WatchNotesRequest) GetFromSeq() uint64 {
+	if x != nil {
+		return x.FromSeq
+	}
+	return 0
+}
+
+// Событие в потоке WatchNotes.
+type NoteEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Seq           uint64                 `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`                                 // Порядковый номер события (растёт монотонно).
+	Type          NoteEventType          `protobuf:"varint,2,opt,name=type,proto3,enum=note.v1.NoteEventType" json:"type,omitempty"`    // Что произошло.
+	Note          *Note                  `protobuf:"bytes,3,opt,name=note,proto3" json:"note,omitempty"`                                // Заметка после изменения (для DELETED — последнее состояние).
+	OccurredAt    int64                  `protobuf:"varint,4,opt,name=occurred_at,json=occurredAt,proto3" json:"occurred_at,omitempty"` // Время события в Unix секундах.
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NoteEvent) Reset() {
+	*x = NoteEvent{}
+	mi := &file_note_proto_msgTypes[60]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NoteEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NoteEvent) ProtoMessage() {}
+
+func (x *NoteEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_note_proto_msgTypes[60]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NoteEvent.ProtoReflect.Descriptor instead.
+func (*NoteEvent) Descriptor() ([]byte, []int) {
+	return file_note_proto_rawDescGZIP(), []int{60}
+}
+
+func (x *NoteEvent) GetSeq() uint64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *NoteEvent) GetType() NoteEventType {
+	if x != nil {
+		return x.Type
+	}
+	return NoteEventType_NOTE_EVENT_TYPE_UNSPECIFIED
+}
+
+func (x *NoteEvent) GetNote() *Note {
+	if x != nil {
+		return x.Note
 	}
 	return nil
 }
 
+func (x *NoteEvent) GetOccurredAt() int64 {
+	if x != nil {
+		return x.OccurredAt
+	}
+	return 0
+}
+
 var File_note_proto protoreflect.FileDescriptor
 
 const file_note_proto_rawDesc = "" +
 	"\n" +
 	"\n" +
-	"note.proto\x12\anote.v1\"e\n" +
+	"note.proto\x12\anote.v1\x1a google/protobuf/field_mask.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xa3\x02\n" +
 	"\x04Note\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
 	"\x05title\x18\x02 \x01(\tR\x05title\x12\x18\n" +
 	"\acontent\x18\x03 \x01(\tR\acontent\x12\x1d\n" +
 	"\n" +
-	"created_at\x18\x04 \x01(\x03R\tcreatedAt\"C\n" +
+	"created_at\x18\x04 \x01(\x03R\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\x05 \x01(\x03R\tupdatedAt\x12\x19\n" +
+	"\bowner_id\x18\x06 \x01(\tR\aownerId\x12\x18\n" +
+	"\aversion\x18\a \x01(\x03R\aversion\x12\x1d\n" +
+	"\n" +
+	"deleted_at\x18\b \x01(\x03R\tdeletedAt\x12\x12\n" +
+	"\x04tags\x18\t \x03(\tR\x04tags\x125\n" +
+	"\vattachments\x18\n" +
+	" \x03(\v2\x13.note.v1.AttachmentR\vattachments\"\x9e\x01\n" +
+	"\n" +
+	"Attachment\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12!\n" +
+	"\fcontent_type\x18\x03 \x01(\tR\vcontentType\x12\x12\n" +
+	"\x04size\x18\x04 \x01(\x03R\x04size\x12\x16\n" +
+	"\x06sha256\x18\x05 \x01(\tR\x06sha256\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\x03R\tcreatedAt\"\x80\x01\n" +
 	"\x11CreateNoteRequest\x12\x14\n" +
 	"\x05title\x18\x01 \x01(\tR\x05title\x12\x18\n" +
-	"\acontent\x18\x02 \x01(\tR\acontent\"7\n" +
+	"\acontent\x18\x02 \x01(\tR\acontent\x12'\n" +
+	"\x0fidempotency_key\x18\x03 \x01(\tR\x0eidempotencyKey\x12\x12\n" +
+	"\x04tags\x18\x04 \x03(\tR\x04tags\"7\n" +
 	"\x12CreateNoteResponse\x12!\n" +
 	"\x04note\x18\x01 \x01(\v2\r.note.v1.NoteR\x04note\" \n" +
 	"\x0eGetNoteRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\"4\n" +
 	"\x0fGetNoteResponse\x12!\n" +
-	"\x04note\x18\x01 \x01(\v2\r.note.v1.NoteR\x04note\"\x12\n" +
-	"\x10ListNotesRequest\"8\n" +
+	"\x04note\x18\x01 \x01(\v2\r.note.v1.NoteR\x04note\"\xbb\x01\n" +
+	"\x11UpdateNoteRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12\x18\n" +
+	"\acontent\x18\x03 \x01(\tR\acontent\x12;\n" +
+	"\vupdate_mask\x18\x04 \x01(\v2\x1a.google.protobuf.FieldMaskR\n" +
+	"updateMask\x12)\n" +
+	"\x10expected_version\x18\x05 \x01(\x03R\x0fexpectedVersion\"7\n" +
+	"\x12UpdateNoteResponse\x12!\n" +
+	"\x04note\x18\x01 \x01(\v2\r.note.v1.NoteR\x04note\"N\n" +
+	"\x11DeleteNoteRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12)\n" +
+	"\x10expected_version\x18\x02 \x01(\x03R\x0fexpectedVersion\"\x14\n" +
+	"\x12DeleteNoteResponse\"\x8c\x01\n" +
+	"\x10ListTrashRequest\x12\x1b\n" +
+	"\tpage_size\x18\x01 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x02 \x01(\tR\tpageToken\x12\x19\n" +
+	"\border_by\x18\x03 \x01(\tR\aorderBy\x12!\n" +
+	"\ftitle_prefix\x18\x04 \x01(\tR\vtitlePrefix\"`\n" +
+	"\x11ListTrashResponse\x12#\n" +
+	"\x05notes\x18\x01 \x03(\v2\r.note.v1.NoteR\x05notes\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"O\n" +
+	"\x12RestoreNoteRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12)\n" +
+	"\x10expected_version\x18\x02 \x01(\x03R\x0fexpectedVersion\"8\n" +
+	"\x13RestoreNoteResponse\x12!\n" +
+	"\x04note\x18\x01 \x01(\v2\r.note.v1.NoteR\x04note\"M\n" +
+	"\x10PurgeNoteRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12)\n" +
+	"\x10expected_version\x18\x02 \x01(\x03R\x0fexpectedVersion\"\x13\n" +
+	"\x11PurgeNoteResponse\"\xd0\x01\n" +
+	"\x10ListNotesRequest\x12\x1b\n" +
+	"\tpage_size\x18\x01 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x02 \x01(\tR\tpageToken\x12\x19\n" +
+	"\border_by\x18\x03 \x01(\tR\aorderBy\x12!\n" +
+	"\ftitle_prefix\x18\x04 \x01(\tR\vtitlePrefix\x12\x12\n" +
+	"\x04tags\x18\x05 \x03(\tR\x04tags\x12.\n" +
+	"\ttag_match\x18\x06 \x01(\x0e2\x11.note.v1.TagMatchR\btagMatch\"`\n" +
 	"\x11ListNotesResponse\x12#\n" +
-	"\x05notes\x18\x01 \x03(\v2\r.note.v1.NoteR\x05notes2\xd6\x01\n" +
+	"\x05notes\x18\x01 \x03(\v2\r.note.v1.NoteR\x05notes\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"_\n" +
+	"\x0eAddTagsRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04tags\x18\x02 \x03(\tR\x04tags\x12)\n" +
+	"\x10expected_version\x18\x03 \x01(\x03R\x0fexpectedVersion\"4\n" +
+	"\x0fAddTagsResponse\x12!\n" +
+	"\x04note\x18\x01 \x01(\v2\r.note.v1.NoteR\x04note\"b\n" +
+	"\x11RemoveTagsRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04tags\x18\x02 \x03(\tR\x04tags\x12)\n" +
+	"\x10expected_version\x18\x03 \x01(\x03R\x0fexpectedVersion\"7\n" +
+	"\x12RemoveTagsResponse\x12!\n" +
+	"\x04note\x18\x01 \x01(\v2\r.note.v1.NoteR\x04note\"\x11\n" +
+	"\x0fListTagsRequest\"2\n" +
+	"\bTagCount\x12\x10\n" +
+	"\x03tag\x18\x01 \x01(\tR\x03tag\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x05R\x05count\"9\n" +
+	"\x10ListTagsResponse\x12%\n" +
+	"\x04tags\x18\x01 \x03(\v2\x11.note.v1.TagCountR\x04tags\"\xa5\x01\n" +
+	"\x10AttachmentUpload\x12\x17\n" +
+	"\anote_id\x18\x01 \x01(\tR\x06noteId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12!\n" +
+	"\fcontent_type\x18\x03 \x01(\tR\vcontentType\x12\x16\n" +
+	"\x06sha256\x18\x04 \x01(\tR\x06sha256\x12)\n" +
+	"\x10expected_version\x18\x05 \x01(\x03R\x0fexpectedVersion\"r\n" +
+	"\x17UploadAttachmentRequest\x127\n" +
+	"\bmetadata\x18\x01 \x01(\v2\x19.note.v1.AttachmentUploadH\x00R\bmetadata\x12\x16\n" +
+	"\x05chunk\x18\x02 \x01(\fH\x00R\x05chunkB\x06\n" +
+	"\x04data\"r\n" +
+	"\x18UploadAttachmentResponse\x12!\n" +
+	"\x04note\x18\x01 \x01(\v2\r.note.v1.NoteR\x04note\x123\n" +
+	"\n" +
+	"attachment\x18\x02 \x01(\v2\x13.note.v1.AttachmentR\n" +
+	"attachment\"Y\n" +
+	"\x19DownloadAttachmentRequest\x12\x17\n" +
+	"\anote_id\x18\x01 \x01(\tR\x06noteId\x12#\n" +
+	"\rattachment_id\x18\x02 \x01(\tR\fattachmentId\"s\n" +
+	"\x1aDownloadAttachmentResponse\x125\n" +
+	"\n" +
+	"attachment\x18\x01 \x01(\v2\x13.note.v1.AttachmentH\x00R\n" +
+	"attachment\x12\x16\n" +
+	"\x05chunk\x18\x02 \x01(\fH\x00R\x05chunkB\x06\n" +
+	"\x04data\"f\n" +
+	"\x18ListNoteRevisionsRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x03 \x01(\tR\tpageToken\"p\n" +
+	"\x19ListNoteRevisionsResponse\x12+\n" +
+	"\trevisions\x18\x01 \x03(\v2\r.note.v1.NoteR\trevisions\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"B\n" +
+	"\x16GetNoteRevisionRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\x03R\aversion\"D\n" +
+	"\x17GetNoteRevisionResponse\x12)\n" +
+	"\brevision\x18\x01 \x01(\v2\r.note.v1.NoteR\brevision\"l\n" +
+	"\x18DiffNoteRevisionsRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12!\n" +
+	"\ffrom_version\x18\x02 \x01(\x03R\vfromVersion\x12\x1d\n" +
+	"\n" +
+	"to_version\x18\x03 \x01(\x03R\ttoVersion\"q\n" +
+	"\x19DiffNoteRevisionsResponse\x12\x12\n" +
+	"\x04diff\x18\x01 \x01(\tR\x04diff\x12!\n" +
+	"\ffrom_version\x18\x02 \x01(\x03R\vfromVersion\x12\x1d\n" +
+	"\n" +
+	"to_version\x18\x03 \x01(\x03R\ttoVersion\"h\n" +
+	"\x11RevertNoteRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\x03R\aversion\x12)\n" +
+	"\x10expected_version\x18\x03 \x01(\x03R\x0fexpectedVersion\"7\n" +
+	"\x12RevertNoteResponse\x12!\n" +
+	"\x04note\x18\x01 \x01(\v2\r.note.v1.NoteR\x04note\"W\n" +
+	"\x11BulkCreateFailure\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x05R\x05index\x12\x14\n" +
+	"\x05field\x18\x02 \x01(\tR\x05field\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\"\x8e\x01\n" +
+	"\x17BulkCreateNotesResponse\x12\x1a\n" +
+	"\breceived\x18\x01 \x01(\x05R\breceived\x12\x1f\n" +
+	"\vcreated_ids\x18\x02 \x03(\tR\n" +
+	"createdIds\x126\n" +
+	"\bfailures\x18\x03 \x03(\v2\x1a.note.v1.BulkCreateFailureR\bfailures\"@\n" +
+	"\x12SearchNotesRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\"`\n" +
+	"\tSearchHit\x12!\n" +
+	"\x04note\x18\x01 \x01(\v2\r.note.v1.NoteR\x04note\x12\x14\n" +
+	"\x05score\x18\x02 \x01(\x01R\x05score\x12\x1a\n" +
+	"\bsnippets\x18\x03 \x03(\tR\bsnippets\"=\n" +
+	"\x13SearchNotesResponse\x12&\n" +
+	"\x04hits\x18\x01 \x03(\v2\x12.note.v1.SearchHitR\x04hits\"=\n" +
+	"\x11RenderNoteRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\x03R\aversion\"G\n" +
+	"\vNoteHeading\x12\x14\n" +
+	"\x05level\x18\x01 \x01(\x05R\x05level\x12\x12\n" +
+	"\x04text\x18\x02 \x01(\tR\x04text\x12\x0e\n" +
+	"\x02id\x18\x03 \x01(\tR\x02id\"0\n" +
+	"\bNoteLink\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12\x12\n" +
+	"\x04text\x18\x02 \x01(\tR\x04text\"\xe3\x01\n" +
+	"\x12RenderNoteResponse\x12\x12\n" +
+	"\x04html\x18\x01 \x01(\tR\x04html\x12&\n" +
+	"\x03toc\x18\x02 \x03(\v2\x14.note.v1.NoteHeadingR\x03toc\x12\x19\n" +
+	"\btoc_html\x18\x03 \x01(\tR\atocHtml\x12'\n" +
+	"\x05links\x18\x04 \x03(\v2\x11.note.v1.NoteLinkR\x05links\x12\x1d\n" +
+	"\n" +
+	"word_count\x18\x05 \x01(\x05R\twordCount\x12\x18\n" +
+	"\aversion\x18\x06 \x01(\x03R\aversion\x12\x14\n" +
+	"\x05title\x18\a \x01(\tR\x05title\"\x8e\x03\n" +
+	"\x13ArchivedNoteVersion\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12\x18\n" +
+	"\acontent\x18\x03 \x01(\tR\acontent\x129\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12\x19\n" +
+	"\bowner_id\x18\x06 \x01(\tR\aownerId\x12\x18\n" +
+	"\aversion\x18\a \x01(\x03R\aversion\x129\n" +
+	"\n" +
+	"deleted_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tdeletedAt\x12\x12\n" +
+	"\x04tags\x18\t \x03(\tR\x04tags\x12=\n" +
+	"\vattachments\x18\n" +
+	" \x03(\v2\x1b.note.v1.ArchivedAttachmentR\vattachments\"\xc2\x01\n" +
+	"\x12ArchivedAttachment\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12!\n" +
+	"\fcontent_type\x18\x03 \x01(\tR\vcontentType\x12\x12\n" +
+	"\x04size\x18\x04 \x01(\x03R\x04size\x12\x16\n" +
+	"\x06sha256\x18\x05 \x01(\tR\x06sha256\x129\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"|\n" +
+	"\fExportedNote\x120\n" +
+	"\x04note\x18\x01 \x01(\v2\x1c.note.v1.ArchivedNoteVersionR\x04note\x12:\n" +
+	"\trevisions\x18\x02 \x03(\v2\x1c.note.v1.ArchivedNoteVersionR\trevisions\"H\n" +
+	"\x0fArchiveManifest\x12\x1d\n" +
+	"\n" +
+	"note_count\x18\x01 \x01(\x03R\tnoteCount\x12\x16\n" +
+	"\x06sha256\x18\x02 \x01(\tR\x06sha256\"U\n" +
+	"\rArchiveHeader\x12%\n" +
+	"\x0eformat_version\x18\x01 \x01(\x05R\rformatVersion\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x02 \x01(\x03R\tcreatedAt\"\xb0\x01\n" +
+	"\rArchiveRecord\x120\n" +
+	"\x06header\x18\x01 \x01(\v2\x16.note.v1.ArchiveHeaderH\x00R\x06header\x12+\n" +
+	"\x04note\x18\x02 \x01(\v2\x15.note.v1.ExportedNoteH\x00R\x04note\x126\n" +
+	"\bmanifest\x18\x03 \x01(\v2\x18.note.v1.ArchiveManifestH\x00R\bmanifestB\b\n" +
+	"\x06record\"V\n" +
+	"\x12ExportNotesRequest\x12\x1d\n" +
+	"\n" +
+	"skip_trash\x18\x01 \x01(\bR\tskipTrash\x12!\n" +
+	"\fskip_history\x18\x02 \x01(\bR\vskipHistory\"\x82\x01\n" +
+	"\x13ExportNotesResponse\x12+\n" +
+	"\x04note\x18\x01 \x01(\v2\x15.note.v1.ExportedNoteH\x00R\x04note\x126\n" +
+	"\bmanifest\x18\x02 \x01(\v2\x18.note.v1.ArchiveManifestH\x00R\bmanifestB\x06\n" +
+	"\x04data\"8\n" +
+	"\rImportOptions\x12'\n" +
+	"\x04mode\x18\x01 \x01(\x0e2\x13.note.v1.ImportModeR\x04mode\"}\n" +
+	"\x12ImportNotesRequest\x122\n" +
+	"\aoptions\x18\x01 \x01(\v2\x16.note.v1.ImportOptionsH\x00R\aoptions\x12+\n" +
+	"\x04note\x18\x02 \x01(\v2\x15.note.v1.ExportedNoteH\x00R\x04noteB\x06\n" +
+	"\x04data\"e\n" +
+	"\vImportIssue\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x05R\x05index\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12\x18\n" +
+	"\amessage\x18\x04 \x01(\tR\amessage\"\xb3\x01\n" +
+	"\x13ImportNotesResponse\x12\x1a\n" +
+	"\breceived\x18\x01 \x01(\x05R\breceived\x12\x1a\n" +
+	"\bimported\x18\x02 \x01(\x05R\bimported\x12\x1c\n" +
+	"\tunchanged\x18\x03 \x01(\x05R\tunchanged\x12\x18\n" +
+	"\adeleted\x18\x04 \x01(\x05R\adeleted\x12,\n" +
+	"\x06issues\x18\x05 \x03(\v2\x14.note.v1.ImportIssueR\x06issues\".\n" +
+	"\x11WatchNotesRequest\x12\x19\n" +
+	"\bfrom_seq\x18\x01 \x01(\x04R\afromSeq\"\x8d\x01\n" +
+	"\tNoteEvent\x12\x10\n" +
+	"\x03seq\x18\x01 \x01(\x04R\x03seq\x12*\n" +
+	"\x04type\x18\x02 \x01(\x0e2\x16.note.v1.NoteEventTypeR\x04type\x12!\n" +
+	"\x04note\x18\x03 \x01(\v2\r.note.v1.NoteR\x04note\x12\x1f\n" +
+	"\voccurred_at\x18\x04 \x01(\x03R\n" +
+	"occurredAt*K\n" +
+	"\bTagMatch\x12\x19\n" +
+	"\x15TAG_MATCH_UNSPECIFIED\x10\x00\x12\x11\n" +
+	"\rTAG_MATCH_ANY\x10\x01\x12\x11\n" +
+	"\rTAG_MATCH_ALL\x10\x02*Y\n" +
+	"\n" +
+	"ImportMode\x12\x1b\n" +
+	"\x17IMPORT_MODE_UNSPECIFIED\x10\x00\x12\x15\n" +
+	"\x11IMPORT_MODE_MERGE\x10\x01\x12\x17\n" +
+	"\x13IMPORT_MODE_REPLACE\x10\x02*\xc1\x01\n" +
+	"\rNoteEventType\x12\x1f\n" +
+	"\x1bNOTE_EVENT_TYPE_UNSPECIFIED\x10\x00\x12\x1b\n" +
+	"\x17NOTE_EVENT_TYPE_CREATED\x10\x01\x12\x1b\n" +
+	"\x17NOTE_EVENT_TYPE_UPDATED\x10\x02\x12\x1b\n" +
+	"\x17NOTE_EVENT_TYPE_DELETED\x10\x03\x12\x1c\n" +
+	"\x18NOTE_EVENT_TYPE_RESTORED\x10\x04\x12\x1a\n" +
+	"\x16NOTE_EVENT_TYPE_PURGED\x10\x052\xc9\r\n" +
 	"\vNoteService\x12E\n" +
 	"\n" +
 	"CreateNote\x12\x1a.note.v1.CreateNoteRequest\x1a\x1b.note.v1.CreateNoteResponse\x12<\n" +
 	"\aGetNote\x12\x17.note.v1.GetNoteRequest\x1a\x18.note.v1.GetNoteResponse\x12B\n" +
-	"\tListNotes\x12\x19.note.v1.ListNotesRequest\x1a\x1a.note.v1.ListNotesResponseB3Z1github.com/verazalayli/go_studying/grpc/pkg/pb;pbb\x06proto3"
+	"\tListNotes\x12\x19.note.v1.ListNotesRequest\x1a\x1a.note.v1.ListNotesResponse\x12E\n" +
+	"\n" +
+	"UpdateNote\x12\x1a.note.v1.UpdateNoteRequest\x1a\x1b.note.v1.UpdateNoteResponse\x12E\n" +
+	"\n" +
+	"DeleteNote\x12\x1a.note.v1.DeleteNoteRequest\x1a\x1b.note.v1.DeleteNoteResponse\x12B\n" +
+	"\tListTrash\x12\x19.note.v1.ListTrashRequest\x1a\x1a.note.v1.ListTrashResponse\x12H\n" +
+	"\vRestoreNote\x12\x1b.note.v1.RestoreNoteRequest\x1a\x1c.note.v1.RestoreNoteResponse\x12B\n" +
+	"\tPurgeNote\x12\x19.note.v1.PurgeNoteRequest\x1a\x1a.note.v1.PurgeNoteResponse\x12<\n" +
+	"\aAddTags\x12\x17.note.v1.AddTagsRequest\x1a\x18.note.v1.AddTagsResponse\x12E\n" +
+	"\n" +
+	"RemoveTags\x12\x1a.note.v1.RemoveTagsRequest\x1a\x1b.note.v1.RemoveTagsResponse\x12?\n" +
+	"\bListTags\x12\x18.note.v1.ListTagsRequest\x1a\x19.note.v1.ListTagsResponse\x12Z\n" +
+	"\x11ListNoteRevisions\x12!.note.v1.ListNoteRevisionsRequest\x1a\".note.v1.ListNoteRevisionsResponse\x12T\n" +
+	"\x0fGetNoteRevision\x12\x1f.note.v1.GetNoteRevisionRequest\x1a .note.v1.GetNoteRevisionResponse\x12Z\n" +
+	"\x11DiffNoteRevisions\x12!.note.v1.DiffNoteRevisionsRequest\x1a\".note.v1.DiffNoteRevisionsResponse\x12E\n" +
+	"\n" +
+	"RevertNote\x12\x1a.note.v1.RevertNoteRequest\x1a\x1b.note.v1.RevertNoteResponse\x12>\n" +
+	"\n" +
+	"WatchNotes\x12\x1a.note.v1.WatchNotesRequest\x1a\x12.note.v1.NoteEvent0\x01\x12Q\n" +
+	"\x0fBulkCreateNotes\x12\x1a.note.v1.CreateNoteRequest\x1a .note.v1.BulkCreateNotesResponse(\x01\x12H\n" +
+	"\vSearchNotes\x12\x1b.note.v1.SearchNotesRequest\x1a\x1c.note.v1.SearchNotesResponse\x12E\n" +
+	"\n" +
+	"RenderNote\x12\x1a.note.v1.RenderNoteRequest\x1a\x1b.note.v1.RenderNoteResponse\x12J\n" +
+	"\vExportNotes\x12\x1b.note.v1.ExportNotesRequest\x1a\x1c.note.v1.ExportNotesResponse0\x01\x12J\n" +
+	"\vImportNotes\x12\x1b.note.v1.ImportNotesRequest\x1a\x1c.note.v1.ImportNotesResponse(\x01\x12Y\n" +
+	"\x10UploadAttachment\x12 .note.v1.UploadAttachmentRequest\x1a!.note.v1.UploadAttachmentResponse(\x01\x12_\n" +
+	"\x12DownloadAttachment\x12\".note.v1.DownloadAttachmentRequest\x1a#.note.v1.DownloadAttachmentResponse0\x01B3Z1github.com/verazalayli/go_studying/grpc/pkg/pb;pbb\x06proto3"
 
 var (
 	file_note_proto_rawDescOnce sync.Once
@@ -395,31 +4263,170 @@ func file_note_proto_rawDescGZIP() []byte {
 	return file_note_proto_rawDescData
 }
 
-var file_note_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_note_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_note_proto_msgTypes = make([]protoimpl.MessageInfo, 61)
 var file_note_proto_goTypes = []any{
-	(*Note)(nil),               // 0: note.v1.Note
-	(*CreateNoteRequest)(nil),  // 1: note.v1.CreateNoteRequest
-	(*CreateNoteResponse)(nil), // 2: note.v1.CreateNoteResponse
-	(*GetNoteRequest)(nil),     // 3: note.v1.GetNoteRequest
-	(*GetNoteResponse)(nil),    // 4: note.v1.GetNoteResponse
-	(*ListNotesRequest)(nil),   // 5: note.v1.ListNotesRequest
-	(*ListNotesResponse)(nil),  // 6: note.v1.ListNotesResponse
+	(TagMatch)(0),                      // 0: note.v1.TagMatch
+	(ImportMode)(0),                    // 1: note.v1.ImportMode
+	(NoteEventType)(0),                 // 2: note.v1.NoteEventType
+	(*Note)(nil),                       // 3: note.v1.Note
+	(*Attachment)(nil),                 // 4: note.v1.Attachment
+	(*CreateNoteRequest)(nil),          // 5: note.v1.CreateNoteRequest
+	(*CreateNoteResponse)(nil),         // 6: note.v1.CreateNoteResponse
+	(*GetNoteRequest)(nil),             // 7: note.v1.GetNoteRequest
+	(*GetNoteResponse)(nil),            // 8: note.v1.GetNoteResponse
+	(*UpdateNoteRequest)(nil),          // 9: note.v1.UpdateNoteRequest
+	(*UpdateNoteResponse)(nil),         // 10: note.v1.UpdateNoteResponse
+	(*DeleteNoteRequest)(nil),          // 11: note.v1.DeleteNoteRequest
+	(*DeleteNoteResponse)(nil),         // 12: note.v1.DeleteNoteResponse
+	(*ListTrashRequest)(nil),           // 13: note.v1.ListTrashRequest
+	(*ListTrashResponse)(nil),          // 14: note.v1.ListTrashResponse
+	(*RestoreNoteRequest)(nil),         // 15: note.v1.RestoreNoteRequest
+	(*RestoreNoteResponse)(nil),        // 16: note.v1.RestoreNoteResponse
+	(*PurgeNoteRequest)(nil),           // 17: note.v1.PurgeNoteRequest
+	(*PurgeNoteResponse)(nil),          // 18: note.v1.PurgeNoteResponse
+	(*ListNotesRequest)(nil),           // 19: note.v1.ListNotesRequest
+	(*ListNotesResponse)(nil),          // 20: note.v1.ListNotesResponse
+	(*AddTagsRequest)(nil),             // 21: note.v1.AddTagsRequest
+	(*AddTagsResponse)(nil),            // 22: note.v1.AddTagsResponse
+	(*RemoveTagsRequest)(nil),          // 23: note.v1.RemoveTagsRequest
+	(*RemoveTagsResponse)(nil),         // 24: note.v1.RemoveTagsResponse
+	(*ListTagsRequest)(nil),            // 25: note.v1.ListTagsRequest
+	(*TagCount)(nil),                   // 26: note.v1.TagCount
+	(*ListTagsResponse)(nil),           // 27: note.v1.ListTagsResponse
+	(*AttachmentUpload)(nil),           // 28: note.v1.AttachmentUpload
+	(*UploadAttachmentRequest)(nil),    // 29: note.v1.UploadAttachmentRequest
+	(*UploadAttachmentResponse)(nil),   // 30: note.v1.UploadAttachmentResponse
+	(*DownloadAttachmentRequest)(nil),  // 31: note.v1.DownloadAttachmentRequest
+	(*DownloadAttachmentResponse)(nil), // 32: note.v1.DownloadAttachmentResponse
+	(*ListNoteRevisionsRequest)(nil),   // 33: note.v1.ListNoteRevisionsRequest
+	(*ListNoteRevisionsResponse)(nil),  // 34: note.v1.ListNoteRevisionsResponse
+	(*GetNoteRevisionRequest)(nil),     // 35: note.v1.GetNoteRevisionRequest
+	(*GetNoteRevisionResponse)(nil),    // 36: note.v1.GetNoteRevisionResponse
+	(*DiffNoteRevisionsRequest)(nil),   // 37: note.v1.DiffNoteRevisionsRequest
+	(*DiffNoteRevisionsResponse)(nil),  // 38: note.v1.DiffNoteRevisionsResponse
+	(*RevertNoteRequest)(nil),          // 39: note.v1.RevertNoteRequest
+	(*RevertNoteResponse)(nil),         // 40: note.v1.RevertNoteResponse
+	(*BulkCreateFailure)(nil),          // 41: note.v1.BulkCreateFailure
+	(*BulkCreateNotesResponse)(nil),    // 42: note.v1.BulkCreateNotesResponse
+	(*SearchNotesRequest)(nil),         // 43: note.v1.SearchNotesRequest
+	(*SearchHit)(nil),                  // 44: note.v1.SearchHit
+	(*SearchNotesResponse)(nil),        // 45: note.v1.SearchNotesResponse
+	(*RenderNoteRequest)(nil),          // 46: note.v1.RenderNoteRequest
+	(*NoteHeading)(nil),                // 47: note.v1.NoteHeading
+	(*NoteLink)(nil),                   // 48: note.v1.NoteLink
+	(*RenderNoteResponse)(nil),         // 49: note.v1.RenderNoteResponse
+	(*ArchivedNoteVersion)(nil),        // 50: note.v1.ArchivedNoteVersion
+	(*ArchivedAttachment)(nil),         // 51: note.v1.ArchivedAttachment
+	(*ExportedNote)(nil),               // 52: note.v1.ExportedNote
+	(*ArchiveManifest)(nil),            // 53: note.v1.ArchiveManifest
+	(*ArchiveHeader)(nil),              // 54: note.v1.ArchiveHeader
+	(*ArchiveRecord)(nil),              // 55: note.v1.ArchiveRecord
+	(*ExportNotesRequest)(nil),         // 56: note.v1.ExportNotesRequest
+	(*ExportNotesResponse)(nil),        // 57: note.v1.ExportNotesResponse
+	(*ImportOptions)(nil),              // 58: note.v1.ImportOptions
+	(*ImportNotesRequest)(nil),         // 59: note.v1.ImportNotesRequest
+	(*ImportIssue)(nil),                // 60: note.v1.ImportIssue
+	(*ImportNotesResponse)(nil),        // 61: note.v1.ImportNotesResponse
+	(*WatchNotesRequest)(nil),          // 62: note.v1.WatchNotesRequest
+	(*NoteEvent)(nil),                  // 63: note.v1.NoteEvent
+	(*fieldmaskpb.FieldMask)(nil),      // 64: google.protobuf.FieldMask
+	(*timestamppb.Timestamp)(nil),      // 65: google.protobuf.Timestamp
 }
 var file_note_proto_depIdxs = []int32{
-	0, // 0: note.v1.CreateNoteResponse.note:type_name -> note.v1.Note
-	0, // 1: note.v1.GetNoteResponse.note:type_name -> note.v1.Note
-	0, // 2: note.v1.ListNotesResponse.notes:type_name -> note.v1.Note
-	1, // 3: note.v1.NoteService.CreateNote:input_type -> note.v1.CreateNoteRequest
-	3, // 4: note.v1.NoteService.GetNote:input_type -> note.v1.GetNoteRequest
-	5, // 5: note.v1.NoteService.ListNotes:input_type -> note.v1.ListNotesRequest
-	2, // 6: note.v1.NoteService.CreateNote:output_type -> note.v1.CreateNoteResponse
-	4, // 7: note.v1.NoteService.GetNote:output_type -> note.v1.GetNoteResponse
-	6, // 8: note.v1.NoteService.ListNotes:output_type -> note.v1.ListNotesResponse
-	6, // [6:9] is the sub-list for method output_type
-	3, // [3:6] is the sub-list for method input_type
-	3, // [3:3] is the sub-list for extension type_name
-	3, // [3:3] is the sub-list for extension extendee
-	0, // [0:3] is the sub-list for field type_name
+	4,  // 0: note.v1.Note.attachments:type_name -> note.v1.Attachment
+	3,  // 1: note.v1.CreateNoteResponse.note:type_name -> note.v1.Note
+	3,  // 2: note.v1.GetNoteResponse.note:type_name -> note.v1.Note
+	64, // 3: note.v1.UpdateNoteRequest.update_mask:type_name -> google.protobuf.FieldMask
+	3,  // 4: note.v1.UpdateNoteResponse.note:type_name -> note.v1.Note
+	3,  // 5: note.v1.ListTrashResponse.notes:type_name -> note.v1.Note
+	3,  // 6: note.v1.RestoreNoteResponse.note:type_name -> note.v1.Note
+	0,  // 7: note.v1.ListNotesRequest.tag_match:type_name -> note.v1.TagMatch
+	3,  // 8: note.v1.ListNotesResponse.notes:type_name -> note.v1.Note
+	3,  // 9: note.v1.AddTagsResponse.note:type_name -> note.v1.Note
+	3,  // 10: note.v1.RemoveTagsResponse.note:type_name -> note.v1.Note
+	26, // 11: note.v1.ListTagsResponse.tags:type_name -> note.v1.TagCount
+	28, // 12: note.v1.UploadAttachmentRequest.metadata:type_name -> note.v1.AttachmentUpload
+	3,  // 13: note.v1.UploadAttachmentResponse.note:type_name -> note.v1.Note
+	4,  // 14: note.v1.UploadAttachmentResponse.attachment:type_name -> note.v1.Attachment
+	4,  // 15: note.v1.DownloadAttachmentResponse.attachment:type_name -> note.v1.Attachment
+	3,  // 16: note.v1.ListNoteRevisionsResponse.revisions:type_name -> note.v1.Note
+	3,  // 17: note.v1.GetNoteRevisionResponse.revision:type_name -> note.v1.Note
+	3,  // 18: note.v1.RevertNoteResponse.note:type_name -> note.v1.Note
+	41, // 19: note.v1.BulkCreateNotesResponse.failures:type_name -> note.v1.BulkCreateFailure
+	3,  // 20: note.v1.SearchHit.note:type_name -> note.v1.Note
+	44, // 21: note.v1.SearchNotesResponse.hits:type_name -> note.v1.SearchHit
+	47, // 22: note.v1.RenderNoteResponse.toc:type_name -> note.v1.NoteHeading
+	48, // 23: note.v1.RenderNoteResponse.links:type_name -> note.v1.NoteLink
+	65, // 24: note.v1.ArchivedNoteVersion.created_at:type_name -> google.protobuf.Timestamp
+	65, // 25: note.v1.ArchivedNoteVersion.updated_at:type_name -> google.protobuf.Timestamp
+	65, // 26: note.v1.ArchivedNoteVersion.deleted_at:type_name -> google.protobuf.Timestamp
+	51, // 27: note.v1.ArchivedNoteVersion.attachments:type_name -> note.v1.ArchivedAttachment
+	65, // 28: note.v1.ArchivedAttachment.created_at:type_name -> google.protobuf.Timestamp
+	50, // 29: note.v1.ExportedNote.note:type_name -> note.v1.ArchivedNoteVersion
+	50, // 30: note.v1.ExportedNote.revisions:type_name -> note.v1.ArchivedNoteVersion
+	54, // 31: note.v1.ArchiveRecord.header:type_name -> note.v1.ArchiveHeader
+	52, // 32: note.v1.ArchiveRecord.note:type_name -> note.v1.ExportedNote
+	53, // 33: note.v1.ArchiveRecord.manifest:type_name -> note.v1.ArchiveManifest
+	52, // 34: note.v1.ExportNotesResponse.note:type_name -> note.v1.ExportedNote
+	53, // 35: note.v1.ExportNotesResponse.manifest:type_name -> note.v1.ArchiveManifest
+	1,  // 36: note.v1.ImportOptions.mode:type_name -> note.v1.ImportMode
+	58, // 37: note.v1.ImportNotesRequest.options:type_name -> note.v1.ImportOptions
+	52, // 38: note.v1.ImportNotesRequest.note:type_name -> note.v1.ExportedNote
+	60, // 39: note.v1.ImportNotesResponse.issues:type_name -> note.v1.ImportIssue
+	2,  // 40: note.v1.NoteEvent.type:type_name -> note.v1.NoteEventType
+	3,  // 41: note.v1.NoteEvent.note:type_name -> note.v1.Note
+	5,  // 42: note.v1.NoteService.CreateNote:input_type -> note.v1.CreateNoteRequest
+	7,  // 43: note.v1.NoteService.GetNote:input_type -> note.v1.GetNoteRequest
+	19, // 44: note.v1.NoteService.ListNotes:input_type -> note.v1.ListNotesRequest
+	9,  // 45: note.v1.NoteService.UpdateNote:input_type -> note.v1.UpdateNoteRequest
+	11, // 46: note.v1.NoteService.DeleteNote:input_type -> note.v1.DeleteNoteRequest
+	13, // 47: note.v1.NoteService.ListTrash:input_type -> note.v1.ListTrashRequest
+	15, // 48: note.v1.NoteService.RestoreNote:input_type -> note.v1.RestoreNoteRequest
+	17, // 49: note.v1.NoteService.PurgeNote:input_type -> note.v1.PurgeNoteRequest
+	21, // 50: note.v1.NoteService.AddTags:input_type -> note.v1.AddTagsRequest
+	23, // 51: note.v1.NoteService.RemoveTags:input_type -> note.v1.RemoveTagsRequest
+	25, // 52: note.v1.NoteService.ListTags:input_type -> note.v1.ListTagsRequest
+	33, // 53: note.v1.NoteService.ListNoteRevisions:input_type -> note.v1.ListNoteRevisionsRequest
+	35, // 54: note.v1.NoteService.GetNoteRevision:input_type -> note.v1.GetNoteRevisionRequest
+	37, // 55: note.v1.NoteService.DiffNoteRevisions:input_type -> note.v1.DiffNoteRevisionsRequest
+	39, // 56: note.v1.NoteService.RevertNote:input_type -> note.v1.RevertNoteRequest
+	62, // 57: note.v1.NoteService.WatchNotes:input_type -> note.v1.WatchNotesRequest
+	5,  // 58: note.v1.NoteService.BulkCreateNotes:input_type -> note.v1.CreateNoteRequest
+	43, // 59: note.v1.NoteService.SearchNotes:input_type -> note.v1.SearchNotesRequest
+	46, // 60: note.v1.NoteService.RenderNote:input_type -> note.v1.RenderNoteRequest
+	56, // 61: note.v1.NoteService.ExportNotes:input_type -> note.v1.ExportNotesRequest
+	59, // 62: note.v1.NoteService.ImportNotes:input_type -> note.v1.ImportNotesRequest
+	29, // 63: note.v1.NoteService.UploadAttachment:input_type -> note.v1.UploadAttachmentRequest
+	31, // 64: note.v1.NoteService.DownloadAttachment:input_type -> note.v1.DownloadAttachmentRequest
+	6,  // 65: note.v1.NoteService.CreateNote:output_type -> note.v1.CreateNoteResponse
+	8,  // 66: note.v1.NoteService.GetNote:output_type -> note.v1.GetNoteResponse
+	20, // 67: note.v1.NoteService.ListNotes:output_type -> note.v1.ListNotesResponse
+	10, // 68: note.v1.NoteService.UpdateNote:output_type -> note.v1.UpdateNoteResponse
+	12, // 69: note.v1.NoteService.DeleteNote:output_type -> note.v1.DeleteNoteResponse
+	14, // 70: note.v1.NoteService.ListTrash:output_type -> note.v1.ListTrashResponse
+	16, // 71: note.v1.NoteService.RestoreNote:output_type -> note.v1.RestoreNoteResponse
+	18, // 72: note.v1.NoteService.PurgeNote:output_type -> note.v1.PurgeNoteResponse
+	22, // 73: note.v1.NoteService.AddTags:output_type -> note.v1.AddTagsResponse
+	24, // 74: note.v1.NoteService.RemoveTags:output_type -> note.v1.RemoveTagsResponse
+	27, // 75: note.v1.NoteService.ListTags:output_type -> note.v1.ListTagsResponse
+	34, // 76: note.v1.NoteService.ListNoteRevisions:output_type -> note.v1.ListNoteRevisionsResponse
+	36, // 77: note.v1.NoteService.GetNoteRevision:output_type -> note.v1.GetNoteRevisionResponse
+	38, // 78: note.v1.NoteService.DiffNoteRevisions:output_type -> note.v1.DiffNoteRevisionsResponse
+	40, // 79: note.v1.NoteService.RevertNote:output_type -> note.v1.RevertNoteResponse
+	63, // 80: note.v1.NoteService.WatchNotes:output_type -> note.v1.NoteEvent
+	42, // 81: note.v1.NoteService.BulkCreateNotes:output_type -> note.v1.BulkCreateNotesResponse
+	45, // 82: note.v1.NoteService.SearchNotes:output_type -> note.v1.SearchNotesResponse
+	49, // 83: note.v1.NoteService.RenderNote:output_type -> note.v1.RenderNoteResponse
+	57, // 84: note.v1.NoteService.ExportNotes:output_type -> note.v1.ExportNotesResponse
+	61, // 85: note.v1.NoteService.ImportNotes:output_type -> note.v1.ImportNotesResponse
+	30, // 86: note.v1.NoteService.UploadAttachment:output_type -> note.v1.UploadAttachmentResponse
+	32, // 87: note.v1.NoteService.DownloadAttachment:output_type -> note.v1.DownloadAttachmentResponse
+	65, // [65:88] is the sub-list for method output_type
+	42, // [42:65] is the sub-list for method input_type
+	42, // [42:42] is the sub-list for extension type_name
+	42, // [42:42] is the sub-list for extension extendee
+	0,  // [0:42] is the sub-list for field type_name
 }
 
 func init() { file_note_proto_init() }
@@ -427,18 +4434,40 @@ func file_note_proto_init() {
 	if File_note_proto != nil {
 		return
 	}
+	file_note_proto_msgTypes[26].OneofWrappers = []any{
+		(*UploadAttachmentRequest_Metadata)(nil),
+		(*UploadAttachmentRequest_Chunk)(nil),
+	}
+	file_note_proto_msgTypes[29].OneofWrappers = []any{
+		(*DownloadAttachmentResponse_Attachment)(nil),
+		(*DownloadAttachmentResponse_Chunk)(nil),
+	}
+	file_note_proto_msgTypes[52].OneofWrappers = []any{
+		(*ArchiveRecord_Header)(nil),
+		(*ArchiveRecord_Note)(nil),
+		(*ArchiveRecord_Manifest)(nil),
+	}
+	file_note_proto_msgTypes[54].OneofWrappers = []any{
+		(*ExportNotesResponse_Note)(nil),
+		(*ExportNotesResponse_Manifest)(nil),
+	}
+	file_note_proto_msgTypes[56].OneofWrappers = []any{
+		(*ImportNotesRequest_Options)(nil),
+		(*ImportNotesRequest_Note)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_note_proto_rawDesc), len(file_note_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   7,
+			NumEnums:      3,
+			NumMessages:   61,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_note_proto_goTypes,
 		DependencyIndexes: file_note_proto_depIdxs,
+		EnumInfos:         file_note_proto_enumTypes,
 		MessageInfos:      file_note_proto_msgTypes,
 	}.Build()
 	File_note_proto = out.File
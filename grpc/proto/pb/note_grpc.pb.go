@@ -1,9 +1,17 @@
+// Указываем версию синтаксиса Protobuf.
+// proto3 — современный вариант с дефолтными значениями, упрощённой моделью optional и т.д.
+
 // Code generated by protoc-gen-go-grpc. DO NOT EDIT.
 // versions:
 // - protoc-gen-go-grpc v1.5.1
 // - protoc             v5.29.3
 // source: note.proto
 
+// Имя proto-пакета (НЕ Go-пакета).
+// Оно будет использоваться в полном имени RPC и типов, например:
+//   /note.v1.NoteService/CreateNote
+// Обычно пишут как <имя_сервиса>.<версия>.
+
 package pb
 
 import (
@@ -19,18 +27,91 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	NoteService_CreateNote_FullMethodName = "/note.v1.NoteService/CreateNote"
-	NoteService_GetNote_FullMethodName    = "/note.v1.NoteService/GetNote"
-	NoteService_ListNotes_FullMethodName  = "/note.v1.NoteService/ListNotes"
+	NoteService_CreateNote_FullMethodName         = "/note.v1.NoteService/CreateNote"
+	NoteService_GetNote_FullMethodName            = "/note.v1.NoteService/GetNote"
+	NoteService_ListNotes_FullMethodName          = "/note.v1.NoteService/ListNotes"
+	NoteService_UpdateNote_FullMethodName         = "/note.v1.NoteService/UpdateNote"
+	NoteService_DeleteNote_FullMethodName         = "/note.v1.NoteService/DeleteNote"
+	NoteService_ListTrash_FullMethodName          = "/note.v1.NoteService/ListTrash"
+	NoteService_RestoreNote_FullMethodName        = "/note.v1.NoteService/RestoreNote"
+	NoteService_PurgeNote_FullMethodName          = "/note.v1.NoteService/PurgeNote"
+	NoteService_AddTags_FullMethodName            = "/note.v1.NoteService/AddTags"
+	NoteService_RemoveTags_FullMethodName         = "/note.v1.NoteService/RemoveTags"
+	NoteService_ListTags_FullMethodName           = "/note.v1.NoteService/ListTags"
+	NoteService_ListNoteRevisions_FullMethodName  = "/note.v1.NoteService/ListNoteRevisions"
+	NoteService_GetNoteRevision_FullMethodName    = "/note.v1.NoteService/GetNoteRevision"
+	NoteService_DiffNoteRevisions_FullMethodName  = "/note.v1.NoteService/DiffNoteRevisions"
+	NoteService_RevertNote_FullMethodName         = "/note.v1.NoteService/RevertNote"
+	NoteService_WatchNotes_FullMethodName         = "/note.v1.NoteService/WatchNotes"
+	NoteService_BulkCreateNotes_FullMethodName    = "/note.v1.NoteService/BulkCreateNotes"
+	NoteService_SearchNotes_FullMethodName        = "/note.v1.NoteService/SearchNotes"
+	NoteService_RenderNote_FullMethodName         = "/note.v1.NoteService/RenderNote"
+	NoteService_ExportNotes_FullMethodName        = "/note.v1.NoteService/ExportNotes"
+	NoteService_ImportNotes_FullMethodName        = "/note.v1.NoteService/ImportNotes"
+	NoteService_UploadAttachment_FullMethodName   = "/note.v1.NoteService/UploadAttachment"
+	NoteService_DownloadAttachment_FullMethodName = "/note.v1.NoteService/DownloadAttachment"
 )
 
 // NoteServiceClient is the client API for NoteService service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Описание gRPC-сервиса NoteService.
+// protoc сгенерирует для него:
+//   - На стороне сервера: интерфейс pb.NoteServiceServer, который мы должны реализовать.
+//   - На стороне клиента: интерфейс pb.NoteServiceClient и фабрику pb.NewNoteServiceClient.
+//
+// Большинство rpc принимают одно сообщение (Request) и возвращают одно сообщение (Response).
+// Это unary RPC — 1 запрос, 1 ответ.
+// WatchNotes — server-streaming RPC: 1 запрос, поток ответов (stream NoteEvent).
+// BulkCreateNotes — client-streaming RPC: поток запросов, 1 ответ.
 type NoteServiceClient interface {
+	// Создать заметку.
 	CreateNote(ctx context.Context, in *CreateNoteRequest, opts ...grpc.CallOption) (*CreateNoteResponse, error)
+	// Получить заметку по ID.
 	GetNote(ctx context.Context, in *GetNoteRequest, opts ...grpc.CallOption) (*GetNoteResponse, error)
+	// Получить страницу списка заметок.
 	ListNotes(ctx context.Context, in *ListNotesRequest, opts ...grpc.CallOption) (*ListNotesResponse, error)
+	// Изменить заголовок и/или текст заметки (по маске полей).
+	UpdateNote(ctx context.Context, in *UpdateNoteRequest, opts ...grpc.CallOption) (*UpdateNoteResponse, error)
+	// Удалить заметку по ID (в корзину).
+	DeleteNote(ctx context.Context, in *DeleteNoteRequest, opts ...grpc.CallOption) (*DeleteNoteResponse, error)
+	// Получить страницу корзины.
+	ListTrash(ctx context.Context, in *ListTrashRequest, opts ...grpc.CallOption) (*ListTrashResponse, error)
+	// Вернуть заметку из корзины.
+	RestoreNote(ctx context.Context, in *RestoreNoteRequest, opts ...grpc.CallOption) (*RestoreNoteResponse, error)
+	// Удалить заметку из корзины окончательно.
+	PurgeNote(ctx context.Context, in *PurgeNoteRequest, opts ...grpc.CallOption) (*PurgeNoteResponse, error)
+	// Добавить заметке теги.
+	AddTags(ctx context.Context, in *AddTagsRequest, opts ...grpc.CallOption) (*AddTagsResponse, error)
+	// Снять с заметки теги.
+	RemoveTags(ctx context.Context, in *RemoveTagsRequest, opts ...grpc.CallOption) (*RemoveTagsResponse, error)
+	// Все теги вызывающего с числом заметок.
+	ListTags(ctx context.Context, in *ListTagsRequest, opts ...grpc.CallOption) (*ListTagsResponse, error)
+	// История версий заметки.
+	ListNoteRevisions(ctx context.Context, in *ListNoteRevisionsRequest, opts ...grpc.CallOption) (*ListNoteRevisionsResponse, error)
+	// Одна версия заметки из истории.
+	GetNoteRevision(ctx context.Context, in *GetNoteRevisionRequest, opts ...grpc.CallOption) (*GetNoteRevisionResponse, error)
+	// Построчный diff содержимого между двумя версиями.
+	DiffNoteRevisions(ctx context.Context, in *DiffNoteRevisionsRequest, opts ...grpc.CallOption) (*DiffNoteRevisionsResponse, error)
+	// Откатить заметку к старой версии (создаёт новую версию).
+	RevertNote(ctx context.Context, in *RevertNoteRequest, opts ...grpc.CallOption) (*RevertNoteResponse, error)
+	// Подписаться на поток изменений заметок (created/updated/deleted/restored/purged).
+	WatchNotes(ctx context.Context, in *WatchNotesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[NoteEvent], error)
+	// Массовый импорт: клиент шлёт поток CreateNoteRequest, сервер отвечает сводкой.
+	BulkCreateNotes(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[CreateNoteRequest, BulkCreateNotesResponse], error)
+	// Полнотекстовый поиск по заголовку и тексту заметок.
+	SearchNotes(ctx context.Context, in *SearchNotesRequest, opts ...grpc.CallOption) (*SearchNotesResponse, error)
+	// Отрисовать текст заметки (Markdown) в безопасный HTML с оглавлением.
+	RenderNote(ctx context.Context, in *RenderNoteRequest, opts ...grpc.CallOption) (*RenderNoteResponse, error)
+	// Выгрузить заметки вызывающего с историей: server-streaming, последним — manifest.
+	ExportNotes(ctx context.Context, in *ExportNotesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExportNotesResponse], error)
+	// Загрузить заметки из архива: client-streaming, режим — в первом сообщении.
+	ImportNotes(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ImportNotesRequest, ImportNotesResponse], error)
+	// Загрузить вложение: client-streaming, первое сообщение — метаданные, дальше куски данных.
+	UploadAttachment(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[UploadAttachmentRequest, UploadAttachmentResponse], error)
+	// Скачать вложение: server-streaming, первое сообщение — метаданные, дальше куски данных.
+	DownloadAttachment(ctx context.Context, in *DownloadAttachmentRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DownloadAttachmentResponse], error)
 }
 
 type noteServiceClient struct {
@@ -71,13 +152,302 @@ func (c *noteServiceClient) ListNotes(ctx context.Context, in *ListNotesRequest,
 	return out, nil
 }
 
+func (c *noteServiceClient) UpdateNote(ctx context.Context, in *UpdateNoteRequest, opts ...grpc.CallOption) (*UpdateNoteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateNoteResponse)
+	err := c.cc.Invoke(ctx, NoteService_UpdateNote_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *noteServiceClient) DeleteNote(ctx context.Context, in *DeleteNoteRequest, opts ...grpc.CallOption) (*DeleteNoteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteNoteResponse)
+	err := c.cc.Invoke(ctx, NoteService_DeleteNote_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *noteServiceClient) ListTrash(ctx context.Context, in *ListTrashRequest, opts ...grpc.CallOption) (*ListTrashResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListTrashResponse)
+	err := c.cc.Invoke(ctx, NoteService_ListTrash_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *noteServiceClient) RestoreNote(ctx context.Context, in *RestoreNoteRequest, opts ...grpc.CallOption) (*RestoreNoteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RestoreNoteResponse)
+	err := c.cc.Invoke(ctx, NoteService_RestoreNote_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *noteServiceClient) PurgeNote(ctx context.Context, in *PurgeNoteRequest, opts ...grpc.CallOption) (*PurgeNoteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PurgeNoteResponse)
+	err := c.cc.Invoke(ctx, NoteService_PurgeNote_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *noteServiceClient) AddTags(ctx context.Context, in *AddTagsRequest, opts ...grpc.CallOption) (*AddTagsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AddTagsResponse)
+	err := c.cc.Invoke(ctx, NoteService_AddTags_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *noteServiceClient) RemoveTags(ctx context.Context, in *RemoveTagsRequest, opts ...grpc.CallOption) (*RemoveTagsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RemoveTagsResponse)
+	err := c.cc.Invoke(ctx, NoteService_RemoveTags_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *noteServiceClient) ListTags(ctx context.Context, in *ListTagsRequest, opts ...grpc.CallOption) (*ListTagsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListTagsResponse)
+	err := c.cc.Invoke(ctx, NoteService_ListTags_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *noteServiceClient) ListNoteRevisions(ctx context.Context, in *ListNoteRevisionsRequest, opts ...grpc.CallOption) (*ListNoteRevisionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListNoteRevisionsResponse)
+	err := c.cc.Invoke(ctx, NoteService_ListNoteRevisions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *noteServiceClient) GetNoteRevision(ctx context.Context, in *GetNoteRevisionRequest, opts ...grpc.CallOption) (*GetNoteRevisionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetNoteRevisionResponse)
+	err := c.cc.Invoke(ctx, NoteService_GetNoteRevision_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *noteServiceClient) DiffNoteRevisions(ctx context.Context, in *DiffNoteRevisionsRequest, opts ...grpc.CallOption) (*DiffNoteRevisionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DiffNoteRevisionsResponse)
+	err := c.cc.Invoke(ctx, NoteService_DiffNoteRevisions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *noteServiceClient) RevertNote(ctx context.Context, in *RevertNoteRequest, opts ...grpc.CallOption) (*RevertNoteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RevertNoteResponse)
+	err := c.cc.Invoke(ctx, NoteService_RevertNote_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *noteServiceClient) WatchNotes(ctx context.Context, in *WatchNotesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[NoteEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &NoteService_ServiceDesc.Streams[0], NoteService_WatchNotes_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchNotesRequest, NoteEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type NoteService_WatchNotesClient = grpc.ServerStreamingClient[NoteEvent]
+
+func (c *noteServiceClient) BulkCreateNotes(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[CreateNoteRequest, BulkCreateNotesResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &NoteService_ServiceDesc.Streams[1], NoteService_BulkCreateNotes_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[CreateNoteRequest, BulkCreateNotesResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type NoteService_BulkCreateNotesClient = grpc.ClientStreamingClient[CreateNoteRequest, BulkCreateNotesResponse]
+
+func (c *noteServiceClient) SearchNotes(ctx context.Context, in *SearchNotesRequest, opts ...grpc.CallOption) (*SearchNotesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchNotesResponse)
+	err := c.cc.Invoke(ctx, NoteService_SearchNotes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *noteServiceClient) RenderNote(ctx context.Context, in *RenderNoteRequest, opts ...grpc.CallOption) (*RenderNoteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RenderNoteResponse)
+	err := c.cc.Invoke(ctx, NoteService_RenderNote_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *noteServiceClient) ExportNotes(ctx context.Context, in *ExportNotesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExportNotesResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &NoteService_ServiceDesc.Streams[2], NoteService_ExportNotes_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ExportNotesRequest, ExportNotesResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type NoteService_ExportNotesClient = grpc.ServerStreamingClient[ExportNotesResponse]
+
+func (c *noteServiceClient) ImportNotes(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ImportNotesRequest, ImportNotesResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &NoteService_ServiceDesc.Streams[3], NoteService_ImportNotes_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ImportNotesRequest, ImportNotesResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type NoteService_ImportNotesClient = grpc.ClientStreamingClient[ImportNotesRequest, ImportNotesResponse]
+
+func (c *noteServiceClient) UploadAttachment(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[UploadAttachmentRequest, UploadAttachmentResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &NoteService_ServiceDesc.Streams[4], NoteService_UploadAttachment_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[UploadAttachmentRequest, UploadAttachmentResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type NoteService_UploadAttachmentClient = grpc.ClientStreamingClient[UploadAttachmentRequest, UploadAttachmentResponse]
+
+func (c *noteServiceClient) DownloadAttachment(ctx context.Context, in *DownloadAttachmentRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DownloadAttachmentResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &NoteService_ServiceDesc.Streams[5], NoteService_DownloadAttachment_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[DownloadAttachmentRequest, DownloadAttachmentResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type NoteService_DownloadAttachmentClient = grpc.ServerStreamingClient[DownloadAttachmentResponse]
+
 // NoteServiceServer is the server API for NoteService service.
 // All implementations must embed UnimplementedNoteServiceServer
 // for forward compatibility.
+//
+// Описание gRPC-сервиса NoteService.
+// protoc сгенерирует для него:
+//   - На стороне сервера: интерфейс pb.NoteServiceServer, который мы должны реализовать.
+//   - На стороне клиента: интерфейс pb.NoteServiceClient и фабрику pb.NewNoteServiceClient.
+//
+// Большинство rpc принимают одно сообщение (Request) и возвращают одно сообщение (Response).
+// Это unary RPC — 1 запрос, 1 ответ.
+// WatchNotes — server-streaming RPC: 1 запрос, поток ответов (stream NoteEvent).
+// BulkCreateNotes — client-streaming RPC: поток запросов, 1 ответ.
 type NoteServiceServer interface {
+	// Создать заметку.
 	CreateNote(context.Context, *CreateNoteRequest) (*CreateNoteResponse, error)
+	// Получить заметку по ID.
 	GetNote(context.Context, *GetNoteRequest) (*GetNoteResponse, error)
+	// Получить страницу списка заметок.
 	ListNotes(context.Context, *ListNotesRequest) (*ListNotesResponse, error)
+	// Изменить заголовок и/или текст заметки (по маске полей).
+	UpdateNote(context.Context, *UpdateNoteRequest) (*UpdateNoteResponse, error)
+	// Удалить заметку по ID (в корзину).
+	DeleteNote(context.Context, *DeleteNoteRequest) (*DeleteNoteResponse, error)
+	// Получить страницу корзины.
+	ListTrash(context.Context, *ListTrashRequest) (*ListTrashResponse, error)
+	// Вернуть заметку из корзины.
+	RestoreNote(context.Context, *RestoreNoteRequest) (*RestoreNoteResponse, error)
+	// Удалить заметку из корзины окончательно.
+	PurgeNote(context.Context, *PurgeNoteRequest) (*PurgeNoteResponse, error)
+	// Добавить заметке теги.
+	AddTags(context.Context, *AddTagsRequest) (*AddTagsResponse, error)
+	// Снять с заметки теги.
+	RemoveTags(context.Context, *RemoveTagsRequest) (*RemoveTagsResponse, error)
+	// Все теги вызывающего с числом заметок.
+	ListTags(context.Context, *ListTagsRequest) (*ListTagsResponse, error)
+	// История версий заметки.
+	ListNoteRevisions(context.Context, *ListNoteRevisionsRequest) (*ListNoteRevisionsResponse, error)
+	// Одна версия заметки из истории.
+	GetNoteRevision(context.Context, *GetNoteRevisionRequest) (*GetNoteRevisionResponse, error)
+	// Построчный diff содержимого между двумя версиями.
+	DiffNoteRevisions(context.Context, *DiffNoteRevisionsRequest) (*DiffNoteRevisionsResponse, error)
+	// Откатить заметку к старой версии (создаёт новую версию).
+	RevertNote(context.Context, *RevertNoteRequest) (*RevertNoteResponse, error)
+	// Подписаться на поток изменений заметок (created/updated/deleted/restored/purged).
+	WatchNotes(*WatchNotesRequest, grpc.ServerStreamingServer[NoteEvent]) error
+	// Массовый импорт: клиент шлёт поток CreateNoteRequest, сервер отвечает сводкой.
+	BulkCreateNotes(grpc.ClientStreamingServer[CreateNoteRequest, BulkCreateNotesResponse]) error
+	// Полнотекстовый поиск по заголовку и тексту заметок.
+	SearchNotes(context.Context, *SearchNotesRequest) (*SearchNotesResponse, error)
+	// Отрисовать текст заметки (Markdown) в безопасный HTML с оглавлением.
+	RenderNote(context.Context, *RenderNoteRequest) (*RenderNoteResponse, error)
+	// Выгрузить заметки вызывающего с историей: server-streaming, последним — manifest.
+	ExportNotes(*ExportNotesRequest, grpc.ServerStreamingServer[ExportNotesResponse]) error
+	// Загрузить заметки из архива: client-streaming, режим — в первом сообщении.
+	ImportNotes(grpc.ClientStreamingServer[ImportNotesRequest, ImportNotesResponse]) error
+	// Загрузить вложение: client-streaming, первое сообщение — метаданные, дальше куски данных.
+	UploadAttachment(grpc.ClientStreamingServer[UploadAttachmentRequest, UploadAttachmentResponse]) error
+	// Скачать вложение: server-streaming, первое сообщение — метаданные, дальше куски данных.
+	DownloadAttachment(*DownloadAttachmentRequest, grpc.ServerStreamingServer[DownloadAttachmentResponse]) error
 	mustEmbedUnimplementedNoteServiceServer()
 }
 
@@ -97,6 +467,66 @@ func (UnimplementedNoteServiceServer) GetNote(context.Context, *GetNoteRequest)
 func (UnimplementedNoteServiceServer) ListNotes(context.Context, *ListNotesRequest) (*ListNotesResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListNotes not implemented")
 }
+func (UnimplementedNoteServiceServer) UpdateNote(context.Context, *UpdateNoteRequest) (*UpdateNoteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateNote not implemented")
+}
+func (UnimplementedNoteServiceServer) DeleteNote(context.Context, *DeleteNoteRequest) (*DeleteNoteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteNote not implemented")
+}
+func (UnimplementedNoteServiceServer) ListTrash(context.Context, *ListTrashRequest) (*ListTrashResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTrash not implemented")
+}
+func (UnimplementedNoteServiceServer) RestoreNote(context.Context, *RestoreNoteRequest) (*RestoreNoteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreNote not implemented")
+}
+func (UnimplementedNoteServiceServer) PurgeNote(context.Context, *PurgeNoteRequest) (*PurgeNoteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PurgeNote not implemented")
+}
+func (UnimplementedNoteServiceServer) AddTags(context.Context, *AddTagsRequest) (*AddTagsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddTags not implemented")
+}
+func (UnimplementedNoteServiceServer) RemoveTags(context.Context, *RemoveTagsRequest) (*RemoveTagsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveTags not implemented")
+}
+func (UnimplementedNoteServiceServer) ListTags(context.Context, *ListTagsRequest) (*ListTagsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTags not implemented")
+}
+func (UnimplementedNoteServiceServer) ListNoteRevisions(context.Context, *ListNoteRevisionsRequest) (*ListNoteRevisionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListNoteRevisions not implemented")
+}
+func (UnimplementedNoteServiceServer) GetNoteRevision(context.Context, *GetNoteRevisionRequest) (*GetNoteRevisionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetNoteRevision not implemented")
+}
+func (UnimplementedNoteServiceServer) DiffNoteRevisions(context.Context, *DiffNoteRevisionsRequest) (*DiffNoteRevisionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DiffNoteRevisions not implemented")
+}
+func (UnimplementedNoteServiceServer) RevertNote(context.Context, *RevertNoteRequest) (*RevertNoteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevertNote not implemented")
+}
+func (UnimplementedNoteServiceServer) WatchNotes(*WatchNotesRequest, grpc.ServerStreamingServer[NoteEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchNotes not implemented")
+}
+func (UnimplementedNoteServiceServer) BulkCreateNotes(grpc.ClientStreamingServer[CreateNoteRequest, BulkCreateNotesResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method BulkCreateNotes not implemented")
+}
+func (UnimplementedNoteServiceServer) SearchNotes(context.Context, *SearchNotesRequest) (*SearchNotesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchNotes not implemented")
+}
+func (UnimplementedNoteServiceServer) RenderNote(context.Context, *RenderNoteRequest) (*RenderNoteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RenderNote not implemented")
+}
+func (UnimplementedNoteServiceServer) ExportNotes(*ExportNotesRequest, grpc.ServerStreamingServer[ExportNotesResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method ExportNotes not implemented")
+}
+func (UnimplementedNoteServiceServer) ImportNotes(grpc.ClientStreamingServer[ImportNotesRequest, ImportNotesResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method ImportNotes not implemented")
+}
+func (UnimplementedNoteServiceServer) UploadAttachment(grpc.ClientStreamingServer[UploadAttachmentRequest, UploadAttachmentResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method UploadAttachment not implemented")
+}
+func (UnimplementedNoteServiceServer) DownloadAttachment(*DownloadAttachmentRequest, grpc.ServerStreamingServer[DownloadAttachmentResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method DownloadAttachment not implemented")
+}
 func (UnimplementedNoteServiceServer) mustEmbedUnimplementedNoteServiceServer() {}
 func (UnimplementedNoteServiceServer) testEmbeddedByValue()                     {}
 
@@ -172,6 +602,312 @@ func _NoteService_ListNotes_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _NoteService_UpdateNote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateNoteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NoteServiceServer).UpdateNote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NoteService_UpdateNote_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NoteServiceServer).UpdateNote(ctx, req.(*UpdateNoteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NoteService_DeleteNote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteNoteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NoteServiceServer).DeleteNote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NoteService_DeleteNote_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NoteServiceServer).DeleteNote(ctx, req.(*DeleteNoteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NoteService_ListTrash_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTrashRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NoteServiceServer).ListTrash(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NoteService_ListTrash_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NoteServiceServer).ListTrash(ctx, req.(*ListTrashRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NoteService_RestoreNote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreNoteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NoteServiceServer).RestoreNote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NoteService_RestoreNote_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NoteServiceServer).RestoreNote(ctx, req.(*RestoreNoteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NoteService_PurgeNote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PurgeNoteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NoteServiceServer).PurgeNote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NoteService_PurgeNote_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NoteServiceServer).PurgeNote(ctx, req.(*PurgeNoteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NoteService_AddTags_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddTagsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NoteServiceServer).AddTags(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NoteService_AddTags_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NoteServiceServer).AddTags(ctx, req.(*AddTagsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NoteService_RemoveTags_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveTagsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NoteServiceServer).RemoveTags(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NoteService_RemoveTags_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NoteServiceServer).RemoveTags(ctx, req.(*RemoveTagsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NoteService_ListTags_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTagsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NoteServiceServer).ListTags(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NoteService_ListTags_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NoteServiceServer).ListTags(ctx, req.(*ListTagsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NoteService_ListNoteRevisions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListNoteRevisionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NoteServiceServer).ListNoteRevisions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NoteService_ListNoteRevisions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NoteServiceServer).ListNoteRevisions(ctx, req.(*ListNoteRevisionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NoteService_GetNoteRevision_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetNoteRevisionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NoteServiceServer).GetNoteRevision(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NoteService_GetNoteRevision_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NoteServiceServer).GetNoteRevision(ctx, req.(*GetNoteRevisionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NoteService_DiffNoteRevisions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DiffNoteRevisionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NoteServiceServer).DiffNoteRevisions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NoteService_DiffNoteRevisions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NoteServiceServer).DiffNoteRevisions(ctx, req.(*DiffNoteRevisionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NoteService_RevertNote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevertNoteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NoteServiceServer).RevertNote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NoteService_RevertNote_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NoteServiceServer).RevertNote(ctx, req.(*RevertNoteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NoteService_WatchNotes_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchNotesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(NoteServiceServer).WatchNotes(m, &grpc.GenericServerStream[WatchNotesRequest, NoteEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type NoteService_WatchNotesServer = grpc.ServerStreamingServer[NoteEvent]
+
+func _NoteService_BulkCreateNotes_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(NoteServiceServer).BulkCreateNotes(&grpc.GenericServerStream[CreateNoteRequest, BulkCreateNotesResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type NoteService_BulkCreateNotesServer = grpc.ClientStreamingServer[CreateNoteRequest, BulkCreateNotesResponse]
+
+func _NoteService_SearchNotes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchNotesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NoteServiceServer).SearchNotes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NoteService_SearchNotes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NoteServiceServer).SearchNotes(ctx, req.(*SearchNotesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NoteService_RenderNote_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RenderNoteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NoteServiceServer).RenderNote(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NoteService_RenderNote_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NoteServiceServer).RenderNote(ctx, req.(*RenderNoteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NoteService_ExportNotes_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportNotesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(NoteServiceServer).ExportNotes(m, &grpc.GenericServerStream[ExportNotesRequest, ExportNotesResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type NoteService_ExportNotesServer = grpc.ServerStreamingServer[ExportNotesResponse]
+
+func _NoteService_ImportNotes_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(NoteServiceServer).ImportNotes(&grpc.GenericServerStream[ImportNotesRequest, ImportNotesResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type NoteService_ImportNotesServer = grpc.ClientStreamingServer[ImportNotesRequest, ImportNotesResponse]
+
+func _NoteService_UploadAttachment_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(NoteServiceServer).UploadAttachment(&grpc.GenericServerStream[UploadAttachmentRequest, UploadAttachmentResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type NoteService_UploadAttachmentServer = grpc.ClientStreamingServer[UploadAttachmentRequest, UploadAttachmentResponse]
+
+func _NoteService_DownloadAttachment_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DownloadAttachmentRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(NoteServiceServer).DownloadAttachment(m, &grpc.GenericServerStream[DownloadAttachmentRequest, DownloadAttachmentResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type NoteService_DownloadAttachmentServer = grpc.ServerStreamingServer[DownloadAttachmentResponse]
+
 // NoteService_ServiceDesc is the grpc.ServiceDesc for NoteService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -191,7 +927,94 @@ var NoteService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ListNotes",
 			Handler:    _NoteService_ListNotes_Handler,
 		},
+		{
+			MethodName: "UpdateNote",
+			Handler:    _NoteService_UpdateNote_Handler,
+		},
+		{
+			MethodName: "DeleteNote",
+			Handler:    _NoteService_DeleteNote_Handler,
+		},
+		{
+			MethodName: "ListTrash",
+			Handler:    _NoteService_ListTrash_Handler,
+		},
+		{
+			MethodName: "RestoreNote",
+			Handler:    _NoteService_RestoreNote_Handler,
+		},
+		{
+			MethodName: "PurgeNote",
+			Handler:    _NoteService_PurgeNote_Handler,
+		},
+		{
+			MethodName: "AddTags",
+			Handler:    _NoteService_AddTags_Handler,
+		},
+		{
+			MethodName: "RemoveTags",
+			Handler:    _NoteService_RemoveTags_Handler,
+		},
+		{
+			MethodName: "ListTags",
+			Handler:    _NoteService_ListTags_Handler,
+		},
+		{
+			MethodName: "ListNoteRevisions",
+			Handler:    _NoteService_ListNoteRevisions_Handler,
+		},
+		{
+			MethodName: "GetNoteRevision",
+			Handler:    _NoteService_GetNoteRevision_Handler,
+		},
+		{
+			MethodName: "DiffNoteRevisions",
+			Handler:    _NoteService_DiffNoteRevisions_Handler,
+		},
+		{
+			MethodName: "RevertNote",
+			Handler:    _NoteService_RevertNote_Handler,
+		},
+		{
+			MethodName: "SearchNotes",
+			Handler:    _NoteService_SearchNotes_Handler,
+		},
+		{
+			MethodName: "RenderNote",
+			Handler:    _NoteService_RenderNote_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchNotes",
+			Handler:       _NoteService_WatchNotes_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "BulkCreateNotes",
+			Handler:       _NoteService_BulkCreateNotes_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "ExportNotes",
+			Handler:       _NoteService_ExportNotes_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ImportNotes",
+			Handler:       _NoteService_ImportNotes_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "UploadAttachment",
+			Handler:       _NoteService_UploadAttachment_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "DownloadAttachment",
+			Handler:       _NoteService_DownloadAttachment_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "note.proto",
 }